@@ -3,8 +3,8 @@ from io import BytesIO
 from flask import Flask, request, jsonify
 from flask_cors import CORS
 from dotenv import load_dotenv
-from service.pdf_service import extract_text_from_pdf
-from service.ai_service import summarize_text
+from service.pdf_service import extract_text_from_pdf, extract_pages_from_pdf, extract_outline, InvalidPasswordError
+from service.ai_service import summarize_text, answer_question, synthesize_documents, structure_summary, extract_fields, extract_entities, classify_document, model_info
 
 load_dotenv()
 
@@ -15,22 +15,26 @@ CORS(app)
 def summarize():
     start = time.time()
 
-    if "file" not in request.files:
-        return jsonify({"error": "No file provided"}), 400
-
-    file = request.files["file"]
     language = request.form.get("language", "EN")
     style = request.form.get("style", "professional")
+    text = request.form.get("text")
+
+    if text is None:
+        if "file" not in request.files:
+            return jsonify({"error": "No file provided"}), 400
 
-    if file.filename == "":
-        return jsonify({"error": "No file selected"}), 400
+        file = request.files["file"]
 
-    if not file.filename.lower().endswith(".pdf"):
-        return jsonify({"error": "Only PDF files are allowed"}), 400
+        if file.filename == "":
+            return jsonify({"error": "No file selected"}), 400
+
+        if not file.filename.lower().endswith(".pdf"):
+            return jsonify({"error": "Only PDF files are allowed"}), 400
 
     try:
-        pdf_bytes = BytesIO(file.read())
-        text = extract_text_from_pdf(pdf_bytes)
+        if text is None:
+            pdf_bytes = BytesIO(file.read())
+            text = extract_text_from_pdf(pdf_bytes)
 
         if not text.strip():
             return jsonify({"error": "No text found in PDF"}), 400
@@ -41,7 +45,67 @@ def summarize():
 
         return jsonify({
             "summary": summary,
-            "processing_time_ms": elapsed_ms
+            "processing_time_ms": elapsed_ms,
+            **model_info()
+        }), 200
+
+    except Exception as e:
+        import traceback
+        traceback.print_exc()
+        return jsonify({"error": str(e)}), 500
+
+
+@app.route("/synthesize", methods=["POST"])
+def synthesize():
+    start = time.time()
+
+    payload = request.get_json(silent=True) or {}
+    documents = [d for d in payload.get("documents") or [] if (d.get("text") or "").strip()]
+
+    if len(documents) < 2:
+        return jsonify({"error": "At least two documents with text are required"}), 400
+
+    try:
+        summary = synthesize_documents(
+            documents,
+            payload.get("mode", "compare"),
+            payload.get("language", "EN"),
+            payload.get("style", "professional"),
+        )
+
+        elapsed_ms = int((time.time() - start) * 1000)
+
+        return jsonify({
+            "summary": summary,
+            "processing_time_ms": elapsed_ms,
+            **model_info()
+        }), 200
+
+    except Exception as e:
+        import traceback
+        traceback.print_exc()
+        return jsonify({"error": str(e)}), 500
+
+
+@app.route("/structure", methods=["POST"])
+def structure():
+    start = time.time()
+
+    payload = request.get_json(silent=True) or {}
+    summary = (payload.get("summary") or "").strip()
+
+    if not summary:
+        return jsonify({"error": "No summary provided"}), 400
+
+    try:
+        structured = structure_summary(summary, payload.get("language", "EN"))
+
+        elapsed_ms = int((time.time() - start) * 1000)
+
+        return jsonify({
+            "structured": structured,
+            "processing_time_ms": elapsed_ms,
+            **model_info()
         }), 200
 
     except Exception as e:
@@ -50,5 +114,173 @@ def summarize():
         return jsonify({"error": str(e)}), 500
 
 
+@app.route("/extract-fields", methods=["POST"])
+def extract_data():
+    start = time.time()
+
+    payload = request.get_json(silent=True) or {}
+    text = (payload.get("text") or "").strip()
+    schema = payload.get("schema")
+
+    if not text:
+        return jsonify({"error": "No text provided"}), 400
+    if not isinstance(schema, dict):
+        return jsonify({"error": "No schema provided"}), 400
+
+    try:
+        data = extract_fields(text, schema, payload.get("errors") or [])
+
+        elapsed_ms = int((time.time() - start) * 1000)
+
+        return jsonify({
+            "data": data,
+            "processing_time_ms": elapsed_ms,
+            **model_info()
+        }), 200
+
+    except Exception as e:
+        import traceback
+        traceback.print_exc()
+        return jsonify({"error": str(e)}), 500
+
+
+@app.route("/entities", methods=["POST"])
+def entities():
+    start = time.time()
+
+    payload = request.get_json(silent=True) or {}
+    text = (payload.get("text") or "").strip()
+
+    if not text:
+        return jsonify({"error": "No text provided"}), 400
+
+    try:
+        found = extract_entities(text)
+
+        elapsed_ms = int((time.time() - start) * 1000)
+
+        return jsonify({
+            "entities": found,
+            "processing_time_ms": elapsed_ms,
+            **model_info()
+        }), 200
+
+    except Exception as e:
+        import traceback
+        traceback.print_exc()
+        return jsonify({"error": str(e)}), 500
+
+
+@app.route("/classify", methods=["POST"])
+def classify():
+    start = time.time()
+
+    payload = request.get_json(silent=True) or {}
+    text = (payload.get("text") or "").strip()
+    categories = [c for c in payload.get("categories") or [] if c.get("category")]
+
+    if not text:
+        return jsonify({"error": "No text provided"}), 400
+    if not categories:
+        return jsonify({"error": "No categories provided"}), 400
+
+    try:
+        result = classify_document(text, categories)
+
+        elapsed_ms = int((time.time() - start) * 1000)
+
+        return jsonify({
+            "category": result.get("category"),
+            "confidence": result.get("confidence"),
+            "processing_time_ms": elapsed_ms,
+            **model_info()
+        }), 200
+
+    except Exception as e:
+        import traceback
+        traceback.print_exc()
+        return jsonify({"error": str(e)}), 500
+
+
+@app.route("/answer", methods=["POST"])
+def answer():
+    start = time.time()
+
+    payload = request.get_json(silent=True) or {}
+    question = (payload.get("question") or "").strip()
+    passages = payload.get("passages") or []
+
+    if not question:
+        return jsonify({"error": "No question provided"}), 400
+
+    try:
+        text = answer_question(
+            question,
+            passages,
+            payload.get("history") or [],
+            payload.get("language", "EN"),
+        )
+
+        elapsed_ms = int((time.time() - start) * 1000)
+
+        return jsonify({
+            "answer": text,
+            "processing_time_ms": elapsed_ms,
+            **model_info()
+        }), 200
+
+    except Exception as e:
+        import traceback
+        traceback.print_exc()
+        return jsonify({"error": str(e)}), 500
+
+
+@app.route("/info", methods=["GET"])
+def info():
+    return jsonify(model_info()), 200
+
+
+@app.route("/extract", methods=["POST"])
+def extract():
+    if "file" not in request.files:
+        return jsonify({"error": "No file provided"}), 400
+
+    file = request.files["file"]
+    password = request.form.get("password")
+
+    try:
+        pages = extract_pages_from_pdf(BytesIO(file.read()), password)
+        return jsonify({"pages": pages}), 200
+
+    except InvalidPasswordError as e:
+        return jsonify({"error": str(e)}), 401
+
+    except Exception as e:
+        import traceback
+        traceback.print_exc()
+        return jsonify({"error": str(e)}), 500
+
+
+@app.route("/outline", methods=["POST"])
+def outline():
+    if "file" not in request.files:
+        return jsonify({"error": "No file provided"}), 400
+
+    file = request.files["file"]
+    password = request.form.get("password")
+
+    try:
+        items = extract_outline(BytesIO(file.read()), password)
+        return jsonify({"outline": items}), 200
+
+    except InvalidPasswordError as e:
+        return jsonify({"error": str(e)}), 401
+
+    except Exception as e:
+        import traceback
+        traceback.print_exc()
+        return jsonify({"error": str(e)}), 500
+
+
 if __name__ == "__main__":
     app.run(host="0.0.0.0", port=8000, debug=True)
@@ -1,20 +1,165 @@
 import os
+import json
 from google import genai
 from dotenv import load_dotenv
-from service.prompts import PROMPTS
+from service.prompts import PROMPTS, PROMPT_VERSION, ANSWER_PROMPT, LANGUAGE_NAMES, SYNTHESIS_PROMPTS, STRUCTURE_PROMPT, STRUCTURE_SCHEMA, EXTRACTION_PROMPT, EXTRACTION_CORRECTIONS, ENTITY_PROMPT, ENTITY_SCHEMA, CLASSIFY_PROMPT
 
 load_dotenv()
 
 client = genai.Client(api_key=os.getenv("GEMINI_API_KEY"))
 
+MODEL = os.getenv("GEMINI_MODEL", "gemini-2.5-flash")
+
+
+def model_info():
+    """Model and prompt version that identify the output of summarize_text"""
+    return {"model": MODEL, "prompt_version": PROMPT_VERSION}
+
 def summarize_text(text, language='EN', style='professional'):
     """Generate summary using Gemini AI"""
     prompt_template = PROMPTS.get(style, {}).get(language, PROMPTS['professional']['EN'])
     prompt = f"{prompt_template}{text[:15000]}"
     
     response = client.models.generate_content(
-        model="gemini-2.5-flash",
+        model=MODEL,
         contents=prompt
     )
     
-    return response.text
\ No newline at end of file
+    return response.text
+
+def answer_question(question, passages, history=None, language='EN'):
+    """Answer a question grounded in numbered passages of a document"""
+    passage_text = "\n\n".join(
+        f"[{p['ref']}] (page {p['page']})\n{p['text']}" for p in passages
+    )
+    history_text = "\n".join(
+        f"{m['role']}: {m['content']}" for m in (history or [])
+    ) or "(none)"
+
+    prompt = ANSWER_PROMPT.format(
+        language=LANGUAGE_NAMES.get(language, "English"),
+        passages=passage_text[:30000],
+        history=history_text[-8000:],
+        question=question,
+    )
+
+    response = client.models.generate_content(
+        model=MODEL,
+        contents=prompt
+    )
+
+    return response.text
+
+
+def synthesize_documents(documents, mode='compare', language='EN', style='professional'):
+    """Generate one summary across several documents"""
+    # Every document gets an equal share of the input budget
+    budget = 30000 // max(len(documents), 1)
+    document_text = "\n\n".join(
+        f"### {d.get('title') or 'Untitled'} ({d.get('date') or 'undated'})\n{d['text'][:budget]}"
+        for d in documents
+    )
+
+    prompt = SYNTHESIS_PROMPTS.get(mode, SYNTHESIS_PROMPTS['compare']).format(
+        language=LANGUAGE_NAMES.get(language, "English"),
+        style="simple, conversational" if style == "simple" else "professional",
+        documents=document_text,
+    )
+
+    response = client.models.generate_content(
+        model=MODEL,
+        contents=prompt
+    )
+
+    return response.text
+
+
+def structure_summary(summary, language='EN'):
+    """Turn a Markdown summary into purpose, key points, findings, conclusions, keywords and takeaways"""
+    prompt = STRUCTURE_PROMPT.format(
+        language=LANGUAGE_NAMES.get(language, "English"),
+        summary=summary[:20000],
+    )
+
+    response = client.models.generate_content(
+        model=MODEL,
+        contents=prompt,
+        config={
+            "response_mime_type": "application/json",
+            "response_schema": STRUCTURE_SCHEMA,
+        },
+    )
+
+    return json.loads(response.text)
+
+
+def extract_fields(text, schema, errors=None):
+    """Extract the data described by a JSON Schema from document text"""
+    corrections = ""
+    if errors:
+        corrections = EXTRACTION_CORRECTIONS.format(
+            errors="\n".join(f"  - {e.get('path', '$')}: {e.get('message', '')}" for e in errors)
+        )
+
+    prompt = EXTRACTION_PROMPT.format(
+        schema=json.dumps(schema, ensure_ascii=False, indent=2),
+        corrections=corrections,
+        text=text[:30000],
+    )
+
+    response = client.models.generate_content(
+        model=MODEL,
+        contents=prompt,
+        config={"response_mime_type": "application/json"},
+    )
+
+    return json.loads(response.text)
+
+
+def extract_entities(text):
+    """List keywords, people, organizations, locations, dates and amounts in text"""
+    prompt = ENTITY_PROMPT.format(text=text[:30000])
+
+    response = client.models.generate_content(
+        model=MODEL,
+        contents=prompt,
+        config={
+            "response_mime_type": "application/json",
+            "response_schema": ENTITY_SCHEMA,
+        },
+    )
+
+    return json.loads(response.text).get("entities", [])
+
+
+def classify_document(text, categories):
+    """Pick the category of a document from a list of {category, label, description}"""
+    category_text = "\n".join(
+        f"- {c['category']}: {c.get('label') or c['category']}"
+        + (f" ({c['description']})" if c.get('description') else "")
+        for c in categories
+    )
+    schema = {
+        "type": "OBJECT",
+        "properties": {
+            "category": {
+                "type": "STRING",
+                "enum": [c["category"] for c in categories] + ["other"],
+            },
+            "confidence": {"type": "NUMBER"},
+        },
+        "required": ["category", "confidence"],
+    }
+
+    prompt = CLASSIFY_PROMPT.format(categories=category_text, text=text[:12000])
+
+    response = client.models.generate_content(
+        model=MODEL,
+        contents=prompt,
+        config={
+            "response_mime_type": "application/json",
+            "response_schema": schema,
+        },
+    )
+
+    return json.loads(response.text)
@@ -1,11 +1,64 @@
 from PyPDF2 import PdfReader
 
-def extract_text_from_pdf(pdf_file):
-    """Extract text from PDF file (BytesIO object)"""
+
+class InvalidPasswordError(Exception):
+    pass
+
+
+def open_pdf(pdf_file, password=None):
+    """Open a PDF, decrypting it with the given password when needed"""
     reader = PdfReader(pdf_file)
+    if reader.is_encrypted:
+        if not reader.decrypt(password or ""):
+            raise InvalidPasswordError("Invalid PDF password")
+    return reader
+
+def extract_pages_from_pdf(pdf_file, password=None):
+    """Extract text of every page, returns a list of {page, text}"""
+    reader = open_pdf(pdf_file, password)
+    pages = []
+    for index, page in enumerate(reader.pages, start=1):
+        pages.append({
+            "page": index,
+            "text": page.extract_text() or ""
+        })
+    return pages
+
+def extract_text_from_pdf(pdf_file, password=None):
+    """Extract text from PDF file (BytesIO object)"""
     text = ""
-    for page in reader.pages:
-        extracted = page.extract_text()
-        if extracted:
-            text += extracted + "\n"
-    return text
\ No newline at end of file
+    for page in extract_pages_from_pdf(pdf_file, password):
+        if page["text"]:
+            text += page["text"] + "\n"
+    return text
+
+MAX_OUTLINE_DEPTH = 32
+
+def extract_outline(pdf_file, password=None):
+    """Extract the bookmarks, returns a nested list of {title, page, children}.
+    Pages are 1-based and 0 when the destination has no page."""
+    reader = open_pdf(pdf_file, password)
+
+    def walk(nodes, depth):
+        items = []
+        for node in nodes:
+            # A nested list holds the children of the item before it
+            if isinstance(node, list):
+                if items and depth < MAX_OUTLINE_DEPTH:
+                    items[-1]["children"] = walk(node, depth + 1)
+                continue
+            try:
+                page = reader.get_destination_page_number(node) + 1
+            except Exception:
+                page = 0
+            items.append({
+                "title": " ".join(str(node.get("/Title", "")).split()),
+                "page": max(page, 0),
+                "children": []
+            })
+        return items
+
+    try:
+        return walk(reader.outline, 0)
+    except Exception:
+        return []
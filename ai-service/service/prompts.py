@@ -1,3 +1,6 @@
+# Bump whenever a prompt changes, cached summaries are keyed on it
+PROMPT_VERSION = "1"
+
 PROMPTS = {
     'professional': {
         "EN": """
@@ -171,4 +174,183 @@ PROMPTS = {
                 문서 내용:
             """
     }
-}
\ No newline at end of file
+}
+
+LANGUAGE_NAMES = {
+    "EN": "English",
+    "ID": "Indonesian",
+    "CN": "Chinese",
+    "JP": "Japanese",
+    "KR": "Korean",
+}
+
+ANSWER_PROMPT = """
+                You answer questions about a document using only the numbered passages below.
+
+                **Instructions:**
+                - Answer in {language}
+                - Use Markdown formatting
+                - Cite every statement with the number of the passage it comes from, e.g. [2]
+                - Only cite passage numbers that appear below
+                - If the passages do not contain the answer, say so instead of guessing
+
+                Passages:
+                {passages}
+
+                Conversation so far:
+                {history}
+
+                Question: {question}
+            """
+
+
+SYNTHESIS_PROMPTS = {
+    "compare": """
+                Compare the following related documents using Markdown formatting.
+
+                **Instructions:**
+                - Write in {language} with a {style} tone
+                - Use ## for section headers
+                - Start with a short overview of what the documents cover
+                - Describe the key similarities, then the key differences
+                - Include a Markdown table comparing the most important figures and facts side by side
+                - Refer to each document by its title
+                - End with a "Conclusions" section
+
+                Documents:
+                {documents}
+            """,
+
+    "merge": """
+                Merge the following related documents into one consolidated summary using Markdown formatting.
+
+                **Instructions:**
+                - Write in {language} with a {style} tone
+                - Use ## for section headers and bullet points (- ) for lists
+                - Combine overlapping information instead of repeating it
+                - Point out where the documents contradict each other
+                - Use **bold** for key terms and findings
+                - End with a "Keywords" section with 5-10 keywords in **bold**
+
+                Documents:
+                {documents}
+            """,
+
+    "timeline": """
+                Summarize how the topic develops across the following documents, which are ordered by date, using Markdown formatting.
+
+                **Instructions:**
+                - Write in {language} with a {style} tone
+                - Use one ## section per document, in the given order, headed by its date and title
+                - Highlight what changed compared to the previous document (new items, changed figures, dropped topics)
+                - End with a "Trends" section describing the overall development
+
+                Documents:
+                {documents}
+            """,
+}
+
+
+STRUCTURE_PROMPT = """
+                Convert the following document summary into structured data.
+
+                **Instructions:**
+                - Write every value in {language}
+                - purpose: one or two sentences on what the document is for
+                - key_points, findings, conclusions and takeaways: short, self-contained statements
+                - keywords: 5-10 of the most important keywords or key phrases, without formatting
+                - Only use information from the summary, leave a list empty if the summary has nothing for it
+
+                Summary:
+                {summary}
+            """
+
+# Response schema for STRUCTURE_PROMPT, mirrored by model.StructuredSummary in the backend
+STRUCTURE_SCHEMA = {
+    "type": "OBJECT",
+    "properties": {
+        "purpose": {"type": "STRING"},
+        "key_points": {"type": "ARRAY", "items": {"type": "STRING"}},
+        "findings": {"type": "ARRAY", "items": {"type": "STRING"}},
+        "conclusions": {"type": "ARRAY", "items": {"type": "STRING"}},
+        "keywords": {"type": "ARRAY", "items": {"type": "STRING"}},
+        "takeaways": {"type": "ARRAY", "items": {"type": "STRING"}},
+    },
+    "required": ["purpose", "key_points", "findings", "conclusions", "keywords", "takeaways"],
+}
+
+
+EXTRACTION_PROMPT = """
+                Extract data from the document below as a single JSON value matching this JSON Schema:
+
+                {schema}
+
+                **Instructions:**
+                - Only use information stated in the document, do not guess
+                - Use null for values the document does not contain, or leave out properties that are not required
+                - Write dates as YYYY-MM-DD
+                - Keep names, amounts and legal terms exactly as written in the document
+                {corrections}
+
+                Document content:
+                {text}
+            """
+
+EXTRACTION_CORRECTIONS = """
+                - A previous attempt returned data that does not match the schema, fix these errors:
+                {errors}
+            """
+
+
+ENTITY_PROMPT = """
+                List the named entities and keywords of the following text.
+
+                **Instructions:**
+                - person: people mentioned by name
+                - organization: companies, institutions, agencies and other organizations
+                - location: countries, cities, regions and addresses
+                - date: specific dates, written as YYYY-MM-DD when the day is known
+                - amount: monetary amounts with their currency
+                - keyword: 5-10 of the most important topics or key phrases
+                - Write names as they appear in the text, without formatting
+                - List every entity once
+
+                Text:
+                {text}
+            """
+
+ENTITY_SCHEMA = {
+    "type": "OBJECT",
+    "properties": {
+        "entities": {
+            "type": "ARRAY",
+            "items": {
+                "type": "OBJECT",
+                "properties": {
+                    "type": {
+                        "type": "STRING",
+                        "enum": ["keyword", "person", "organization", "location", "date", "amount"],
+                    },
+                    "name": {"type": "STRING"},
+                },
+                "required": ["type", "name"],
+            },
+        },
+    },
+    "required": ["entities"],
+}
+
+
+CLASSIFY_PROMPT = """
+                Classify the following document into exactly one of these categories:
+
+                {categories}
+
+                **Instructions:**
+                - Answer with the category identifier, or "other" if none of the categories fits
+                - confidence is a number between 0 and 1 for how certain the classification is
+                - Judge the type of document, not its topic
+
+                Document content:
+                {text}
+            """
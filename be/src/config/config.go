@@ -2,23 +2,60 @@ package config
 
 import (
 	"app/src/utils"
+	"crypto/rand"
+	"encoding/hex"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 )
 
 var (
-	IsProd       bool
-	AppHost      string
-	AppPort      int
-	DBHost       string
-	DBUser       string
-	DBPassword   string
-	DBName       string
-	DBPort       int
-	RedirectURL  string
-	UploadDir    = getEnv("UPLOAD_DIR", "./uploads")
-	MLServiceURL = getEnv("ML_SERVICE_URL", "http://localhost:8000")
+	IsProd        bool
+	AppHost       string
+	AppPort       int
+	DBHost        string
+	DBUser        string
+	DBPassword    string
+	DBName        string
+	DBPort        int
+	RedirectURL   string
+	UploadDir     = getEnv("UPLOAD_DIR", "./uploads")
+	QuarantineDir = getEnv("QUARANTINE_DIR", "./quarantine")
+	MLServiceURL  = getEnv("ML_SERVICE_URL", "http://localhost:8000")
+	ClamAVAddress = getEnv("CLAMAV_ADDRESS", "")
+	ClamAVTimeout = 30 * time.Second
+	OCREngine     = getEnv("OCR_ENGINE", "tesseract")
+	TesseractPath = getEnv("TESSERACT_PATH", "tesseract")
+	PdftoppmPath  = getEnv("PDFTOPPM_PATH", "pdftoppm")
+	QpdfPath      = getEnv("QPDF_PATH", "qpdf")
+	OCRLanguages  = getEnv("OCR_LANGUAGES", "eng")
+	OCRDPI        = 300
+
+	// authentication and signed file URLs
+	APIKeys         = parseAPIKeys(getEnv("API_KEYS", ""))
+	AdminPrincipals = parseList(getEnv("ADMIN_PRINCIPALS", ""))
+	SigningSecret   = getEnv("SIGNING_SECRET", "")
+	SignedURLTTL    = 15 * time.Minute
+	MaxSignedURLTTL = 24 * time.Hour
+
+	// how long responses of requests with an Idempotency-Key are replayed
+	IdempotencyTTL = getEnvDuration("IDEMPOTENCY_TTL", 24*time.Hour)
+
+	// embeddings for semantic search
+	EmbeddingProvider   = getEnv("EMBEDDING_PROVIDER", "hash")
+	EmbeddingURL        = getEnv("EMBEDDING_URL", "https://api.openai.com/v1")
+	EmbeddingAPIKey     = getEnv("EMBEDDING_API_KEY", "")
+	EmbeddingModel      = getEnv("EMBEDDING_MODEL", "text-embedding-3-small")
+	EmbeddingDimensions = 256
+	SemanticMinScore    = getEnvFloat("SEMANTIC_MIN_SCORE", 0.2)
+
+	// document classification, results below the minimum confidence leave
+	// the document unclassified
+	Classifier                  = getEnv("CLASSIFIER", "ai")
+	ClassificationMinConfidence = getEnvFloat("CLASSIFICATION_MIN_CONFIDENCE", 0.4)
 )
 
 func getEnv(key, fallback string) string {
@@ -28,6 +65,42 @@ func getEnv(key, fallback string) string {
 	return fallback
 }
 
+func getEnvDuration(key string, fallback time.Duration) time.Duration {
+	if v, err := time.ParseDuration(os.Getenv(key)); err == nil && v > 0 {
+		return v
+	}
+	return fallback
+}
+
+func getEnvFloat(key string, fallback float64) float64 {
+	if v, err := strconv.ParseFloat(os.Getenv(key), 64); err == nil {
+		return v
+	}
+	return fallback
+}
+
+// parseAPIKeys reads "name:key" pairs separated by commas
+func parseAPIKeys(value string) map[string]string {
+	keys := map[string]string{}
+	for _, pair := range strings.Split(value, ",") {
+		name, key, ok := strings.Cut(strings.TrimSpace(pair), ":")
+		if ok && name != "" && key != "" {
+			keys[name] = key
+		}
+	}
+	return keys
+}
+
+func parseList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
 func init() {
 	loadConfig()
 
@@ -42,6 +115,13 @@ func init() {
 	DBPassword = viper.GetString("DB_PASSWORD")
 	DBName = viper.GetString("DB_NAME")
 	DBPort = viper.GetInt("DB_PORT")
+
+	if SigningSecret == "" {
+		secret := make([]byte, 32)
+		_, _ = rand.Read(secret)
+		SigningSecret = hex.EncodeToString(secret)
+		utils.Log.Warn("SIGNING_SECRET is not set, signed URLs will be invalid after a restart")
+	}
 }
 
 func loadConfig() {
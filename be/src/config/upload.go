@@ -10,6 +10,10 @@ const (
 
 	// Allowed extensions
 	AllowedExtension = ".pdf"
+
+	// Supported PDF versions (inclusive)
+	MinPDFVersion = 1.0
+	MaxPDFVersion = 2.0
 )
 
 // PDF Magic Numbers
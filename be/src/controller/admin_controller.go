@@ -0,0 +1,123 @@
+package controller
+
+import (
+	"app/src/service"
+	"app/src/validation"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type AdminController struct {
+	PDFService    service.PDFService
+	SummaryCache  service.SummaryCacheService
+	SearchService service.SearchService
+	ChunkService  service.ChunkService
+	EntityService service.EntityService
+}
+
+func NewAdminController(
+	pdfService service.PDFService, summaryCache service.SummaryCacheService,
+	searchService service.SearchService, chunkService service.ChunkService,
+	entityService service.EntityService,
+) *AdminController {
+	return &AdminController{
+		PDFService:    pdfService,
+		SummaryCache:  summaryCache,
+		SearchService: searchService,
+		ChunkService:  chunkService,
+		EntityService: entityService,
+	}
+}
+
+func (c *AdminController) InvalidateSummaryCache(ctx *fiber.Ctx) error {
+	var query validation.InvalidateCacheQuery
+
+	if err := ctx.QueryParser(&query); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid query parameters")
+	}
+	if err := validation.Validator().Struct(query); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	filter := service.SummaryCacheFilter{
+		ContentHash:   query.ContentHash,
+		Language:      query.Language,
+		Style:         query.Style,
+		Model:         query.Model,
+		PromptVersion: query.PromptVersion,
+	}
+
+	if query.PDFID != "" {
+		pdf, err := c.PDFService.GetByID(ctx.Context(), uuid.MustParse(query.PDFID))
+		if err != nil {
+			return fiber.NewError(fiber.StatusNotFound, "PDF not found")
+		}
+		if pdf.ContentHash == "" {
+			return ctx.JSON(fiber.Map{
+				"message": "Summary cache invalidated",
+				"data":    fiber.Map{"deleted": 0},
+			})
+		}
+		filter.ContentHash = pdf.ContentHash
+	}
+
+	deleted, err := c.SummaryCache.Invalidate(ctx.Context(), filter)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	return ctx.JSON(fiber.Map{
+		"message": "Summary cache invalidated",
+		"data":    fiber.Map{"deleted": deleted},
+	})
+}
+
+func (c *AdminController) ReindexSearch(ctx *fiber.Ctx) error {
+	counts, err := c.SearchService.Reindex(ctx.Context())
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	return ctx.JSON(fiber.Map{
+		"message": "Search index rebuilt",
+		"data":    counts,
+	})
+}
+
+func (c *AdminController) ReindexEmbeddings(ctx *fiber.Ctx) error {
+	indexed, err := c.ChunkService.Reindex(ctx.Context())
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	return ctx.JSON(fiber.Map{
+		"message": "Chunk embeddings rebuilt",
+		"data":    fiber.Map{"pdfs": indexed},
+	})
+}
+
+func (c *AdminController) ReindexEntities(ctx *fiber.Ctx) error {
+	counts, err := c.EntityService.Reindex(ctx.Context())
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	return ctx.JSON(fiber.Map{
+		"message": "Entity index rebuilt",
+		"data":    counts,
+	})
+}
+
+// ReindexOutlines reads the bookmarks of PDFs stored without an outline
+func (c *AdminController) ReindexOutlines(ctx *fiber.Ctx) error {
+	counts, err := c.PDFService.ReindexOutlines(ctx.Context())
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	return ctx.JSON(fiber.Map{
+		"message": "Outlines extracted",
+		"data":    counts,
+	})
+}
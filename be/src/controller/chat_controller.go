@@ -0,0 +1,133 @@
+package controller
+
+import (
+	"app/src/middleware"
+	"app/src/service"
+	"app/src/validation"
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+type ChatController struct {
+	ChatService service.ChatService
+}
+
+func NewChatController(chatService service.ChatService) *ChatController {
+	return &ChatController{
+		ChatService: chatService,
+	}
+}
+
+// conversationOwner restricts conversations to the caller, admins see all
+func conversationOwner(ctx *fiber.Ctx) string {
+	if middleware.IsAdmin(ctx) {
+		return ""
+	}
+	return middleware.Principal(ctx)
+}
+
+func (c *ChatController) Ask(ctx *fiber.Ctx) error {
+	var params validation.PDFIDParam
+	var payload validation.AskQuestion
+
+	if err := ctx.ParamsParser(&params); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid PDF ID")
+	}
+	if err := ctx.BodyParser(&payload); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid request payload")
+	}
+
+	if err := validation.Validator().Struct(params); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	if err := validation.Validator().Struct(payload); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	answer, err := c.ChatService.Ask(ctx.Context(), params.ID, payload, middleware.Principal(ctx))
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return fiber.NewError(fiber.StatusNotFound, "PDF not found")
+		case errors.Is(err, service.ErrConversationNotFound):
+			return fiber.NewError(fiber.StatusNotFound, "Conversation not found")
+		case errors.Is(err, service.ErrDocumentNotIndexed):
+			return fiber.NewError(fiber.StatusConflict, err.Error())
+		case errors.Is(err, service.ErrAIService):
+			return fiber.NewError(fiber.StatusBadGateway, err.Error())
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	return ctx.JSON(fiber.Map{
+		"message": "Question answered successfully",
+		"data":    answer,
+	})
+}
+
+func (c *ChatController) GetConversations(ctx *fiber.Ctx) error {
+	var params validation.PDFIDParam
+
+	if err := ctx.ParamsParser(&params); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid PDF ID")
+	}
+	if err := validation.Validator().Struct(params); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	conversations, err := c.ChatService.GetConversations(ctx.Context(), params.ID, conversationOwner(ctx))
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	return ctx.JSON(fiber.Map{
+		"data": conversations,
+	})
+}
+
+func (c *ChatController) GetConversation(ctx *fiber.Ctx) error {
+	var params validation.ConversationIDParam
+
+	if err := ctx.ParamsParser(&params); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid conversation ID")
+	}
+	if err := validation.Validator().Struct(params); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	conversation, err := c.ChatService.GetConversation(ctx.Context(), params.ID, conversationOwner(ctx))
+	if err != nil {
+		if errors.Is(err, service.ErrConversationNotFound) {
+			return fiber.NewError(fiber.StatusNotFound, "Conversation not found")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	return ctx.JSON(fiber.Map{
+		"data": conversation,
+	})
+}
+
+func (c *ChatController) DeleteConversation(ctx *fiber.Ctx) error {
+	var params validation.ConversationIDParam
+
+	if err := ctx.ParamsParser(&params); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid conversation ID")
+	}
+	if err := validation.Validator().Struct(params); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	if err := c.ChatService.DeleteConversation(ctx.Context(), params.ID, conversationOwner(ctx)); err != nil {
+		if errors.Is(err, service.ErrConversationNotFound) {
+			return fiber.NewError(fiber.StatusNotFound, "Conversation not found")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	return ctx.JSON(fiber.Map{
+		"message": "Conversation deleted successfully",
+	})
+}
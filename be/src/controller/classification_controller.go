@@ -0,0 +1,183 @@
+package controller
+
+import (
+	"app/src/service"
+	"app/src/utils"
+	"app/src/validation"
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+type ClassificationController struct {
+	ClassificationService service.ClassificationService
+}
+
+func NewClassificationController(classificationService service.ClassificationService) *ClassificationController {
+	return &ClassificationController{
+		ClassificationService: classificationService,
+	}
+}
+
+func classificationError(err error, notFound string) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return fiber.NewError(fiber.StatusNotFound, notFound)
+	case errors.Is(err, service.ErrUnknownCategory), errors.Is(err, service.ErrInvalidCategory):
+		return fiber.NewError(fiber.StatusUnprocessableEntity, err.Error())
+	case errors.Is(err, service.ErrVersionConflict):
+		return fiber.NewError(fiber.StatusPreconditionFailed, err.Error())
+	}
+	return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+}
+
+// GetCategories lists the active categories PDFs can be filtered by
+func (c *ClassificationController) GetCategories(ctx *fiber.Ctx) error {
+	rules, err := c.ClassificationService.GetRules(ctx.Context(), true)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	return ctx.JSON(fiber.Map{
+		"data": rules,
+	})
+}
+
+// SetCategory overrides the category of a PDF, a null category hands it back
+// to automatic classification
+func (c *ClassificationController) SetCategory(ctx *fiber.Ctx) error {
+	var params validation.PDFIDParam
+	var payload validation.SetCategory
+
+	if err := ctx.ParamsParser(&params); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid PDF ID")
+	}
+	if err := ctx.BodyParser(&payload); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid request payload")
+	}
+
+	if err := validation.Validator().Struct(params); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	if err := validation.Validator().Struct(payload); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	version, err := ifMatchVersion(ctx)
+	if err != nil {
+		return err
+	}
+
+	pdf, err := c.ClassificationService.SetCategory(ctx.Context(), params.ID, payload.Category, version)
+	if err != nil {
+		return classificationError(err, "PDF not found")
+	}
+
+	ctx.Set(fiber.HeaderETag, utils.ETag(pdf.Version))
+
+	return ctx.JSON(fiber.Map{
+		"message": "Category updated successfully",
+		"data":    pdf,
+	})
+}
+
+func (c *ClassificationController) Classify(ctx *fiber.Ctx) error {
+	var params validation.PDFIDParam
+
+	if err := ctx.ParamsParser(&params); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid PDF ID")
+	}
+	if err := validation.Validator().Struct(params); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	pdf, err := c.ClassificationService.Classify(ctx.Context(), params.ID)
+	if err != nil {
+		return classificationError(err, "PDF not found")
+	}
+
+	return ctx.JSON(fiber.Map{
+		"message": "PDF classified",
+		"data":    pdf,
+	})
+}
+
+func (c *ClassificationController) GetRules(ctx *fiber.Ctx) error {
+	rules, err := c.ClassificationService.GetRules(ctx.Context(), false)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	return ctx.JSON(fiber.Map{
+		"data": rules,
+	})
+}
+
+func (c *ClassificationController) UpsertRule(ctx *fiber.Ctx) error {
+	var params validation.ClassificationRuleParam
+	var payload validation.UpsertClassificationRule
+
+	if err := ctx.ParamsParser(&params); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid category")
+	}
+	if err := ctx.BodyParser(&payload); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid request payload")
+	}
+
+	if err := validation.Validator().Struct(params); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	if err := validation.Validator().Struct(payload); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	rule, err := c.ClassificationService.UpsertRule(ctx.Context(), params.Category, payload)
+	if err != nil {
+		return classificationError(err, "Category not found")
+	}
+
+	return ctx.JSON(fiber.Map{
+		"message": "Classification rule saved successfully",
+		"data":    rule,
+	})
+}
+
+func (c *ClassificationController) DeleteRule(ctx *fiber.Ctx) error {
+	var params validation.ClassificationRuleParam
+
+	if err := ctx.ParamsParser(&params); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid category")
+	}
+	if err := validation.Validator().Struct(params); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	if err := c.ClassificationService.DeleteRule(ctx.Context(), params.Category); err != nil {
+		return classificationError(err, "Category not found")
+	}
+
+	return ctx.JSON(fiber.Map{
+		"message": "Classification rule deleted successfully",
+	})
+}
+
+// Retrain learns keywords from the manually categorized PDFs and optionally
+// classifies the other PDFs again with them
+func (c *ClassificationController) Retrain(ctx *fiber.Ctx) error {
+	var query validation.RetrainQuery
+
+	if err := ctx.QueryParser(&query); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid query parameters")
+	}
+
+	result, err := c.ClassificationService.Retrain(ctx.Context(), query.Reclassify)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	return ctx.JSON(fiber.Map{
+		"message": "Classification rules retrained",
+		"data":    result,
+	})
+}
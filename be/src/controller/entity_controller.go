@@ -0,0 +1,97 @@
+package controller
+
+import (
+	"app/src/model"
+	"app/src/service"
+	"app/src/validation"
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+type EntityController struct {
+	EntityService service.EntityService
+}
+
+func NewEntityController(entityService service.EntityService) *EntityController {
+	return &EntityController{
+		EntityService: entityService,
+	}
+}
+
+// GetTop lists the keywords and named entities mentioned in the most PDFs
+func (c *EntityController) GetTop(ctx *fiber.Ctx) error {
+	var query validation.EntityQuery
+
+	if err := ctx.QueryParser(&query); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid query parameters")
+	}
+	if err := validation.Validator().Struct(query); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	entities, err := c.EntityService.GetTop(ctx.Context(), query)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	return ctx.JSON(fiber.Map{
+		"data": entities,
+	})
+}
+
+func (c *EntityController) GetEntityPDFs(ctx *fiber.Ctx) error {
+	var params validation.EntityIDParam
+	var query validation.EntityPDFsQuery
+
+	if err := ctx.ParamsParser(&params); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid entity ID")
+	}
+	if err := ctx.QueryParser(&query); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid query parameters")
+	}
+
+	if err := validation.Validator().Struct(params); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	if err := validation.Validator().Struct(query); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	links, meta, err := c.EntityService.GetPDFs(ctx.Context(), params.ID, query)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fiber.NewError(fiber.StatusNotFound, "Entity not found")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	return ctx.JSON(model.PaginatedResponse{
+		Data: links,
+		Meta: *meta,
+	})
+}
+
+func (c *EntityController) GetPDFEntities(ctx *fiber.Ctx) error {
+	var params validation.PDFIDParam
+
+	if err := ctx.ParamsParser(&params); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid PDF ID")
+	}
+	if err := validation.Validator().Struct(params); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	links, err := c.EntityService.GetForPDF(ctx.Context(), params.ID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fiber.NewError(fiber.StatusNotFound, "PDF not found")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	return ctx.JSON(fiber.Map{
+		"data": links,
+	})
+}
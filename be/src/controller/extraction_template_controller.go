@@ -0,0 +1,355 @@
+package controller
+
+import (
+	"app/src/middleware"
+	"app/src/model"
+	"app/src/service"
+	"app/src/utils"
+	"app/src/validation"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+type ExtractionTemplateController struct {
+	TemplateService service.ExtractionTemplateService
+}
+
+func NewExtractionTemplateController(templateService service.ExtractionTemplateService) *ExtractionTemplateController {
+	return &ExtractionTemplateController{
+		TemplateService: templateService,
+	}
+}
+
+func templateError(err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return fiber.NewError(fiber.StatusNotFound, "Extraction template not found")
+	case errors.Is(err, service.ErrInvalidSchema):
+		return fiber.NewError(fiber.StatusUnprocessableEntity, err.Error())
+	case errors.Is(err, service.ErrTemplateNameTaken):
+		return fiber.NewError(fiber.StatusConflict, err.Error())
+	}
+	return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+}
+
+func (c *ExtractionTemplateController) CreateTemplate(ctx *fiber.Ctx) error {
+	var payload validation.CreateExtractionTemplate
+
+	if err := ctx.BodyParser(&payload); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid request payload")
+	}
+	if err := validation.Validator().Struct(payload); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	template, err := c.TemplateService.Create(ctx.Context(), payload, middleware.Principal(ctx))
+	if err != nil {
+		return templateError(err)
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Extraction template created successfully",
+		"data":    template,
+	})
+}
+
+func (c *ExtractionTemplateController) GetTemplates(ctx *fiber.Ctx) error {
+	templates, err := c.TemplateService.GetAll(ctx.Context())
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	return ctx.JSON(fiber.Map{
+		"data": templates,
+	})
+}
+
+func (c *ExtractionTemplateController) GetTemplate(ctx *fiber.Ctx) error {
+	var params validation.TemplateIDParam
+
+	if err := ctx.ParamsParser(&params); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid template ID")
+	}
+	if err := validation.Validator().Struct(params); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	template, err := c.TemplateService.Get(ctx.Context(), params.ID.String())
+	if err != nil {
+		return templateError(err)
+	}
+
+	return ctx.JSON(fiber.Map{
+		"data": template,
+	})
+}
+
+func (c *ExtractionTemplateController) UpdateTemplate(ctx *fiber.Ctx) error {
+	var params validation.TemplateIDParam
+	var payload validation.UpdateExtractionTemplate
+
+	if err := ctx.ParamsParser(&params); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid template ID")
+	}
+	if err := ctx.BodyParser(&payload); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid request payload")
+	}
+
+	if err := validation.Validator().Struct(params); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	if err := validation.Validator().Struct(payload); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	template, err := c.TemplateService.Update(ctx.Context(), params.ID, payload)
+	if err != nil {
+		return templateError(err)
+	}
+
+	return ctx.JSON(fiber.Map{
+		"message": "Extraction template updated successfully",
+		"data":    template,
+	})
+}
+
+func (c *ExtractionTemplateController) DeleteTemplate(ctx *fiber.Ctx) error {
+	var params validation.TemplateIDParam
+
+	if err := ctx.ParamsParser(&params); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid template ID")
+	}
+	if err := validation.Validator().Struct(params); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	if err := c.TemplateService.Delete(ctx.Context(), params.ID); err != nil {
+		return templateError(err)
+	}
+
+	return ctx.JSON(fiber.Map{
+		"message": "Extraction template deleted successfully",
+	})
+}
+
+func (c *ExtractionTemplateController) Run(ctx *fiber.Ctx) error {
+	var params validation.PDFIDParam
+	var query validation.RunExtraction
+
+	if err := ctx.ParamsParser(&params); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid PDF ID")
+	}
+	if err := ctx.QueryParser(&query); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid query parameters")
+	}
+
+	if err := validation.Validator().Struct(params); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	if err := validation.Validator().Struct(query); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	// The template is looked up first, a missing record is reported as such
+	if _, err := c.TemplateService.Get(ctx.Context(), query.Template); err != nil {
+		return templateError(err)
+	}
+
+	result, err := c.TemplateService.Run(ctx.Context(), params.ID, query.Template, middleware.Principal(ctx))
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return fiber.NewError(fiber.StatusNotFound, "PDF not found")
+		case errors.Is(err, service.ErrPDFLocked):
+			return fiber.NewError(fiber.StatusLocked, err.Error())
+		case errors.Is(err, service.ErrPDFUnavailable):
+			return fiber.NewError(fiber.StatusConflict, err.Error())
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	return ctx.Status(fiber.StatusAccepted).JSON(fiber.Map{
+		"message": "Extraction started",
+		"data":    result,
+	})
+}
+
+func (c *ExtractionTemplateController) GetPDFResults(ctx *fiber.Ctx) error {
+	var params validation.PDFIDParam
+
+	if err := ctx.ParamsParser(&params); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid PDF ID")
+	}
+	if err := validation.Validator().Struct(params); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	results, err := c.TemplateService.GetResults(ctx.Context(), params.ID)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	return ctx.JSON(fiber.Map{
+		"data": results,
+	})
+}
+
+func (c *ExtractionTemplateController) GetResult(ctx *fiber.Ctx) error {
+	var params validation.ExtractionIDParam
+
+	if err := ctx.ParamsParser(&params); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid extraction ID")
+	}
+	if err := validation.Validator().Struct(params); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	result, err := c.TemplateService.GetResult(ctx.Context(), params.ID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fiber.NewError(fiber.StatusNotFound, "Extraction not found")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	return ctx.JSON(fiber.Map{
+		"data": result,
+	})
+}
+
+// GetTemplateResults lists the latest result of a template for every PDF and
+// exports them with one column per top-level schema property
+func (c *ExtractionTemplateController) GetTemplateResults(ctx *fiber.Ctx) error {
+	var params validation.TemplateIDParam
+	var query validation.ExtractionResultsQuery
+
+	if err := ctx.ParamsParser(&params); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid template ID")
+	}
+	if err := ctx.QueryParser(&query); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid query parameters")
+	}
+
+	if err := validation.Validator().Struct(params); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	if err := validation.Validator().Struct(query); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	if query.Export != "" {
+		query.Limit = 10000
+		query.Page = 1
+	}
+
+	results, meta, err := c.TemplateService.GetTemplateResults(ctx.Context(), params.ID, query)
+	if err != nil {
+		return templateError(err)
+	}
+
+	switch query.Export {
+	case "csv":
+		template, err := c.TemplateService.Get(ctx.Context(), params.ID.String())
+		if err != nil {
+			return templateError(err)
+		}
+		return c.exportResultsCSV(ctx, template, results)
+	case "json":
+		ctx.Set("Content-Type", "application/json; charset=utf-8")
+		ctx.Set("Content-Disposition", utils.ContentDisposition("attachment", fmt.Sprintf("extractions_%s.json", time.Now().Format("20060102_150405"))))
+
+		data, err := json.MarshalIndent(results, "", "  ")
+		if err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, "Failed to generate JSON")
+		}
+		return ctx.Send(data)
+	}
+
+	return ctx.JSON(model.PaginatedResponse{
+		Data: results,
+		Meta: *meta,
+	})
+}
+
+func (c *ExtractionTemplateController) exportResultsCSV(ctx *fiber.Ctx, template *model.ExtractionTemplate, results []model.ExtractionResult) error {
+	ctx.Set("Content-Type", "text/csv; charset=utf-8")
+	ctx.Set("Content-Disposition", utils.ContentDisposition("attachment", fmt.Sprintf("extractions_%s_%s.csv", template.Name, time.Now().Format("20060102_150405"))))
+
+	// The byte order mark lets spreadsheet applications detect UTF-8 names
+	ctx.WriteString("\ufeff")
+
+	writer := csv.NewWriter(ctx)
+	defer writer.Flush()
+
+	fields := utils.SchemaProperties(template.Schema)
+	headers := append([]string{"PDF ID", "Document", "Status", "Template Version"}, fields...)
+	headers = append(headers, "Extracted At")
+	if err := writer.Write(headers); err != nil {
+		return err
+	}
+
+	for _, result := range results {
+		document := ""
+		if result.PDF != nil {
+			document = result.PDF.OriginalName
+		}
+
+		var data map[string]interface{}
+		if result.Data != nil {
+			_ = json.Unmarshal(*result.Data, &data)
+		}
+
+		row := []string{
+			result.PDFID.String(),
+			document,
+			result.Status,
+			strconv.Itoa(result.TemplateVersion),
+		}
+		for _, field := range fields {
+			row = append(row, csvValue(data[field]))
+		}
+		row = append(row, result.UpdatedAt.Format(time.RFC3339))
+
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// csvValue flattens an extracted value into one cell. Lists of plain values
+// are joined, objects are kept as JSON.
+func csvValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case []interface{}:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			switch item.(type) {
+			case map[string]interface{}, []interface{}:
+				data, _ := json.Marshal(v)
+				return string(data)
+			}
+			items = append(items, csvValue(item))
+		}
+		return strings.Join(items, "; ")
+	}
+	data, _ := json.Marshal(value)
+	return string(data)
+}
@@ -1,16 +1,24 @@
 package controller
 
 import (
+	"app/src/config"
+	"app/src/middleware"
 	"app/src/model"
+	"app/src/pdfparser"
 	"app/src/service"
+	"app/src/utils"
 	"app/src/validation"
 	"encoding/csv"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"os"
+	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 type PDFController struct {
@@ -25,6 +33,15 @@ func NewPDFController(pdfService service.PDFService, summaryService service.Summ
 	}
 }
 
+// ifMatchVersion reads the version required by the If-Match header, 0 when absent
+func ifMatchVersion(ctx *fiber.Ctx) (int, error) {
+	version, ok := utils.ParseIfMatch(ctx.Get(fiber.HeaderIfMatch))
+	if !ok {
+		return 0, fiber.NewError(fiber.StatusPreconditionFailed, "If-Match does not match the current version")
+	}
+	return version, nil
+}
+
 func (c *PDFController) Upload(ctx *fiber.Ctx) error {
 	file, err := ctx.FormFile("file")
 	if err != nil {
@@ -36,6 +53,21 @@ func (c *PDFController) Upload(ctx *fiber.Ctx) error {
 
 	pdf, err := c.PDFService.Create(ctx.Context(), file)
 	if err != nil {
+		var pdfErr *pdfparser.Error
+		if errors.As(err, &pdfErr) {
+			return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"message": err.Error(),
+				"code":    pdfErr.Code,
+			})
+		}
+
+		if errors.Is(err, service.ErrMalwareDetected) {
+			return ctx.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+				"message": err.Error(),
+				"code":    "malware_detected",
+			})
+		}
+
 		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"message": err.Error(),
 		})
@@ -92,13 +124,16 @@ func (c *PDFController) exportPDFs(ctx *fiber.Ctx, params validation.QueryParams
 }
 
 func (c *PDFController) exportPDFsCSV(ctx *fiber.Ctx, pdfs []model.PDF) error {
-	ctx.Set("Content-Type", "text/csv")
-	ctx.Set("Content-Disposition", fmt.Sprintf("attachment; filename=pdfs_%s.csv", time.Now().Format("20060102_150405")))
+	ctx.Set("Content-Type", "text/csv; charset=utf-8")
+	ctx.Set("Content-Disposition", utils.ContentDisposition("attachment", fmt.Sprintf("pdfs_%s.csv", time.Now().Format("20060102_150405"))))
+
+	// The byte order mark lets spreadsheet applications detect UTF-8 names
+	ctx.WriteString("\ufeff")
 
 	writer := csv.NewWriter(ctx)
 	defer writer.Flush()
 
-	headers := []string{"ID", "Original Name", "File Size (MB)", "Status", "Uploaded At"}
+	headers := []string{"ID", "Original Name", "Title", "Author", "Pages", "File Size (MB)", "Status", "Uploaded At"}
 	if err := writer.Write(headers); err != nil {
 		return err
 	}
@@ -107,6 +142,9 @@ func (c *PDFController) exportPDFsCSV(ctx *fiber.Ctx, pdfs []model.PDF) error {
 		row := []string{
 			pdf.ID.String(),
 			pdf.OriginalName,
+			pdf.Title,
+			pdf.Author,
+			fmt.Sprintf("%d", pdf.PageCount),
 			fmt.Sprintf("%.2f", float64(pdf.FileSize)/1024/1024),
 			pdf.Status,
 			pdf.UploadedAt.Format(time.RFC3339),
@@ -120,8 +158,8 @@ func (c *PDFController) exportPDFsCSV(ctx *fiber.Ctx, pdfs []model.PDF) error {
 }
 
 func (c *PDFController) exportPDFsJSON(ctx *fiber.Ctx, pdfs []model.PDF) error {
-	ctx.Set("Content-Type", "application/json")
-	ctx.Set("Content-Disposition", fmt.Sprintf("attachment; filename=pdfs_%s.json", time.Now().Format("20060102_150405")))
+	ctx.Set("Content-Type", "application/json; charset=utf-8")
+	ctx.Set("Content-Disposition", utils.ContentDisposition("attachment", fmt.Sprintf("pdfs_%s.json", time.Now().Format("20060102_150405"))))
 
 	data, err := json.MarshalIndent(pdfs, "", "  ")
 	if err != nil {
@@ -146,11 +184,120 @@ func (c *PDFController) GetPDF(ctx *fiber.Ctx) error {
 		return fiber.NewError(fiber.StatusNotFound, "PDF not found")
 	}
 
+	// The version only identifies stored fields. Signed file links expire, so
+	// they stay out of this representation and are issued by /file/sign.
+	ctx.Set(fiber.HeaderETag, utils.ETag(pdf.Version))
+	if utils.MatchETag(ctx.Get(fiber.HeaderIfNoneMatch), pdf.Version) {
+		return ctx.SendStatus(fiber.StatusNotModified)
+	}
+
 	return ctx.JSON(fiber.Map{
 		"data": pdf,
 	})
 }
 
+func (c *PDFController) GetOutline(ctx *fiber.Ctx) error {
+	var params validation.PDFIDParam
+
+	if err := ctx.ParamsParser(&params); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid PDF ID")
+	}
+	if err := validation.Validator().Struct(params); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	outline, err := c.PDFService.GetOutline(ctx.Context(), params.ID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fiber.NewError(fiber.StatusNotFound, "PDF not found")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	return ctx.JSON(fiber.Map{
+		"data": outline,
+	})
+}
+
+func (c *PDFController) GetFile(ctx *fiber.Ctx) error {
+	var params validation.PDFIDParam
+
+	if err := ctx.ParamsParser(&params); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid PDF ID")
+	}
+	if err := validation.Validator().Struct(params); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	pdf, err := c.PDFService.GetByID(ctx.Context(), params.ID)
+	if err != nil {
+		return fiber.NewError(fiber.StatusNotFound, "PDF not found")
+	}
+
+	// Failed uploads may have been quarantined, never serve them
+	if pdf.Status == "failed" {
+		return fiber.NewError(fiber.StatusNotFound, "PDF file is not available")
+	}
+	if _, err := os.Stat(pdf.FilePath); err != nil {
+		return fiber.NewError(fiber.StatusNotFound, "PDF file is not available")
+	}
+
+	disposition := "inline"
+	if ctx.QueryBool("download") {
+		disposition = "attachment"
+	}
+
+	ctx.Set(fiber.HeaderContentDisposition, utils.ContentDisposition(disposition, pdf.OriginalName))
+	ctx.Set(fiber.HeaderCacheControl, "private, no-cache")
+	ctx.Set(fiber.HeaderXContentTypeOptions, "nosniff")
+	ctx.Set("Cross-Origin-Resource-Policy", "cross-origin")
+
+	// SendFile answers Range requests with 206 Partial Content
+	return ctx.SendFile(pdf.FilePath, false)
+}
+
+func (c *PDFController) SignFileURL(ctx *fiber.Ctx) error {
+	var params validation.PDFIDParam
+	var payload validation.SignFileURL
+
+	if err := ctx.ParamsParser(&params); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid PDF ID")
+	}
+	if len(ctx.Body()) > 0 {
+		if err := ctx.BodyParser(&payload); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "Invalid request payload")
+		}
+	}
+
+	if err := validation.Validator().Struct(params); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	if err := validation.Validator().Struct(payload); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	if _, err := c.PDFService.GetByID(ctx.Context(), params.ID); err != nil {
+		return fiber.NewError(fiber.StatusNotFound, "PDF not found")
+	}
+
+	ttl := config.SignedURLTTL
+	if payload.TTL > 0 {
+		ttl = time.Duration(payload.TTL) * time.Second
+	}
+	if ttl > config.MaxSignedURLTTL {
+		ttl = config.MaxSignedURLTTL
+	}
+
+	url, expiresAt := c.PDFService.SignedFileURL(params.ID, ttl)
+
+	return ctx.JSON(fiber.Map{
+		"data": fiber.Map{
+			"url":        url,
+			"expires_at": expiresAt,
+		},
+	})
+}
+
 func (c *PDFController) DeletePDF(ctx *fiber.Ctx) error {
 	var params validation.PDFIDParam
 
@@ -161,7 +308,18 @@ func (c *PDFController) DeletePDF(ctx *fiber.Ctx) error {
 		return fiber.NewError(fiber.StatusBadRequest, err.Error())
 	}
 
-	if err := c.PDFService.Delete(ctx.Context(), params.ID); err != nil {
+	version, err := ifMatchVersion(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := c.PDFService.Delete(ctx.Context(), params.ID, version); err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return fiber.NewError(fiber.StatusNotFound, "PDF not found")
+		case errors.Is(err, service.ErrVersionConflict):
+			return fiber.NewError(fiber.StatusPreconditionFailed, err.Error())
+		}
 		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
 	}
 
@@ -170,6 +328,43 @@ func (c *PDFController) DeletePDF(ctx *fiber.Ctx) error {
 	})
 }
 
+func (c *PDFController) UnlockPDF(ctx *fiber.Ctx) error {
+	var params validation.PDFIDParam
+	var payload validation.UnlockPDF
+
+	if err := ctx.ParamsParser(&params); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid PDF ID")
+	}
+	if err := ctx.BodyParser(&payload); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid request payload")
+	}
+
+	if err := validation.Validator().Struct(params); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	if err := validation.Validator().Struct(payload); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	pdf, err := c.PDFService.Unlock(ctx.Context(), params.ID, payload.Password)
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return fiber.NewError(fiber.StatusNotFound, "PDF not found")
+		case errors.Is(err, service.ErrPDFNotLocked):
+			return fiber.NewError(fiber.StatusConflict, err.Error())
+		case errors.Is(err, service.ErrInvalidPassword):
+			return fiber.NewError(fiber.StatusUnauthorized, err.Error())
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	return ctx.JSON(fiber.Map{
+		"message": "PDF unlocked successfully",
+		"data":    pdf,
+	})
+}
+
 func (c *PDFController) GenerateSummary(ctx *fiber.Ctx) error {
 	var params validation.PDFIDParam
 	var payload validation.GenerateSummary
@@ -188,13 +383,61 @@ func (c *PDFController) GenerateSummary(ctx *fiber.Ctx) error {
 		return fiber.NewError(fiber.StatusBadRequest, err.Error())
 	}
 
-	summary, err := c.SummaryService.Create(ctx.Context(), params.ID, payload.Language, payload.Style)
+	payload.Force = payload.Force || ctx.QueryBool("force")
+
+	summary, err := c.SummaryService.Create(ctx.Context(), params.ID, payload)
 	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return fiber.NewError(fiber.StatusNotFound, "PDF not found")
+		case errors.Is(err, service.ErrPDFLocked):
+			return fiber.NewError(fiber.StatusLocked, err.Error())
+		case errors.Is(err, service.ErrPDFUnavailable):
+			return fiber.NewError(fiber.StatusConflict, err.Error())
+		case errors.Is(err, service.ErrInvalidPageRange),
+			errors.Is(err, service.ErrNoOutline),
+			errors.Is(err, service.ErrTooManyChapters):
+			return fiber.NewError(fiber.StatusUnprocessableEntity, err.Error())
+		}
 		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
 	}
 
+	message := "Summary generation started"
+	if summary.Status == "completed" {
+		message = "Summary served from cache"
+	}
+
 	return ctx.Status(fiber.StatusCreated).JSON(fiber.Map{
-		"message": "Summary generation started",
+		"message": message,
+		"data":    summary,
+	})
+}
+
+func (c *PDFController) GenerateSynthesis(ctx *fiber.Ctx) error {
+	var payload validation.GenerateSynthesis
+
+	if err := ctx.BodyParser(&payload); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid request payload")
+	}
+	if err := validation.Validator().Struct(payload); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	summary, err := c.SummaryService.CreateSynthesis(ctx.Context(), payload)
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return fiber.NewError(fiber.StatusNotFound, "One or more PDFs not found")
+		case errors.Is(err, service.ErrPDFLocked):
+			return fiber.NewError(fiber.StatusLocked, err.Error())
+		case errors.Is(err, service.ErrPDFUnavailable):
+			return fiber.NewError(fiber.StatusConflict, err.Error())
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Synthesis generation started",
 		"data":    summary,
 	})
 }
@@ -252,13 +495,16 @@ func (c *PDFController) exportSummaries(ctx *fiber.Ctx, pdfID uuid.UUID, params
 }
 
 func (c *PDFController) exportSummariesCSV(ctx *fiber.Ctx, summaries []model.Summary) error {
-	ctx.Set("Content-Type", "text/csv")
-	ctx.Set("Content-Disposition", fmt.Sprintf("attachment; filename=summaries_%s.csv", time.Now().Format("20060102_150405")))
+	ctx.Set("Content-Type", "text/csv; charset=utf-8")
+	ctx.Set("Content-Disposition", utils.ContentDisposition("attachment", fmt.Sprintf("summaries_%s.csv", time.Now().Format("20060102_150405"))))
+
+	// The byte order mark lets spreadsheet applications detect UTF-8 names
+	ctx.WriteString("\ufeff")
 
 	writer := csv.NewWriter(ctx)
 	defer writer.Flush()
 
-	headers := []string{"ID", "PDF ID", "Language", "Style", "Status", "Content Preview", "Created At"}
+	headers := []string{"ID", "PDF ID", "Language", "Style", "Pages", "Status", "Keywords", "Content Preview", "Created At"}
 	if err := writer.Write(headers); err != nil {
 		return err
 	}
@@ -269,12 +515,24 @@ func (c *PDFController) exportSummariesCSV(ctx *fiber.Ctx, summaries []model.Sum
 			contentPreview = contentPreview[:100] + "..."
 		}
 
+		pages := "all"
+		if summary.IsPartial() {
+			pages = fmt.Sprintf("%d-%d", *summary.PageFrom, *summary.PageTo)
+		}
+
+		var structured model.StructuredSummary
+		if summary.Structured != nil {
+			_ = json.Unmarshal(*summary.Structured, &structured)
+		}
+
 		row := []string{
 			summary.ID.String(),
 			summary.PDFID.String(),
 			summary.Language,
 			summary.Style,
+			pages,
 			summary.Status,
+			strings.Join(structured.Keywords, "; "),
 			contentPreview,
 			summary.CreatedAt.Format(time.RFC3339),
 		}
@@ -287,8 +545,8 @@ func (c *PDFController) exportSummariesCSV(ctx *fiber.Ctx, summaries []model.Sum
 }
 
 func (c *PDFController) exportSummariesJSON(ctx *fiber.Ctx, summaries []model.Summary) error {
-	ctx.Set("Content-Type", "application/json")
-	ctx.Set("Content-Disposition", fmt.Sprintf("attachment; filename=summaries_%s.json", time.Now().Format("20060102_150405")))
+	ctx.Set("Content-Type", "application/json; charset=utf-8")
+	ctx.Set("Content-Disposition", utils.ContentDisposition("attachment", fmt.Sprintf("summaries_%s.json", time.Now().Format("20060102_150405"))))
 
 	data, err := json.MarshalIndent(summaries, "", "  ")
 	if err != nil {
@@ -313,11 +571,56 @@ func (c *PDFController) GetSummaryByID(ctx *fiber.Ctx) error {
 		return fiber.NewError(fiber.StatusNotFound, "Summary not found")
 	}
 
+	ctx.Set(fiber.HeaderETag, utils.ETag(summary.Version))
+	if utils.MatchETag(ctx.Get(fiber.HeaderIfNoneMatch), summary.Version) {
+		return ctx.SendStatus(fiber.StatusNotModified)
+	}
+
 	return ctx.JSON(fiber.Map{
 		"data": summary,
 	})
 }
 
+// GetSummaryAttributions returns the summary with a page anchor after every
+// paragraph and list item that could be matched to the source text
+func (c *PDFController) GetSummaryAttributions(ctx *fiber.Ctx) error {
+	var params validation.SummaryIDParam
+
+	if err := ctx.ParamsParser(&params); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid summary ID")
+	}
+	if err := validation.Validator().Struct(params); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	attributions, err := c.SummaryService.Attributions(ctx.Context(), params.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return fiber.NewError(fiber.StatusNotFound, "Summary not found")
+		case errors.Is(err, service.ErrSummaryNotCompleted):
+			return fiber.NewError(fiber.StatusConflict, err.Error())
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	// Links point at the file endpoint, which needs an API key or a link
+	// signed through /file/sign
+	annotated := utils.AnnotateMarkdown(attributions.Content, attributions.Blocks, func(ref utils.PageRef) string {
+		return fmt.Sprintf("/v1/pdfs/%s/file#page=%d", ref.PDFID, ref.Page)
+	})
+
+	return ctx.JSON(fiber.Map{
+		"data": fiber.Map{
+			"summary_id": attributions.SummaryID,
+			"version":    attributions.Version,
+			"annotated":  annotated,
+			"stored":     attributions.Stored,
+			"blocks":     attributions.Blocks,
+		},
+	})
+}
+
 func (c *PDFController) UpdateSummary(ctx *fiber.Ctx) error {
 	var params validation.SummaryIDParam
 	var payload validation.UpdateSummary
@@ -336,12 +639,27 @@ func (c *PDFController) UpdateSummary(ctx *fiber.Ctx) error {
 		return fiber.NewError(fiber.StatusBadRequest, err.Error())
 	}
 
-	if err := c.SummaryService.Update(ctx.Context(), params.ID, payload.Content); err != nil {
+	version, err := ifMatchVersion(ctx)
+	if err != nil {
+		return err
+	}
+
+	summary, err := c.SummaryService.Update(ctx.Context(), params.ID, payload.Content, middleware.Principal(ctx), version)
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return fiber.NewError(fiber.StatusNotFound, "Summary not found")
+		case errors.Is(err, service.ErrVersionConflict):
+			return fiber.NewError(fiber.StatusPreconditionFailed, err.Error())
+		}
 		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
 	}
 
+	ctx.Set(fiber.HeaderETag, utils.ETag(summary.Version))
+
 	return ctx.JSON(fiber.Map{
 		"message": "Summary updated successfully",
+		"data":    summary,
 	})
 }
 
@@ -355,11 +673,157 @@ func (c *PDFController) DeleteSummary(ctx *fiber.Ctx) error {
 		return fiber.NewError(fiber.StatusBadRequest, err.Error())
 	}
 
-	if err := c.SummaryService.Delete(ctx.Context(), params.ID); err != nil {
+	version, err := ifMatchVersion(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := c.SummaryService.Delete(ctx.Context(), params.ID, version); err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return fiber.NewError(fiber.StatusNotFound, "Summary not found")
+		case errors.Is(err, service.ErrVersionConflict):
+			return fiber.NewError(fiber.StatusPreconditionFailed, err.Error())
+		}
 		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
 	}
 
 	return ctx.JSON(fiber.Map{
 		"message": "Summary deleted successfully",
 	})
+}
+
+func (c *PDFController) GetRevisions(ctx *fiber.Ctx) error {
+	var params validation.SummaryIDParam
+
+	if err := ctx.ParamsParser(&params); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid summary ID")
+	}
+	if err := validation.Validator().Struct(params); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	revisions, err := c.SummaryService.GetRevisions(ctx.Context(), params.ID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fiber.NewError(fiber.StatusNotFound, "Summary not found")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	return ctx.JSON(fiber.Map{
+		"data": revisions,
+	})
+}
+
+func (c *PDFController) GetRevision(ctx *fiber.Ctx) error {
+	var params validation.RevisionParam
+
+	if err := ctx.ParamsParser(&params); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid revision")
+	}
+	if err := validation.Validator().Struct(params); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	revision, err := c.SummaryService.GetRevision(ctx.Context(), params.ID, params.Revision)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fiber.NewError(fiber.StatusNotFound, "Revision not found")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	return ctx.JSON(fiber.Map{
+		"data": revision,
+	})
+}
+
+func (c *PDFController) RestoreRevision(ctx *fiber.Ctx) error {
+	var params validation.RevisionParam
+
+	if err := ctx.ParamsParser(&params); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid revision")
+	}
+	if err := validation.Validator().Struct(params); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	summary, err := c.SummaryService.RestoreRevision(ctx.Context(), params.ID, params.Revision, middleware.Principal(ctx))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fiber.NewError(fiber.StatusNotFound, "Revision not found")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	return ctx.JSON(fiber.Map{
+		"message": "Summary restored successfully",
+		"data":    summary,
+	})
+}
+
+func (c *PDFController) DiffRevisions(ctx *fiber.Ctx) error {
+	var params validation.SummaryIDParam
+	var query validation.DiffQuery
+
+	if err := ctx.ParamsParser(&params); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid summary ID")
+	}
+	if err := ctx.QueryParser(&query); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid query parameters")
+	}
+
+	if err := validation.Validator().Struct(params); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	if err := validation.Validator().Struct(query); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	diff, err := c.SummaryService.DiffRevisions(ctx.Context(), params.ID, query.From, query.To, query.ContextLines())
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fiber.NewError(fiber.StatusNotFound, "Revision not found")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	return ctx.JSON(fiber.Map{
+		"data": diff,
+	})
+}
+
+func (c *PDFController) DiffSummaries(ctx *fiber.Ctx) error {
+	var params validation.SummaryPairParam
+	var query validation.DiffQuery
+
+	if err := ctx.ParamsParser(&params); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid summary ID")
+	}
+	if err := ctx.QueryParser(&query); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid query parameters")
+	}
+
+	if err := validation.Validator().Struct(params); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	if err := validation.Validator().Struct(query); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	diff, err := c.SummaryService.DiffSummaries(ctx.Context(), params.ID, params.OtherID, query.ContextLines())
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return fiber.NewError(fiber.StatusNotFound, "Summary not found")
+		case errors.Is(err, service.ErrDifferentPDFs):
+			return fiber.NewError(fiber.StatusUnprocessableEntity, err.Error())
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	return ctx.JSON(fiber.Map{
+		"data": diff,
+	})
 }
\ No newline at end of file
@@ -0,0 +1,47 @@
+package controller
+
+import (
+	"app/src/model"
+	"app/src/service"
+	"app/src/validation"
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type SearchController struct {
+	SearchService service.SearchService
+}
+
+func NewSearchController(searchService service.SearchService) *SearchController {
+	return &SearchController{
+		SearchService: searchService,
+	}
+}
+
+func (c *SearchController) Search(ctx *fiber.Ctx) error {
+	var params validation.SearchQuery
+
+	if err := ctx.QueryParser(&params); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid query parameters")
+	}
+
+	params.SetDefaults()
+
+	if err := validation.Validator().Struct(params); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	results, meta, err := c.SearchService.Search(ctx.Context(), params)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidSearchPDF) {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	return ctx.JSON(model.PaginatedResponse{
+		Data: results,
+		Meta: *meta,
+	})
+}
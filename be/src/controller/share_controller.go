@@ -0,0 +1,200 @@
+package controller
+
+import (
+	"app/src/middleware"
+	"app/src/service"
+	"app/src/utils"
+	"app/src/validation"
+	"bytes"
+	"errors"
+	"html/template"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+var sharePageTemplate = template.Must(template.New("share").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="robots" content="noindex, nofollow">
+<title>{{.Title}}</title>
+<style>
+body { font-family: system-ui, sans-serif; line-height: 1.6; max-width: 760px; margin: 2rem auto; padding: 0 1rem; color: #1f2937; }
+header { border-bottom: 1px solid #e5e7eb; margin-bottom: 1.5rem; }
+header p { color: #6b7280; font-size: 0.9rem; }
+pre { background: #f3f4f6; padding: 1rem; overflow-x: auto; }
+blockquote { border-left: 3px solid #d1d5db; margin: 0; padding-left: 1rem; color: #4b5563; }
+</style>
+</head>
+<body>
+<header>
+<h1>{{.Title}}</h1>
+<p>Summary ({{.Language}}, {{.Style}}) &middot; {{.UpdatedAt}}</p>
+</header>
+<main>
+{{.Content}}
+</main>
+</body>
+</html>
+`))
+
+type ShareController struct {
+	ShareService service.ShareService
+}
+
+func NewShareController(shareService service.ShareService) *ShareController {
+	return &ShareController{
+		ShareService: shareService,
+	}
+}
+
+func (c *ShareController) CreateShare(ctx *fiber.Ctx) error {
+	var params validation.SummaryIDParam
+	var payload validation.CreateShare
+
+	if err := ctx.ParamsParser(&params); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid summary ID")
+	}
+	if len(ctx.Body()) > 0 {
+		if err := ctx.BodyParser(&payload); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "Invalid request payload")
+		}
+	}
+
+	if err := validation.Validator().Struct(params); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	if err := validation.Validator().Struct(payload); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	share, err := c.ShareService.Create(ctx.Context(), params.ID, payload, middleware.Principal(ctx))
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return fiber.NewError(fiber.StatusNotFound, "Summary not found")
+		case errors.Is(err, service.ErrSummaryNotCompleted):
+			return fiber.NewError(fiber.StatusConflict, err.Error())
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Share link created successfully",
+		"data":    share,
+	})
+}
+
+func (c *ShareController) GetShares(ctx *fiber.Ctx) error {
+	var params validation.PDFIDParam
+
+	if err := ctx.ParamsParser(&params); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid PDF ID")
+	}
+	if err := validation.Validator().Struct(params); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	shares, err := c.ShareService.GetActiveByPDF(ctx.Context(), params.ID)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	return ctx.JSON(fiber.Map{
+		"data": shares,
+	})
+}
+
+func (c *ShareController) RevokeShare(ctx *fiber.Ctx) error {
+	var params validation.ShareIDParam
+
+	if err := ctx.ParamsParser(&params); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid share ID")
+	}
+	if err := validation.Validator().Struct(params); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	if err := c.ShareService.Revoke(ctx.Context(), params.ID); err != nil {
+		if errors.Is(err, service.ErrShareNotFound) {
+			return fiber.NewError(fiber.StatusNotFound, "Share not found")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	return ctx.JSON(fiber.Map{
+		"message": "Share revoked successfully",
+	})
+}
+
+// ViewShare is public. The password of protected shares is read from the
+// X-Share-Password header or, for POST requests, from the body.
+func (c *ShareController) ViewShare(ctx *fiber.Ctx) error {
+	var params validation.ShareTokenParam
+	var query validation.ViewShare
+	var payload validation.UnlockShare
+
+	if err := ctx.ParamsParser(&params); err != nil {
+		return fiber.NewError(fiber.StatusNotFound, "Share not found")
+	}
+	if err := ctx.QueryParser(&query); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid query parameters")
+	}
+	if ctx.Method() == fiber.MethodPost && len(ctx.Body()) > 0 {
+		if err := ctx.BodyParser(&payload); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "Invalid request payload")
+		}
+	}
+	if payload.Password == "" {
+		payload.Password = ctx.Get("X-Share-Password")
+	}
+
+	if err := validation.Validator().Struct(params); err != nil {
+		return fiber.NewError(fiber.StatusNotFound, "Share not found")
+	}
+	if err := validation.Validator().Struct(query); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	if err := validation.Validator().Struct(payload); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	summary, pdf, err := c.ShareService.View(ctx.Context(), params.Token, payload.Password)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrShareNotFound):
+			return fiber.NewError(fiber.StatusNotFound, "Share not found")
+		case errors.Is(err, service.ErrShareExpired):
+			return fiber.NewError(fiber.StatusGone, err.Error())
+		case errors.Is(err, service.ErrSharePasswordRequired), errors.Is(err, service.ErrInvalidSharePassword):
+			return fiber.NewError(fiber.StatusUnauthorized, err.Error())
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	ctx.Set(fiber.HeaderCacheControl, "private, no-store")
+	ctx.Set("X-Robots-Tag", "noindex, nofollow")
+
+	if query.Format == "md" {
+		ctx.Set(fiber.HeaderContentType, "text/markdown; charset=utf-8")
+		return ctx.SendString(summary.Content)
+	}
+
+	var page bytes.Buffer
+	err = sharePageTemplate.Execute(&page, map[string]interface{}{
+		"Title":     pdf.OriginalName,
+		"Language":  summary.Language,
+		"Style":     summary.Style,
+		"UpdatedAt": summary.UpdatedAt.Format("2 January 2006"),
+		"Content":   template.HTML(utils.RenderMarkdown(summary.Content)),
+	})
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to render summary")
+	}
+
+	ctx.Set(fiber.HeaderContentType, fiber.MIMETextHTMLCharsetUTF8)
+	ctx.Set(fiber.HeaderContentSecurityPolicy, "default-src 'none'; style-src 'unsafe-inline'")
+	return ctx.Send(page.Bytes())
+}
@@ -0,0 +1,6 @@
+UPDATE pdf_documents SET status = 'failed' WHERE status = 'locked';
+
+ALTER TABLE pdf_documents DROP CONSTRAINT IF EXISTS pdfs_status_check;
+
+ALTER TABLE pdf_documents
+    ADD CONSTRAINT pdfs_status_check CHECK ( status IN ('pending', 'processing', 'completed', 'failed'));
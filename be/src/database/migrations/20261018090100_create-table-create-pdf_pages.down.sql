@@ -0,0 +1 @@
+DROP TABLE IF EXISTS pdf_pages;
\ No newline at end of file
@@ -0,0 +1,12 @@
+CREATE TABLE pdf_pages (
+    id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    pdf_id      UUID         NOT NULL,
+    page_number INT          NOT NULL,
+    text        TEXT,
+    created_at  TIMESTAMP    DEFAULT NOW(),
+
+    CONSTRAINT fk_pdf_pages_pdf FOREIGN KEY (pdf_id) REFERENCES pdf_documents(id) ON DELETE CASCADE,
+    CONSTRAINT uq_pdf_pages_page UNIQUE (pdf_id, page_number)
+);
+
+CREATE INDEX IF NOT EXISTS idx_pdf_pages_pdf_id ON pdf_pages(pdf_id);
@@ -0,0 +1 @@
+ALTER TABLE pdf_documents DROP COLUMN IF EXISTS status_reason;
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE pdf_documents ADD COLUMN IF NOT EXISTS status_reason TEXT;
@@ -0,0 +1,15 @@
+DROP INDEX IF EXISTS idx_pdfs_page_count;
+DROP INDEX IF EXISTS idx_pdfs_author;
+DROP INDEX IF EXISTS idx_pdfs_pdf_created_at;
+
+ALTER TABLE pdf_documents
+    DROP COLUMN IF EXISTS page_count,
+    DROP COLUMN IF EXISTS pdf_version,
+    DROP COLUMN IF EXISTS title,
+    DROP COLUMN IF EXISTS author,
+    DROP COLUMN IF EXISTS subject,
+    DROP COLUMN IF EXISTS keywords,
+    DROP COLUMN IF EXISTS producer,
+    DROP COLUMN IF EXISTS pdf_created_at,
+    DROP COLUMN IF EXISTS pdf_modified_at,
+    DROP COLUMN IF EXISTS has_text_layer;
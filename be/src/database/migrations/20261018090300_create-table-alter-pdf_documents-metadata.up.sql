@@ -0,0 +1,15 @@
+ALTER TABLE pdf_documents
+    ADD COLUMN IF NOT EXISTS page_count      INT,
+    ADD COLUMN IF NOT EXISTS pdf_version     VARCHAR(10),
+    ADD COLUMN IF NOT EXISTS title           TEXT,
+    ADD COLUMN IF NOT EXISTS author          TEXT,
+    ADD COLUMN IF NOT EXISTS subject         TEXT,
+    ADD COLUMN IF NOT EXISTS keywords        TEXT,
+    ADD COLUMN IF NOT EXISTS producer        TEXT,
+    ADD COLUMN IF NOT EXISTS pdf_created_at  TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS pdf_modified_at TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS has_text_layer  BOOLEAN DEFAULT FALSE;
+
+CREATE INDEX IF NOT EXISTS idx_pdfs_page_count ON pdf_documents(page_count);
+CREATE INDEX IF NOT EXISTS idx_pdfs_author ON pdf_documents(author);
+CREATE INDEX IF NOT EXISTS idx_pdfs_pdf_created_at ON pdf_documents(pdf_created_at);
@@ -0,0 +1,4 @@
+ALTER TABLE pdf_pages
+    DROP COLUMN IF EXISTS has_text_layer,
+    DROP COLUMN IF EXISTS ocr_text,
+    DROP COLUMN IF EXISTS ocr_confidence;
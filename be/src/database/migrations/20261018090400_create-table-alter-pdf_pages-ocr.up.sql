@@ -0,0 +1,4 @@
+ALTER TABLE pdf_pages
+    ADD COLUMN IF NOT EXISTS has_text_layer BOOLEAN DEFAULT TRUE,
+    ADD COLUMN IF NOT EXISTS ocr_text       TEXT,
+    ADD COLUMN IF NOT EXISTS ocr_confidence REAL;
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS summary_shares;
\ No newline at end of file
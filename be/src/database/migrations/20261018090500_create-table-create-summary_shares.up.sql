@@ -0,0 +1,20 @@
+CREATE TABLE summary_shares (
+    id             UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    summary_id     UUID         NOT NULL,
+    pdf_id         UUID         NOT NULL,
+    token_hash     VARCHAR(64)  NOT NULL,
+    password_hash  VARCHAR(100),
+    expires_at     TIMESTAMP,
+    view_count     INT          NOT NULL DEFAULT 0,
+    last_viewed_at TIMESTAMP,
+    created_by     VARCHAR(100),
+    revoked_at     TIMESTAMP,
+    created_at     TIMESTAMP    DEFAULT NOW(),
+
+    CONSTRAINT fk_summary_shares_summary FOREIGN KEY (summary_id) REFERENCES summaries(id) ON DELETE CASCADE,
+    CONSTRAINT fk_summary_shares_pdf FOREIGN KEY (pdf_id) REFERENCES pdf_documents(id) ON DELETE CASCADE,
+    CONSTRAINT uq_summary_shares_token_hash UNIQUE (token_hash)
+);
+
+CREATE INDEX IF NOT EXISTS idx_summary_shares_summary_id ON summary_shares(summary_id);
+CREATE INDEX IF NOT EXISTS idx_summary_shares_pdf_id ON summary_shares(pdf_id);
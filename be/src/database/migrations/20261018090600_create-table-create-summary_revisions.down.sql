@@ -0,0 +1 @@
+DROP TABLE IF EXISTS summary_revisions;
\ No newline at end of file
@@ -0,0 +1,22 @@
+CREATE TABLE summary_revisions (
+    id              UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    summary_id      UUID         NOT NULL,
+    revision_number INT          NOT NULL,
+    content         TEXT         NOT NULL,
+    source          VARCHAR(20)  NOT NULL,
+    author          VARCHAR(100),
+    restored_from   INT,
+    created_at      TIMESTAMP    DEFAULT NOW(),
+
+    CONSTRAINT summary_revisions_source_check CHECK (source IN ('ai', 'manual', 'restore')),
+    CONSTRAINT fk_summary_revisions_summary FOREIGN KEY (summary_id) REFERENCES summaries(id) ON DELETE CASCADE,
+    CONSTRAINT uq_summary_revisions_number UNIQUE (summary_id, revision_number)
+);
+
+CREATE INDEX IF NOT EXISTS idx_summary_revisions_summary_id ON summary_revisions(summary_id);
+
+-- Keep the current content of existing summaries as their first revision
+INSERT INTO summary_revisions (summary_id, revision_number, content, source, author, created_at)
+SELECT id, 1, content, CASE WHEN is_edited THEN 'manual' ELSE 'ai' END, NULL, updated_at
+FROM summaries
+WHERE content IS NOT NULL AND content <> '';
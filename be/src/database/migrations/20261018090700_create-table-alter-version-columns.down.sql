@@ -0,0 +1,3 @@
+ALTER TABLE summaries DROP COLUMN IF EXISTS version;
+
+ALTER TABLE pdf_documents DROP COLUMN IF EXISTS version;
\ No newline at end of file
@@ -0,0 +1,5 @@
+ALTER TABLE summaries
+    ADD COLUMN IF NOT EXISTS version INT NOT NULL DEFAULT 1;
+
+ALTER TABLE pdf_documents
+    ADD COLUMN IF NOT EXISTS version INT NOT NULL DEFAULT 1;
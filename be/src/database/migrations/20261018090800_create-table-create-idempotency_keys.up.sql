@@ -0,0 +1,17 @@
+CREATE TABLE idempotency_keys (
+    id              UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    principal       VARCHAR(100) NOT NULL,
+    key             VARCHAR(255) NOT NULL,
+    fingerprint     VARCHAR(64)  NOT NULL,
+    status          VARCHAR(20)  NOT NULL DEFAULT 'processing',
+    response_status INT,
+    response_type   VARCHAR(255),
+    response_body   BYTEA,
+    created_at      TIMESTAMP    DEFAULT NOW(),
+    expires_at      TIMESTAMP    NOT NULL,
+
+    CONSTRAINT idempotency_keys_status_check CHECK (status IN ('processing', 'completed')),
+    CONSTRAINT uq_idempotency_keys_principal_key UNIQUE (principal, key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_expires_at ON idempotency_keys(expires_at);
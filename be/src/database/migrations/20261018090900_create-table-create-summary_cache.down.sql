@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS summary_cache;
+
+DROP INDEX IF EXISTS idx_pdfs_content_hash;
+
+ALTER TABLE pdf_documents DROP COLUMN IF EXISTS content_hash;
\ No newline at end of file
@@ -0,0 +1,20 @@
+ALTER TABLE pdf_documents
+    ADD COLUMN IF NOT EXISTS content_hash VARCHAR(64);
+
+CREATE INDEX IF NOT EXISTS idx_pdfs_content_hash ON pdf_documents(content_hash);
+
+CREATE TABLE summary_cache (
+    id                UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    content_hash      VARCHAR(64)  NOT NULL,
+    language          VARCHAR(10)  NOT NULL,
+    style             VARCHAR(20)  NOT NULL,
+    model             VARCHAR(100) NOT NULL,
+    prompt_version    VARCHAR(20)  NOT NULL,
+    content           TEXT         NOT NULL,
+    source_summary_id UUID,
+    hit_count         INT          NOT NULL DEFAULT 0,
+    last_hit_at       TIMESTAMP,
+    created_at        TIMESTAMP    DEFAULT NOW(),
+
+    CONSTRAINT uq_summary_cache_key UNIQUE (content_hash, language, style, model, prompt_version)
+);
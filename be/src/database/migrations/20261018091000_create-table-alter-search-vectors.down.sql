@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_summaries_search_vector;
+DROP INDEX IF EXISTS idx_pdf_pages_search_vector;
+DROP INDEX IF EXISTS idx_pdfs_search_vector;
+
+ALTER TABLE summaries DROP COLUMN IF EXISTS search_vector;
+ALTER TABLE pdf_pages DROP COLUMN IF EXISTS search_vector;
+ALTER TABLE pdf_documents DROP COLUMN IF EXISTS search_vector;
\ No newline at end of file
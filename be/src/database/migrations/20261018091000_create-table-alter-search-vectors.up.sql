@@ -0,0 +1,22 @@
+-- The 'simple' configuration does not stem, so it behaves the same for every
+-- summary language (EN, ID, CN, JP, KR)
+ALTER TABLE pdf_documents
+    ADD COLUMN IF NOT EXISTS search_vector TSVECTOR GENERATED ALWAYS AS (
+        setweight(to_tsvector('simple', coalesce(title, '')), 'A') ||
+        setweight(to_tsvector('simple', coalesce(original_name, '')), 'A') ||
+        setweight(to_tsvector('simple', coalesce(subject, '') || ' ' || coalesce(keywords, '') || ' ' || coalesce(author, '')), 'B')
+    ) STORED;
+
+ALTER TABLE pdf_pages
+    ADD COLUMN IF NOT EXISTS search_vector TSVECTOR GENERATED ALWAYS AS (
+        to_tsvector('simple', coalesce(text, '') || ' ' || coalesce(ocr_text, ''))
+    ) STORED;
+
+ALTER TABLE summaries
+    ADD COLUMN IF NOT EXISTS search_vector TSVECTOR GENERATED ALWAYS AS (
+        to_tsvector('simple', coalesce(content, ''))
+    ) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_pdfs_search_vector ON pdf_documents USING GIN(search_vector);
+CREATE INDEX IF NOT EXISTS idx_pdf_pages_search_vector ON pdf_pages USING GIN(search_vector);
+CREATE INDEX IF NOT EXISTS idx_summaries_search_vector ON summaries USING GIN(search_vector);
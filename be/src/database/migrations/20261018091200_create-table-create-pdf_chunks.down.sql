@@ -0,0 +1 @@
+DROP TABLE IF EXISTS pdf_chunks;
\ No newline at end of file
@@ -0,0 +1,15 @@
+CREATE TABLE pdf_chunks (
+    id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    pdf_id      UUID         NOT NULL,
+    page_number INT          NOT NULL,
+    chunk_index INT          NOT NULL,
+    content     TEXT         NOT NULL,
+    model       VARCHAR(100) NOT NULL,
+    embedding   BYTEA        NOT NULL,
+    created_at  TIMESTAMP    DEFAULT NOW(),
+
+    CONSTRAINT fk_pdf_chunks_pdf FOREIGN KEY (pdf_id) REFERENCES pdf_documents(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_pdf_chunks_pdf_id ON pdf_chunks(pdf_id);
+CREATE INDEX IF NOT EXISTS idx_pdf_chunks_model ON pdf_chunks(model);
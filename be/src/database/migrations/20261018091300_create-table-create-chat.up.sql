@@ -0,0 +1,28 @@
+CREATE TABLE chat_conversations (
+    id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    pdf_id      UUID         NOT NULL,
+    title       VARCHAR(255) NOT NULL,
+    language    VARCHAR(10)  NOT NULL DEFAULT 'EN',
+    created_by  VARCHAR(100),
+    created_at  TIMESTAMP    DEFAULT NOW(),
+    updated_at  TIMESTAMP    DEFAULT NOW(),
+
+    CONSTRAINT fk_chat_conversations_pdf FOREIGN KEY (pdf_id) REFERENCES pdf_documents(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_chat_conversations_pdf_id ON chat_conversations(pdf_id);
+
+CREATE TABLE chat_messages (
+    id              UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    conversation_id UUID         NOT NULL,
+    role            VARCHAR(20)  NOT NULL,
+    content         TEXT         NOT NULL,
+    citations       JSONB,
+    model           VARCHAR(100),
+    created_at      TIMESTAMP    DEFAULT NOW(),
+
+    CONSTRAINT chat_messages_role_check CHECK (role IN ('user', 'assistant')),
+    CONSTRAINT fk_chat_messages_conversation FOREIGN KEY (conversation_id) REFERENCES chat_conversations(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_chat_messages_conversation_id ON chat_messages(conversation_id, created_at);
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS summary_sources;
+
+ALTER TABLE summaries DROP CONSTRAINT IF EXISTS summaries_mode_check;
+ALTER TABLE summaries DROP CONSTRAINT IF EXISTS summaries_kind_check;
+ALTER TABLE summaries DROP COLUMN IF EXISTS mode;
+ALTER TABLE summaries DROP COLUMN IF EXISTS kind;
\ No newline at end of file
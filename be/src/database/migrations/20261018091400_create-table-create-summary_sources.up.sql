@@ -0,0 +1,18 @@
+-- Synthesis summaries cover several PDFs. pdf_id keeps the first source so
+-- existing lookups keep working, summary_sources links all of them.
+ALTER TABLE summaries ADD COLUMN IF NOT EXISTS kind VARCHAR(20) NOT NULL DEFAULT 'single';
+ALTER TABLE summaries ADD COLUMN IF NOT EXISTS mode VARCHAR(20);
+ALTER TABLE summaries ADD CONSTRAINT summaries_kind_check CHECK (kind IN ('single', 'synthesis'));
+ALTER TABLE summaries ADD CONSTRAINT summaries_mode_check CHECK (mode IS NULL OR mode IN ('compare', 'merge', 'timeline'));
+
+CREATE TABLE summary_sources (
+    summary_id UUID NOT NULL,
+    pdf_id     UUID NOT NULL,
+    position   INT  NOT NULL,
+
+    PRIMARY KEY (summary_id, pdf_id),
+    CONSTRAINT fk_summary_sources_summary FOREIGN KEY (summary_id) REFERENCES summaries(id) ON DELETE CASCADE,
+    CONSTRAINT fk_summary_sources_pdf FOREIGN KEY (pdf_id) REFERENCES pdf_documents(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_summary_sources_pdf_id ON summary_sources(pdf_id);
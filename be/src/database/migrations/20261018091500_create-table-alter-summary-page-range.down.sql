@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_summaries_page_from;
+
+ALTER TABLE summaries DROP CONSTRAINT IF EXISTS summaries_page_range_check;
+ALTER TABLE summaries DROP COLUMN IF EXISTS page_to;
+ALTER TABLE summaries DROP COLUMN IF EXISTS page_from;
\ No newline at end of file
@@ -0,0 +1,8 @@
+-- A NULL range means the summary covers the whole document
+ALTER TABLE summaries ADD COLUMN IF NOT EXISTS page_from INT;
+ALTER TABLE summaries ADD COLUMN IF NOT EXISTS page_to INT;
+ALTER TABLE summaries ADD CONSTRAINT summaries_page_range_check CHECK (
+    (page_from IS NULL AND page_to IS NULL) OR (page_from >= 1 AND page_to >= page_from)
+);
+
+CREATE INDEX IF NOT EXISTS idx_summaries_page_from ON summaries(pdf_id, page_from);
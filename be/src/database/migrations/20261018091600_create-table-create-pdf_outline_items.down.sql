@@ -0,0 +1,7 @@
+-- Chapter summaries cover the whole document and remain as plain summaries
+UPDATE summaries SET mode = NULL WHERE mode = 'chapters';
+ALTER TABLE summaries DROP CONSTRAINT IF EXISTS summaries_mode_check;
+ALTER TABLE summaries ADD CONSTRAINT summaries_mode_check CHECK (mode IS NULL OR mode IN ('compare', 'merge', 'timeline'));
+ALTER TABLE summaries DROP COLUMN IF EXISTS outline_item_id;
+
+DROP TABLE IF EXISTS pdf_outline_items;
\ No newline at end of file
@@ -0,0 +1,20 @@
+CREATE TABLE pdf_outline_items (
+    id         UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    pdf_id     UUID  NOT NULL,
+    parent_id  UUID,
+    position   INT   NOT NULL,
+    level      INT   NOT NULL,
+    title      TEXT  NOT NULL,
+    page_from  INT,
+    page_to    INT,
+
+    CONSTRAINT fk_pdf_outline_items_pdf FOREIGN KEY (pdf_id) REFERENCES pdf_documents(id) ON DELETE CASCADE,
+    CONSTRAINT fk_pdf_outline_items_parent FOREIGN KEY (parent_id) REFERENCES pdf_outline_items(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_pdf_outline_items_pdf_id ON pdf_outline_items(pdf_id, position);
+
+-- Summaries can be scoped to an outline item and generated chapter by chapter
+ALTER TABLE summaries ADD COLUMN IF NOT EXISTS outline_item_id UUID;
+ALTER TABLE summaries DROP CONSTRAINT IF EXISTS summaries_mode_check;
+ALTER TABLE summaries ADD CONSTRAINT summaries_mode_check CHECK (mode IS NULL OR mode IN ('compare', 'merge', 'timeline', 'chapters'));
@@ -0,0 +1 @@
+ALTER TABLE summaries DROP COLUMN IF EXISTS structured;
\ No newline at end of file
@@ -0,0 +1,3 @@
+-- Purpose, key points, findings, conclusions, keywords and takeaways of the
+-- generated content, see model.StructuredSummary
+ALTER TABLE summaries ADD COLUMN IF NOT EXISTS structured JSONB;
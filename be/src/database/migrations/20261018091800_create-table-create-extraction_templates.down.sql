@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS extraction_results;
+DROP TABLE IF EXISTS extraction_templates;
\ No newline at end of file
@@ -0,0 +1,36 @@
+CREATE TABLE extraction_templates (
+    id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name        VARCHAR(100) NOT NULL,
+    description TEXT,
+    schema      JSONB        NOT NULL,
+    version     INT          NOT NULL DEFAULT 1,
+    created_by  VARCHAR(100),
+    created_at  TIMESTAMP    DEFAULT NOW(),
+    updated_at  TIMESTAMP    DEFAULT NOW(),
+    deleted_at  TIMESTAMP
+);
+
+-- Templates are referenced by name in ?template=, deleted ones free their name
+CREATE UNIQUE INDEX IF NOT EXISTS idx_extraction_templates_name ON extraction_templates(lower(name)) WHERE deleted_at IS NULL;
+
+CREATE TABLE extraction_results (
+    id               UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    template_id      UUID         NOT NULL,
+    template_version INT          NOT NULL,
+    pdf_id           UUID         NOT NULL,
+    status           VARCHAR(20)  NOT NULL DEFAULT 'processing',
+    data             JSONB,
+    errors           JSONB,
+    model            VARCHAR(100),
+    created_by       VARCHAR(100),
+    created_at       TIMESTAMP    DEFAULT NOW(),
+    updated_at       TIMESTAMP    DEFAULT NOW(),
+
+    CONSTRAINT extraction_results_status_check CHECK (status IN ('processing', 'completed', 'invalid', 'failed')),
+    CONSTRAINT fk_extraction_results_template FOREIGN KEY (template_id) REFERENCES extraction_templates(id) ON DELETE CASCADE,
+    CONSTRAINT fk_extraction_results_pdf FOREIGN KEY (pdf_id) REFERENCES pdf_documents(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_extraction_results_template_pdf ON extraction_results(template_id, pdf_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_extraction_results_pdf_id ON extraction_results(pdf_id);
+CREATE INDEX IF NOT EXISTS idx_extraction_results_data ON extraction_results USING GIN (data jsonb_path_ops);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS pdf_entities;
+DROP TABLE IF EXISTS entities;
\ No newline at end of file
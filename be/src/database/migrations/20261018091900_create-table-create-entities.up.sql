@@ -0,0 +1,33 @@
+CREATE TABLE entities (
+    id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    type        VARCHAR(20)  NOT NULL,
+    name        VARCHAR(255) NOT NULL,
+    normalized  VARCHAR(255) NOT NULL,
+    created_at  TIMESTAMP    DEFAULT NOW(),
+    updated_at  TIMESTAMP    DEFAULT NOW(),
+
+    CONSTRAINT entities_type_check CHECK (type IN ('keyword', 'person', 'organization', 'location', 'date', 'amount'))
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_entities_type_normalized ON entities(type, normalized);
+CREATE INDEX IF NOT EXISTS idx_entities_normalized ON entities(normalized);
+
+-- One row per PDF and entity, replaced whenever the PDF is indexed again
+CREATE TABLE pdf_entities (
+    pdf_id      UUID         NOT NULL,
+    entity_id   UUID         NOT NULL,
+    mentions    INT          NOT NULL DEFAULT 1,
+    source      VARCHAR(20)  NOT NULL,
+    method      VARCHAR(20)  NOT NULL,
+    summary_id  UUID,
+    created_at  TIMESTAMP    DEFAULT NOW(),
+
+    PRIMARY KEY (pdf_id, entity_id),
+    CONSTRAINT pdf_entities_source_check CHECK (source IN ('summary', 'text')),
+    CONSTRAINT pdf_entities_method_check CHECK (method IN ('ai', 'rules')),
+    CONSTRAINT fk_pdf_entities_pdf FOREIGN KEY (pdf_id) REFERENCES pdf_documents(id) ON DELETE CASCADE,
+    CONSTRAINT fk_pdf_entities_entity FOREIGN KEY (entity_id) REFERENCES entities(id) ON DELETE CASCADE,
+    CONSTRAINT fk_pdf_entities_summary FOREIGN KEY (summary_id) REFERENCES summaries(id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_pdf_entities_entity_id ON pdf_entities(entity_id);
@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS idx_pdf_documents_category;
+
+ALTER TABLE pdf_documents DROP CONSTRAINT IF EXISTS fk_pdf_documents_category;
+ALTER TABLE pdf_documents DROP CONSTRAINT IF EXISTS pdf_documents_category_source_check;
+ALTER TABLE pdf_documents DROP COLUMN IF EXISTS classified_at;
+ALTER TABLE pdf_documents DROP COLUMN IF EXISTS category_source;
+ALTER TABLE pdf_documents DROP COLUMN IF EXISTS category_confidence;
+ALTER TABLE pdf_documents DROP COLUMN IF EXISTS category;
+
+DROP TABLE IF EXISTS classification_rules;
\ No newline at end of file
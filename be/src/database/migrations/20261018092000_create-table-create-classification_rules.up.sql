@@ -0,0 +1,37 @@
+-- The taxonomy documents are classified into. Keywords are maintained by
+-- admins, learned_keywords are weights computed from labeled documents.
+CREATE TABLE classification_rules (
+    category         VARCHAR(50)  PRIMARY KEY,
+    label            VARCHAR(100) NOT NULL,
+    description      TEXT,
+    keywords         JSONB        NOT NULL DEFAULT '[]',
+    learned_keywords JSONB,
+    is_active        BOOLEAN      NOT NULL DEFAULT TRUE,
+    trained_at       TIMESTAMP,
+    created_at       TIMESTAMP    DEFAULT NOW(),
+    updated_at       TIMESTAMP    DEFAULT NOW(),
+
+    CONSTRAINT classification_rules_category_check CHECK (category ~ '^[a-z0-9_]+$')
+);
+
+INSERT INTO classification_rules (category, label, description, keywords) VALUES
+    ('contract', 'Contract', 'Agreements between parties with obligations and terms',
+        '["agreement", "contract", "party", "parties", "hereby", "obligations", "termination", "governing law", "effective date", "indemnify", "warranty", "confidentiality", "perjanjian", "kontrak", "pihak pertama", "pihak kedua"]'),
+    ('invoice', 'Invoice', 'Bills and payment requests for goods or services',
+        '["invoice", "bill to", "amount due", "due date", "subtotal", "tax", "vat", "total", "payment terms", "invoice number", "quantity", "unit price", "faktur", "tagihan", "jumlah"]'),
+    ('research_paper', 'Research paper', 'Scientific and academic publications',
+        '["abstract", "introduction", "methodology", "methods", "results", "discussion", "conclusion", "references", "et al", "hypothesis", "experiment", "dataset", "doi", "journal", "penelitian"]'),
+    ('policy', 'Policy', 'Internal or public policies, procedures and guidelines',
+        '["policy", "procedure", "guideline", "compliance", "scope", "responsibilities", "must", "shall", "employees", "prohibited", "violation", "kebijakan", "prosedur", "peraturan"]'),
+    ('report', 'Report', 'Periodic, financial or project reports',
+        '["report", "annual report", "quarter", "fiscal year", "revenue", "performance", "summary", "key findings", "recommendations", "outlook", "laporan", "kinerja"]');
+
+ALTER TABLE pdf_documents ADD COLUMN IF NOT EXISTS category VARCHAR(50);
+ALTER TABLE pdf_documents ADD COLUMN IF NOT EXISTS category_confidence REAL;
+ALTER TABLE pdf_documents ADD COLUMN IF NOT EXISTS category_source VARCHAR(20);
+ALTER TABLE pdf_documents ADD COLUMN IF NOT EXISTS classified_at TIMESTAMP;
+ALTER TABLE pdf_documents ADD CONSTRAINT pdf_documents_category_source_check CHECK (category_source IN ('ai', 'rules', 'manual'));
+ALTER TABLE pdf_documents ADD CONSTRAINT fk_pdf_documents_category FOREIGN KEY (category)
+    REFERENCES classification_rules(category) ON UPDATE CASCADE ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_pdf_documents_category ON pdf_documents(category);
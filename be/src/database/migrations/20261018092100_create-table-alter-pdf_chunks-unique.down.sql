@@ -0,0 +1 @@
+ALTER TABLE pdf_chunks DROP CONSTRAINT IF EXISTS uq_pdf_chunks_position;
\ No newline at end of file
@@ -0,0 +1,10 @@
+-- Interleaved indexing runs could store a page twice, keep the newest copy
+DELETE FROM pdf_chunks a
+USING pdf_chunks b
+WHERE a.pdf_id = b.pdf_id
+  AND a.model = b.model
+  AND a.page_number = b.page_number
+  AND a.chunk_index = b.chunk_index
+  AND (a.created_at, a.id) < (b.created_at, b.id);
+
+ALTER TABLE pdf_chunks ADD CONSTRAINT uq_pdf_chunks_position UNIQUE (pdf_id, model, page_number, chunk_index);
@@ -0,0 +1 @@
+ALTER TABLE summary_cache DROP COLUMN IF EXISTS structured;
\ No newline at end of file
@@ -0,0 +1,3 @@
+-- The structured form of the cached content, so cache hits never take it
+-- from a summary that was edited since
+ALTER TABLE summary_cache ADD COLUMN IF NOT EXISTS structured JSONB;
@@ -54,12 +54,9 @@ func setupFiberApp() *fiber.App {
 	app.Use(middleware.LoggerConfig())
 	app.Use(helmet.New(helmet.Config{ XFrameOptions: "ALLOWALL"}))
 	app.Use(compress.New())
-	app.Use(cors.New())
+	app.Use(cors.New(cors.Config{ExposeHeaders: "ETag, Idempotent-Replayed"}))
 	app.Use(middleware.RecoverConfig())
 
-	// Serve static files on folder uploads
-	app.Static("/uploads", "./uploads")
-
 	return app
 }
 
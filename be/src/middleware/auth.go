@@ -0,0 +1,100 @@
+package middleware
+
+import (
+	"app/src/config"
+	"app/src/utils"
+	"crypto/subtle"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const principalKey = "principal"
+
+// Auth requires a valid API key in the Authorization header. Without configured
+// keys, requests are allowed outside production as "anonymous".
+func Auth() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		principal, ok := authenticate(c)
+		if !ok {
+			return fiber.NewError(fiber.StatusUnauthorized, "Please authenticate")
+		}
+
+		c.Locals(principalKey, principal)
+		return c.Next()
+	}
+}
+
+// Admin requires an API key whose name is listed in ADMIN_PRINCIPALS. Like
+// Auth, it is open outside production while no API keys are configured.
+func Admin() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		principal, ok := authenticate(c)
+		if !ok {
+			return fiber.NewError(fiber.StatusUnauthorized, "Please authenticate")
+		}
+		if len(config.APIKeys) > 0 && !slices.Contains(config.AdminPrincipals, principal) {
+			return fiber.NewError(fiber.StatusForbidden, "Admin access required")
+		}
+
+		c.Locals(principalKey, principal)
+		return c.Next()
+	}
+}
+
+// IsAdmin reports whether the caller is listed in ADMIN_PRINCIPALS
+func IsAdmin(c *fiber.Ctx) bool {
+	return slices.Contains(config.AdminPrincipals, Principal(c))
+}
+
+// SignedURLOrAuth accepts a valid, unexpired signed URL or falls back to Auth
+func SignedURLOrAuth() fiber.Handler {
+	auth := Auth()
+
+	return func(c *fiber.Ctx) error {
+		signature := c.Query("signature")
+		if signature == "" {
+			return auth(c)
+		}
+
+		expires, err := strconv.ParseInt(c.Query("expires"), 10, 64)
+		if err != nil || !utils.VerifyPath(config.SigningSecret, c.Path(), expires, signature) {
+			return fiber.NewError(fiber.StatusForbidden, "Invalid or expired signature")
+		}
+
+		c.Locals(principalKey, "signed-url")
+		return c.Next()
+	}
+}
+
+// Principal returns the authenticated caller, resolving the API key if no
+// authentication middleware ran for the route
+func Principal(c *fiber.Ctx) string {
+	if principal, ok := c.Locals(principalKey).(string); ok {
+		return principal
+	}
+	if principal, ok := authenticate(c); ok {
+		return principal
+	}
+	return "anonymous"
+}
+
+func authenticate(c *fiber.Ctx) (string, bool) {
+	token := strings.TrimSpace(strings.TrimPrefix(c.Get(fiber.HeaderAuthorization), "Bearer "))
+
+	if token != "" {
+		for name, key := range config.APIKeys {
+			if subtle.ConstantTimeCompare([]byte(token), []byte(key)) == 1 {
+				return name, true
+			}
+		}
+	}
+
+	if len(config.APIKeys) == 0 && !config.IsProd {
+		return "anonymous", true
+	}
+
+	return "", false
+}
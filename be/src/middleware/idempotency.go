@@ -0,0 +1,128 @@
+package middleware
+
+import (
+	"app/src/response"
+	"app/src/service"
+	"app/src/utils"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"mime/multipart"
+	"sort"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const maxIdempotencyKeyLength = 255
+
+// Idempotency replays the stored response when a request is retried with the
+// same Idempotency-Key header. Requests without the header are not affected.
+func Idempotency(store service.IdempotencyService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		key := c.Get("Idempotency-Key")
+		if key == "" {
+			return c.Next()
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			return response.Error(c, fiber.StatusBadRequest, "Idempotency-Key must be at most 255 characters", nil)
+		}
+
+		fingerprint, err := requestFingerprint(c)
+		if err != nil {
+			return response.Error(c, fiber.StatusBadRequest, "Failed to read request payload", nil)
+		}
+
+		record, started, err := store.Begin(c.UserContext(), Principal(c), key, fingerprint)
+		switch {
+		case errors.Is(err, service.ErrIdempotencyKeyReused):
+			return response.Error(c, fiber.StatusUnprocessableEntity, err.Error(), nil)
+		case errors.Is(err, service.ErrIdempotencyKeyInFlight):
+			return response.Error(c, fiber.StatusConflict, err.Error(), nil)
+		case err != nil:
+			return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+		}
+
+		if !started {
+			c.Set("Idempotent-Replayed", "true")
+			c.Set(fiber.HeaderContentType, record.ResponseType)
+			return c.Status(record.ResponseStatus).Send(record.ResponseBody)
+		}
+
+		// Failed requests are released so that a retry runs them again
+		if err := c.Next(); err != nil {
+			if releaseErr := store.Release(c.UserContext(), record.ID); releaseErr != nil {
+				utils.Log.WithError(releaseErr).Error("Failed to release idempotency key")
+			}
+			return err
+		}
+
+		status := c.Response().StatusCode()
+		if status >= fiber.StatusInternalServerError {
+			if err := store.Release(c.UserContext(), record.ID); err != nil {
+				utils.Log.WithError(err).Error("Failed to release idempotency key")
+			}
+			return nil
+		}
+
+		body := append([]byte(nil), c.Response().Body()...)
+		contentType := string(c.Response().Header.ContentType())
+		if err := store.Complete(c.UserContext(), record.ID, status, contentType, body); err != nil {
+			utils.Log.WithError(err).Error("Failed to store idempotent response")
+		}
+
+		return nil
+	}
+}
+
+// requestFingerprint hashes the method, URL and payload. Multipart forms are
+// hashed field by field with the uploaded file contents, so a retried upload
+// matches even though the multipart boundary changes.
+func requestFingerprint(c *fiber.Ctx) (string, error) {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s %s\n", c.Method(), c.OriginalURL())
+
+	form, err := c.MultipartForm()
+	if err != nil {
+		h.Write(c.Body())
+		return hex.EncodeToString(h.Sum(nil)), nil
+	}
+
+	for _, name := range sortedKeys(form.Value) {
+		for _, value := range form.Value[name] {
+			fmt.Fprintf(h, "value %s=%q\n", name, value)
+		}
+	}
+	for _, name := range sortedKeys(form.File) {
+		for _, file := range form.File[name] {
+			fmt.Fprintf(h, "file %s=%q %d\n", name, file.Filename, file.Size)
+			if err := hashFile(h, file); err != nil {
+				return "", err
+			}
+		}
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func hashFile(h hash.Hash, file *multipart.FileHeader) error {
+	f, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = io.Copy(h, f)
+	return err
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
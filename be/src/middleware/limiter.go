@@ -23,3 +23,33 @@ func LimiterConfig() fiber.Handler {
 		SkipSuccessfulRequests: true,
 	})
 }
+
+// ShareLimiter throttles failed attempts on public share links per token and
+// client, so share passwords cannot be guessed by brute force
+func ShareLimiter() fiber.Handler {
+	return limiter.New(limiter.Config{
+		Max:        10,
+		Expiration: 15 * time.Minute,
+		KeyGenerator: func(c *fiber.Ctx) string {
+			return c.Params("token") + "|" + c.IP()
+		},
+		LimitReached: func(c *fiber.Ctx) error {
+			return c.Status(fiber.StatusTooManyRequests).
+				JSON(response.Common{
+					Code:    fiber.StatusTooManyRequests,
+					Status:  "error",
+					Message: "Too many attempts, please try again later",
+				})
+		},
+		SkipSuccessfulRequests: true,
+	})
+}
+
+// ErrorStatus hands errors to the error handler right away. Limiters earlier
+// in the chain only see the status code of failed requests this way.
+func ErrorStatus(c *fiber.Ctx) error {
+	if err := c.Next(); err != nil {
+		return c.App().ErrorHandler(c, err)
+	}
+	return nil
+}
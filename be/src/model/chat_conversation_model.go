@@ -0,0 +1,24 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ChatConversation is a thread of questions about one PDF
+type ChatConversation struct {
+	ID        uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey;column:id" json:"id"`
+	PDFID     uuid.UUID `gorm:"type:uuid;not null;column:pdf_id" json:"pdf_id"`
+	Title     string    `gorm:"type:varchar(255);not null;column:title" json:"title"`
+	Language  string    `gorm:"type:varchar(10);not null;column:language" json:"language"`
+	CreatedBy string    `gorm:"type:varchar(100);column:created_by" json:"created_by"`
+	CreatedAt time.Time `gorm:"type:timestamp;default:now();column:created_at" json:"created_at"`
+	UpdatedAt time.Time `gorm:"type:timestamp;default:now();column:updated_at" json:"updated_at"`
+
+	Messages []ChatMessage `gorm:"foreignKey:ConversationID;constraint:OnDelete:CASCADE" json:"messages,omitempty"`
+}
+
+func (ChatConversation) TableName() string {
+	return "chat_conversations"
+}
@@ -0,0 +1,31 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ChatMessage is a question ("user") or a grounded answer ("assistant").
+// Citations of answers reference the chunks and pages they were based on.
+type ChatMessage struct {
+	ID             uuid.UUID        `gorm:"type:uuid;default:gen_random_uuid();primaryKey;column:id" json:"id"`
+	ConversationID uuid.UUID        `gorm:"type:uuid;not null;column:conversation_id" json:"conversation_id"`
+	Role           string           `gorm:"type:varchar(20);not null;column:role" json:"role"`
+	Content        string           `gorm:"type:text;not null;column:content" json:"content"`
+	Citations      *json.RawMessage `gorm:"type:jsonb;column:citations" json:"citations,omitempty"`
+	Model          string           `gorm:"type:varchar(100);column:model" json:"model,omitempty"`
+	CreatedAt      time.Time        `gorm:"type:timestamp;default:now();column:created_at" json:"created_at"`
+}
+
+func (ChatMessage) TableName() string {
+	return "chat_messages"
+}
+
+type ChatCitation struct {
+	Ref        int       `json:"ref"`
+	PageNumber int       `json:"page_number"`
+	ChunkID    uuid.UUID `json:"chunk_id"`
+	Excerpt    string    `json:"excerpt"`
+}
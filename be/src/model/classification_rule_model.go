@@ -0,0 +1,25 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// ClassificationRule is one category of the document taxonomy. Keywords is
+// a list of words and phrases, LearnedKeywords maps terms to the weights the
+// last retraining computed from labeled documents.
+type ClassificationRule struct {
+	Category        string           `gorm:"type:varchar(50);primaryKey;column:category" json:"category"`
+	Label           string           `gorm:"type:varchar(100);not null;column:label" json:"label"`
+	Description     string           `gorm:"type:text;column:description" json:"description"`
+	Keywords        json.RawMessage  `gorm:"type:jsonb;not null;default:'[]';column:keywords" json:"keywords"`
+	LearnedKeywords *json.RawMessage `gorm:"type:jsonb;column:learned_keywords" json:"learned_keywords,omitempty"`
+	IsActive        bool             `gorm:"type:boolean;not null;default:true;column:is_active" json:"is_active"`
+	TrainedAt       *time.Time       `gorm:"type:timestamp;column:trained_at" json:"trained_at,omitempty"`
+	CreatedAt       time.Time        `gorm:"type:timestamp;default:now();column:created_at" json:"created_at"`
+	UpdatedAt       time.Time        `gorm:"type:timestamp;default:now();column:updated_at" json:"updated_at"`
+}
+
+func (ClassificationRule) TableName() string {
+	return "classification_rules"
+}
@@ -0,0 +1,41 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Entity is a keyword or named entity, unique by type and normalized name
+type Entity struct {
+	ID         uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey;column:id" json:"id"`
+	Type       string    `gorm:"type:varchar(20);not null;column:type" json:"type"`
+	Name       string    `gorm:"type:varchar(255);not null;column:name" json:"name"`
+	Normalized string    `gorm:"type:varchar(255);not null;column:normalized" json:"normalized"`
+	CreatedAt  time.Time `gorm:"type:timestamp;default:now();column:created_at" json:"created_at"`
+	UpdatedAt  time.Time `gorm:"type:timestamp;default:now();column:updated_at" json:"updated_at"`
+}
+
+func (Entity) TableName() string {
+	return "entities"
+}
+
+// PDFEntity links an entity to a PDF it was found in. Source tells whether it
+// came from the latest summary or the document text, Method whether the ML
+// service or the rule-based fallback found it.
+type PDFEntity struct {
+	PDFID     uuid.UUID  `gorm:"type:uuid;primaryKey;column:pdf_id" json:"pdf_id"`
+	EntityID  uuid.UUID  `gorm:"type:uuid;primaryKey;column:entity_id" json:"entity_id"`
+	Mentions  int        `gorm:"type:int;not null;default:1;column:mentions" json:"mentions"`
+	Source    string     `gorm:"type:varchar(20);not null;column:source" json:"source"`
+	Method    string     `gorm:"type:varchar(20);not null;column:method" json:"method"`
+	SummaryID *uuid.UUID `gorm:"type:uuid;column:summary_id" json:"summary_id,omitempty"`
+	CreatedAt time.Time  `gorm:"type:timestamp;default:now();column:created_at" json:"created_at"`
+
+	Entity *Entity `gorm:"foreignKey:EntityID" json:"entity,omitempty"`
+	PDF    *PDF    `gorm:"foreignKey:PDFID" json:"pdf,omitempty"`
+}
+
+func (PDFEntity) TableName() string {
+	return "pdf_entities"
+}
@@ -0,0 +1,49 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ExtractionTemplate describes the fields to pull from a document as a JSON
+// Schema. Version is bumped whenever the schema changes.
+type ExtractionTemplate struct {
+	ID          uuid.UUID       `gorm:"type:uuid;default:gen_random_uuid();primaryKey;column:id" json:"id"`
+	Name        string          `gorm:"type:varchar(100);not null;column:name" json:"name"`
+	Description string          `gorm:"type:text;column:description" json:"description"`
+	Schema      json.RawMessage `gorm:"type:jsonb;not null;column:schema" json:"schema"`
+	Version     int             `gorm:"type:int;not null;default:1;column:version" json:"version"`
+	CreatedBy   string          `gorm:"type:varchar(100);column:created_by" json:"created_by"`
+	CreatedAt   time.Time       `gorm:"type:timestamp;default:now();column:created_at" json:"created_at"`
+	UpdatedAt   time.Time       `gorm:"type:timestamp;default:now();column:updated_at" json:"updated_at"`
+	DeletedAt   gorm.DeletedAt  `gorm:"type:timestamp;column:deleted_at" json:"deleted_at,omitempty"`
+}
+
+func (ExtractionTemplate) TableName() string {
+	return "extraction_templates"
+}
+
+// ExtractionResult is one run of a template on a PDF. Data that does not
+// match the schema is kept with status "invalid" and the violations in Errors.
+type ExtractionResult struct {
+	ID              uuid.UUID        `gorm:"type:uuid;default:gen_random_uuid();primaryKey;column:id" json:"id"`
+	TemplateID      uuid.UUID        `gorm:"type:uuid;not null;column:template_id" json:"template_id"`
+	TemplateVersion int              `gorm:"type:int;not null;column:template_version" json:"template_version"`
+	PDFID           uuid.UUID        `gorm:"type:uuid;not null;column:pdf_id" json:"pdf_id"`
+	Status          string           `gorm:"type:varchar(20);not null;default:'processing';column:status" json:"status"`
+	Data            *json.RawMessage `gorm:"type:jsonb;column:data" json:"data"`
+	Errors          *json.RawMessage `gorm:"type:jsonb;column:errors" json:"errors,omitempty"`
+	Model           string           `gorm:"type:varchar(100);column:model" json:"model,omitempty"`
+	CreatedBy       string           `gorm:"type:varchar(100);column:created_by" json:"created_by"`
+	CreatedAt       time.Time        `gorm:"type:timestamp;default:now();column:created_at" json:"created_at"`
+	UpdatedAt       time.Time        `gorm:"type:timestamp;default:now();column:updated_at" json:"updated_at"`
+
+	PDF *PDF `gorm:"foreignKey:PDFID" json:"pdf,omitempty"`
+}
+
+func (ExtractionResult) TableName() string {
+	return "extraction_results"
+}
@@ -0,0 +1,26 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// IdempotencyKey stores the response of a request made with an Idempotency-Key
+// header so retries of the same request are answered without running it again
+type IdempotencyKey struct {
+	ID             uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey;column:id" json:"id"`
+	Principal      string    `gorm:"type:varchar(100);not null;column:principal" json:"principal"`
+	Key            string    `gorm:"type:varchar(255);not null;column:key" json:"key"`
+	Fingerprint    string    `gorm:"type:varchar(64);not null;column:fingerprint" json:"fingerprint"`
+	Status         string    `gorm:"type:varchar(20);not null;default:'processing';column:status" json:"status"`
+	ResponseStatus int       `gorm:"type:int;column:response_status" json:"response_status"`
+	ResponseType   string    `gorm:"type:varchar(255);column:response_type" json:"response_type"`
+	ResponseBody   []byte    `gorm:"type:bytea;column:response_body" json:"-"`
+	CreatedAt      time.Time `gorm:"type:timestamp;default:now();column:created_at" json:"created_at"`
+	ExpiresAt      time.Time `gorm:"type:timestamp;not null;column:expires_at" json:"expires_at"`
+}
+
+func (IdempotencyKey) TableName() string {
+	return "idempotency_keys"
+}
@@ -0,0 +1,24 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PDFChunk is a passage of a page's text with its embedding, stored as little
+// endian float32 values. Chunks are rebuilt whenever the pages are extracted.
+type PDFChunk struct {
+	ID         uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey;column:id" json:"id"`
+	PDFID      uuid.UUID `gorm:"type:uuid;not null;column:pdf_id" json:"pdf_id"`
+	PageNumber int       `gorm:"type:int;not null;column:page_number" json:"page_number"`
+	ChunkIndex int       `gorm:"type:int;not null;column:chunk_index" json:"chunk_index"`
+	Content    string    `gorm:"type:text;not null;column:content" json:"content"`
+	Model      string    `gorm:"type:varchar(100);not null;column:model" json:"model"`
+	Embedding  []byte    `gorm:"type:bytea;not null;column:embedding" json:"-"`
+	CreatedAt  time.Time `gorm:"type:timestamp;default:now();column:created_at" json:"created_at"`
+}
+
+func (PDFChunk) TableName() string {
+	return "pdf_chunks"
+}
@@ -14,12 +14,35 @@ type PDF struct {
 	FilePath     string         `gorm:"type:varchar(500);not null;column:file_path" json:"file_path"`
 	FileSize     int64          `gorm:"type:bigint;not null;column:file_size" json:"file_size"`
 	MimeType     string         `gorm:"type:varchar(100);column:mime_type" json:"mime_type"`
+	ContentHash  string         `gorm:"type:varchar(64);column:content_hash" json:"content_hash"`
 	Status       string         `gorm:"type:varchar(20);not null;default:'pending';column:status" json:"status"`
+	StatusReason string         `gorm:"type:text;column:status_reason" json:"status_reason,omitempty"`
+	Version      int            `gorm:"type:int;not null;default:1;column:version" json:"version"`
+
+	PageCount     int        `gorm:"type:int;column:page_count" json:"page_count"`
+	PDFVersion    string     `gorm:"type:varchar(10);column:pdf_version" json:"pdf_version"`
+	Title         string     `gorm:"type:text;column:title" json:"title"`
+	Author        string     `gorm:"type:text;column:author" json:"author"`
+	Subject       string     `gorm:"type:text;column:subject" json:"subject"`
+	Keywords      string     `gorm:"type:text;column:keywords" json:"keywords"`
+	Producer      string     `gorm:"type:text;column:producer" json:"producer"`
+	PDFCreatedAt  *time.Time `gorm:"type:timestamp;column:pdf_created_at" json:"pdf_created_at"`
+	PDFModifiedAt *time.Time `gorm:"type:timestamp;column:pdf_modified_at" json:"pdf_modified_at"`
+	HasTextLayer  bool       `gorm:"type:boolean;default:false;column:has_text_layer" json:"has_text_layer"`
+	SearchTokens  string     `gorm:"type:text;column:search_tokens" json:"-"`
+
+	// Category is nil while unclassified. Manual categories are never
+	// replaced by automatic classification.
+	Category           *string    `gorm:"type:varchar(50);column:category" json:"category"`
+	CategoryConfidence *float64   `gorm:"type:real;column:category_confidence" json:"category_confidence"`
+	CategorySource     *string    `gorm:"type:varchar(20);column:category_source" json:"category_source"`
+	ClassifiedAt       *time.Time `gorm:"type:timestamp;column:classified_at" json:"classified_at"`
+
 	UploadedAt   time.Time      `gorm:"type:timestamp;default:now();column:uploaded_at" json:"uploaded_at"`
 	UpdatedAt    time.Time      `gorm:"type:timestamp;default:now();column:updated_at" json:"updated_at"`
 	DeletedAt    gorm.DeletedAt `gorm:"type:timestamp;column:deleted_at" json:"deleted_at,omitempty"`
 
-	URL          string    `gorm:"-"`
+	URL          string    `gorm:"-" json:",omitempty"` // Signed file link, only set for the uploader
 
 	Summaries []Summary `json:"summaries,omitempty" gorm:"foreignKey:PDFID;constraint:OnDelete:CASCADE"`
 }
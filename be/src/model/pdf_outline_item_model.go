@@ -0,0 +1,23 @@
+package model
+
+import "github.com/google/uuid"
+
+// PDFOutlineItem is a bookmark of a PDF. Items are stored flat in document
+// order, PageFrom and PageTo span the pages until the next item of the same
+// or a higher level and are nil when the bookmark has no page target.
+type PDFOutlineItem struct {
+	ID       uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey;column:id" json:"id"`
+	PDFID    uuid.UUID  `gorm:"type:uuid;not null;column:pdf_id" json:"pdf_id"`
+	ParentID *uuid.UUID `gorm:"type:uuid;column:parent_id" json:"parent_id"`
+	Position int        `gorm:"type:int;not null;column:position" json:"position"`
+	Level    int        `gorm:"type:int;not null;column:level" json:"level"`
+	Title    string     `gorm:"type:text;not null;column:title" json:"title"`
+	PageFrom *int       `gorm:"type:int;column:page_from" json:"page_from"`
+	PageTo   *int       `gorm:"type:int;column:page_to" json:"page_to"`
+
+	Children []PDFOutlineItem `gorm:"-" json:"children"`
+}
+
+func (PDFOutlineItem) TableName() string {
+	return "pdf_outline_items"
+}
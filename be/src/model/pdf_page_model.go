@@ -0,0 +1,32 @@
+package model
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type PDFPage struct {
+	ID            uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey;column:id" json:"id"`
+	PDFID         uuid.UUID `gorm:"type:uuid;not null;column:pdf_id" json:"pdf_id"`
+	PageNumber    int       `gorm:"type:int;not null;column:page_number" json:"page_number"`
+	Text          string    `gorm:"type:text;column:text" json:"text"`
+	HasTextLayer  bool      `gorm:"type:boolean;column:has_text_layer" json:"has_text_layer"`
+	OCRText       string    `gorm:"type:text;column:ocr_text" json:"ocr_text,omitempty"`
+	OCRConfidence *float64  `gorm:"type:real;column:ocr_confidence" json:"ocr_confidence,omitempty"`
+	SearchTokens  string    `gorm:"type:text;column:search_tokens" json:"-"`
+	CreatedAt     time.Time `gorm:"type:timestamp;default:now();column:created_at" json:"created_at"`
+}
+
+func (PDFPage) TableName() string {
+	return "pdf_pages"
+}
+
+// Content returns the native text of the page, falling back to OCR text
+func (p PDFPage) Content() string {
+	if text := strings.TrimSpace(p.Text); text != "" {
+		return text
+	}
+	return strings.TrimSpace(p.OCRText)
+}
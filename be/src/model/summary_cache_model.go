@@ -0,0 +1,29 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SummaryCache holds the AI output for a document, keyed by the file hash and
+// everything that influences the generated text
+type SummaryCache struct {
+	ID              uuid.UUID        `gorm:"type:uuid;default:gen_random_uuid();primaryKey;column:id" json:"id"`
+	ContentHash     string           `gorm:"type:varchar(64);not null;column:content_hash" json:"content_hash"`
+	Language        string           `gorm:"type:varchar(10);not null;column:language" json:"language"`
+	Style           string           `gorm:"type:varchar(20);not null;column:style" json:"style"`
+	Model           string           `gorm:"type:varchar(100);not null;column:model" json:"model"`
+	PromptVersion   string           `gorm:"type:varchar(20);not null;column:prompt_version" json:"prompt_version"`
+	Content         string           `gorm:"type:text;not null;column:content" json:"content"`
+	Structured      *json.RawMessage `gorm:"type:jsonb;column:structured" json:"structured"`
+	SourceSummaryID *uuid.UUID       `gorm:"type:uuid;column:source_summary_id" json:"source_summary_id"`
+	HitCount        int              `gorm:"type:int;not null;default:0;column:hit_count" json:"hit_count"`
+	LastHitAt       *time.Time       `gorm:"type:timestamp;column:last_hit_at" json:"last_hit_at"`
+	CreatedAt       time.Time        `gorm:"type:timestamp;default:now();column:created_at" json:"created_at"`
+}
+
+func (SummaryCache) TableName() string {
+	return "summary_cache"
+}
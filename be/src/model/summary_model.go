@@ -12,16 +12,51 @@ type Summary struct {
 	ID        uuid.UUID        `gorm:"type:uuid;default:gen_random_uuid();primaryKey;column:id" json:"id"`
 	PDFID     uuid.UUID        `gorm:"type:uuid;not null;column:pdf_id" json:"pdf_id"`
 	Content   string           `gorm:"type:text;column:content" json:"content"`
+	SearchTokens string        `gorm:"type:text;column:search_tokens" json:"-"`
 	Language  string           `gorm:"type:varchar(10);not null;column:language" json:"language"`
 	Style     string           `gorm:"type:varchar(20);not null;column:style" json:"style"`
+	Kind      string           `gorm:"type:varchar(20);not null;default:'single';column:kind" json:"kind"`
+	Mode      *string          `gorm:"type:varchar(20);column:mode" json:"mode,omitempty"`
+	PageFrom  *int             `gorm:"type:int;column:page_from" json:"page_from"`
+	PageTo    *int             `gorm:"type:int;column:page_to" json:"page_to"`
+	OutlineItemID *uuid.UUID   `gorm:"type:uuid;column:outline_item_id" json:"outline_item_id,omitempty"`
 	Status    string           `gorm:"type:varchar(20);not null;default:'processing';column:status" json:"status"`
 	IsEdited  bool             `gorm:"type:boolean;default:false;column:is_edited" json:"is_edited"`
 	Metadata  *json.RawMessage `gorm:"type:jsonb;column:metadata" json:"metadata"`
+	Structured *json.RawMessage `gorm:"type:jsonb;column:structured" json:"structured"`
+	Version   int              `gorm:"type:int;not null;default:1;column:version" json:"version"`
 	CreatedAt time.Time      `gorm:"type:timestamp;default:now();column:created_at" json:"created_at"`
 	UpdatedAt time.Time      `gorm:"type:timestamp;default:now();column:updated_at" json:"updated_at"`
 	DeletedAt gorm.DeletedAt `gorm:"type:timestamp;column:deleted_at" json:"deleted_at,omitempty"`
+
+	Sources []SummarySource `gorm:"foreignKey:SummaryID;constraint:OnDelete:CASCADE" json:"sources,omitempty"`
 }
 
 func (Summary) TableName() string {
 	return "summaries"
 }
+
+// IsPartial reports whether the summary only covers a page range
+func (s Summary) IsPartial() bool {
+	return s.PageFrom != nil
+}
+
+// InRange reports whether a page belongs to the summarized range
+func (s Summary) InRange(page int) bool {
+	if s.PageFrom == nil || s.PageTo == nil {
+		return true
+	}
+	return page >= *s.PageFrom && page <= *s.PageTo
+}
+
+// StructuredSummary is the content split into its parts. It is derived when
+// the content is generated, and again in the background after edits and
+// restores. Failures are recorded in the metadata as structured_error.
+type StructuredSummary struct {
+	Purpose     string   `json:"purpose"`
+	KeyPoints   []string `json:"key_points"`
+	Findings    []string `json:"findings"`
+	Conclusions []string `json:"conclusions"`
+	Keywords    []string `json:"keywords"`
+	Takeaways   []string `json:"takeaways"`
+}
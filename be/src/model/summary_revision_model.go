@@ -0,0 +1,24 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SummaryRevision is an immutable snapshot of a summary's content. Source is
+// "ai" for generated content, "manual" for edits and "restore" for rollbacks.
+type SummaryRevision struct {
+	ID             uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey;column:id" json:"id"`
+	SummaryID      uuid.UUID `gorm:"type:uuid;not null;column:summary_id" json:"summary_id"`
+	RevisionNumber int       `gorm:"type:int;not null;column:revision_number" json:"revision_number"`
+	Content        string    `gorm:"type:text;not null;column:content" json:"content"`
+	Source         string    `gorm:"type:varchar(20);not null;column:source" json:"source"`
+	Author         string    `gorm:"type:varchar(100);column:author" json:"author"`
+	RestoredFrom   *int      `gorm:"type:int;column:restored_from" json:"restored_from,omitempty"`
+	CreatedAt      time.Time `gorm:"type:timestamp;default:now();column:created_at" json:"created_at"`
+}
+
+func (SummaryRevision) TableName() string {
+	return "summary_revisions"
+}
@@ -0,0 +1,39 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SummaryShare is a public, read-only link to a summary. Only a hash of the
+// token is stored, the token itself is returned once when the share is created.
+type SummaryShare struct {
+	ID           uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey;column:id" json:"id"`
+	SummaryID    uuid.UUID  `gorm:"type:uuid;not null;column:summary_id" json:"summary_id"`
+	PDFID        uuid.UUID  `gorm:"type:uuid;not null;column:pdf_id" json:"pdf_id"`
+	TokenHash    string     `gorm:"type:varchar(64);not null;column:token_hash" json:"-"`
+	PasswordHash string     `gorm:"type:varchar(100);column:password_hash" json:"-"`
+	ExpiresAt    *time.Time `gorm:"type:timestamp;column:expires_at" json:"expires_at"`
+	ViewCount    int        `gorm:"type:int;not null;default:0;column:view_count" json:"view_count"`
+	LastViewedAt *time.Time `gorm:"type:timestamp;column:last_viewed_at" json:"last_viewed_at"`
+	CreatedBy    string     `gorm:"type:varchar(100);column:created_by" json:"created_by"`
+	RevokedAt    *time.Time `gorm:"type:timestamp;column:revoked_at" json:"revoked_at,omitempty"`
+	CreatedAt    time.Time  `gorm:"type:timestamp;default:now();column:created_at" json:"created_at"`
+
+	HasPassword bool   `gorm:"-" json:"has_password"`
+	Token       string `gorm:"-" json:"token,omitempty"`
+	URL         string `gorm:"-" json:"url,omitempty"`
+}
+
+func (SummaryShare) TableName() string {
+	return "summary_shares"
+}
+
+// Active reports whether the share can still be viewed
+func (s SummaryShare) Active() bool {
+	if s.RevokedAt != nil {
+		return false
+	}
+	return s.ExpiresAt == nil || s.ExpiresAt.After(time.Now())
+}
@@ -0,0 +1,15 @@
+package model
+
+import "github.com/google/uuid"
+
+// SummarySource links a synthesis summary to one of its PDFs, Position keeps
+// the order the documents were given to the AI in
+type SummarySource struct {
+	SummaryID uuid.UUID `gorm:"type:uuid;primaryKey;column:summary_id" json:"summary_id"`
+	PDFID     uuid.UUID `gorm:"type:uuid;primaryKey;column:pdf_id" json:"pdf_id"`
+	Position  int       `gorm:"type:int;not null;column:position" json:"position"`
+}
+
+func (SummarySource) TableName() string {
+	return "summary_sources"
+}
@@ -0,0 +1,177 @@
+package pdfparser
+
+import (
+	"bytes"
+	"regexp"
+	"strconv"
+)
+
+const (
+	maxPageTreeDepth = 64
+	maxPages         = 100000
+)
+
+var versionPattern = regexp.MustCompile(`^%PDF-(\d\.\d)`)
+
+type Document struct {
+	Version   string
+	Encrypted bool
+	PageCount int
+	Pages     []Page
+
+	reader  *reader
+	catalog Dict
+}
+
+type Page struct {
+	Ref       Ref
+	Dict      Dict
+	Resources Dict
+}
+
+// Parse validates the structure of a PDF file: header, trailer, cross-reference
+// data, document catalog and page tree.
+func Parse(data []byte) (*Document, error) {
+	match := versionPattern.FindSubmatch(data)
+	if match == nil {
+		return nil, newError(CodeInvalidHeader, "file does not start with a valid %%PDF-x.y header")
+	}
+
+	tail := data
+	if len(tail) > 2048 {
+		tail = tail[len(tail)-2048:]
+	}
+
+	if !bytes.Contains(tail, []byte("%%EOF")) {
+		return nil, newError(CodeMissingEOF, "file has no %%%%EOF marker, it may be truncated")
+	}
+
+	idx := bytes.LastIndex(tail, []byte("startxref"))
+	if idx < 0 {
+		return nil, newError(CodeMissingStartXref, "file has no startxref pointer")
+	}
+	p := &parser{data: tail, pos: idx + len("startxref")}
+	tok, err := p.readToken()
+	startxref, ok := tok.(int64)
+	if err != nil || !ok {
+		return nil, newError(CodeMissingStartXref, "startxref pointer is not a valid offset")
+	}
+
+	r := newReader(data)
+	if err := r.loadXref(startxref); err != nil {
+		return nil, newError(CodeInvalidXref, "invalid cross-reference data: %v", err)
+	}
+
+	if _, ok := r.trailer["Root"].(Ref); !ok {
+		return nil, newError(CodeInvalidTrailer, "trailer has no /Root reference")
+	}
+	_, r.encrypted = r.trailer["Encrypt"]
+
+	catalog := r.dict(r.trailer["Root"])
+	if catalog == nil {
+		return nil, newError(CodeInvalidCatalog, "document catalog is missing or unreadable")
+	}
+
+	doc := &Document{
+		Version:   string(match[1]),
+		Encrypted: r.encrypted,
+		reader:    r,
+		catalog:   catalog,
+	}
+
+	// The catalog may declare a newer version than the header after incremental updates
+	if v := catalog.Name("Version"); v != "" {
+		if newer, err := strconv.ParseFloat(string(v), 64); err == nil {
+			if current, _ := strconv.ParseFloat(doc.Version, 64); newer > current {
+				doc.Version = string(v)
+			}
+		}
+	}
+
+	if err := doc.loadPages(); err != nil {
+		// Page objects of encrypted files may live in encrypted object streams
+		if !doc.Encrypted {
+			return nil, err
+		}
+		if root := r.dict(catalog["Pages"]); root != nil {
+			if count, ok := root.Int("Count"); ok {
+				doc.PageCount = int(count)
+			}
+		}
+		return doc, nil
+	}
+
+	if doc.PageCount == 0 {
+		return nil, newError(CodeNoPages, "document has no pages")
+	}
+
+	return doc, nil
+}
+
+func (d *Document) loadPages() error {
+	rootRef, _ := d.catalog["Pages"].(Ref)
+	root, err := d.reader.resolve(d.catalog["Pages"])
+	if err != nil {
+		return newError(CodeInvalidPageTree, "failed to read page tree root: %v", err)
+	}
+	rootDict, ok := root.(Dict)
+	if !ok {
+		return newError(CodeInvalidPageTree, "document catalog has no page tree")
+	}
+
+	visited := map[Ref]bool{rootRef: true}
+	if err := d.walkPages(rootRef, rootDict, nil, visited, 0); err != nil {
+		return err
+	}
+
+	d.PageCount = len(d.Pages)
+	return nil
+}
+
+func (d *Document) walkPages(ref Ref, node Dict, resources Dict, visited map[Ref]bool, depth int) error {
+	if depth > maxPageTreeDepth {
+		return newError(CodeInvalidPageTree, "page tree is nested too deeply")
+	}
+
+	if res := d.reader.dict(node["Resources"]); res != nil {
+		resources = res
+	}
+
+	kids, hasKids := d.reader.resolveQuiet(node["Kids"]).(Array)
+	nodeType := node.Name("Type")
+	if nodeType == "Page" || (nodeType != "Pages" && !hasKids) {
+		if len(d.Pages) >= maxPages {
+			return newError(CodeInvalidPageTree, "document has more than %d pages", maxPages)
+		}
+		d.Pages = append(d.Pages, Page{Ref: ref, Dict: node, Resources: resources})
+		return nil
+	}
+	if !hasKids {
+		return newError(CodeInvalidPageTree, "page tree node %d has no /Kids", ref.Num)
+	}
+
+	for _, kid := range kids {
+		kidRef, ok := kid.(Ref)
+		if !ok {
+			return newError(CodeInvalidPageTree, "page tree node %d has a direct kid", ref.Num)
+		}
+		if visited[kidRef] {
+			return newError(CodeInvalidPageTree, "page tree contains a cycle at object %d", kidRef.Num)
+		}
+		visited[kidRef] = true
+
+		obj, err := d.reader.resolve(kidRef)
+		if err != nil {
+			return newError(CodeInvalidPageTree, "failed to read page tree node %d: %v", kidRef.Num, err)
+		}
+		child, ok := obj.(Dict)
+		if !ok {
+			return newError(CodeInvalidPageTree, "page tree node %d is not a dictionary", kidRef.Num)
+		}
+		if err := d.walkPages(kidRef, child, resources, visited, depth+1); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
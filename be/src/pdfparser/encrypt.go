@@ -0,0 +1,174 @@
+package pdfparser
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/md5"
+	"crypto/rc4"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"hash"
+)
+
+// Padding string from the standard security handler (ISO 32000-1, 7.6.3.3)
+var passwordPadding = []byte{
+	0x28, 0xbf, 0x4e, 0x5e, 0x4e, 0x75, 0x8a, 0x41, 0x64, 0x00, 0x4e, 0x56, 0xff, 0xfa, 0x01, 0x08,
+	0x2e, 0x2e, 0x00, 0xb6, 0xd0, 0x68, 0x3e, 0x80, 0x2f, 0x0c, 0xa9, 0xfe, 0x64, 0x53, 0x69, 0x7a,
+}
+
+// RequiresPassword reports whether the document cannot be opened without a user
+// password. Files encrypted only with an owner password (permission restrictions)
+// open with an empty user password and are not considered locked.
+func (d *Document) RequiresPassword() bool {
+	if !d.Encrypted {
+		return false
+	}
+
+	enc := d.reader.dict(d.reader.trailer["Encrypt"])
+	if enc == nil || enc.Name("Filter") != "Standard" {
+		return true
+	}
+
+	revision, _ := enc.Int("R")
+	o, _ := d.reader.resolveQuiet(enc["O"]).(String)
+	u, _ := d.reader.resolveQuiet(enc["U"]).(String)
+
+	switch {
+	case revision >= 2 && revision <= 4:
+		return !d.checkUserPasswordLegacy(enc, int(revision), []byte(o), []byte(u))
+	case revision == 5 || revision == 6:
+		return !checkUserPasswordAES256(int(revision), []byte(u))
+	}
+
+	return true
+}
+
+func (d *Document) checkUserPasswordLegacy(enc Dict, revision int, o, u []byte) bool {
+	if len(o) < 32 || len(u) < 32 {
+		return false
+	}
+
+	keyLen := 5
+	if revision >= 3 {
+		if bits, ok := enc.Int("Length"); ok && bits >= 40 && bits <= 128 && bits%8 == 0 {
+			keyLen = int(bits / 8)
+		}
+		// Crypt filters of revision 4 use 128-bit keys unless stated otherwise
+		if revision == 4 {
+			keyLen = 16
+		}
+	}
+
+	var firstID []byte
+	if ids, ok := d.reader.resolveQuiet(d.reader.trailer["ID"]).(Array); ok && len(ids) > 0 {
+		if id, ok := d.reader.resolveQuiet(ids[0]).(String); ok {
+			firstID = []byte(id)
+		}
+	}
+
+	permissions, _ := enc.Int("P")
+	p := make([]byte, 4)
+	binary.LittleEndian.PutUint32(p, uint32(int32(permissions)))
+
+	h := md5.New()
+	h.Write(passwordPadding)
+	h.Write(o[:32])
+	h.Write(p)
+	h.Write(firstID)
+	if encryptMetadata, ok := enc["EncryptMetadata"].(bool); revision >= 4 && ok && !encryptMetadata {
+		h.Write([]byte{0xff, 0xff, 0xff, 0xff})
+	}
+	key := h.Sum(nil)
+	if revision >= 3 {
+		for i := 0; i < 50; i++ {
+			sum := md5.Sum(key[:keyLen])
+			key = sum[:]
+		}
+	}
+	key = key[:keyLen]
+
+	if revision == 2 {
+		c, err := rc4.NewCipher(key)
+		if err != nil {
+			return false
+		}
+		out := make([]byte, 32)
+		c.XORKeyStream(out, passwordPadding)
+		return bytes.Equal(out, u[:32])
+	}
+
+	h = md5.New()
+	h.Write(passwordPadding)
+	h.Write(firstID)
+	out := h.Sum(nil)
+	for i := 0; i < 20; i++ {
+		roundKey := make([]byte, len(key))
+		for j := range key {
+			roundKey[j] = key[j] ^ byte(i)
+		}
+		c, err := rc4.NewCipher(roundKey)
+		if err != nil {
+			return false
+		}
+		c.XORKeyStream(out, out)
+	}
+
+	return bytes.Equal(out[:16], u[:16])
+}
+
+func checkUserPasswordAES256(revision int, u []byte) bool {
+	if len(u) < 48 {
+		return false
+	}
+
+	validationSalt := u[32:40]
+	if revision == 5 {
+		sum := sha256.Sum256(validationSalt)
+		return bytes.Equal(sum[:], u[:32])
+	}
+
+	return bytes.Equal(hardenedHash(nil, validationSalt), u[:32])
+}
+
+// hardenedHash implements algorithm 2.B of ISO 32000-2 for an empty user key
+func hardenedHash(password, salt []byte) []byte {
+	first := sha256.Sum256(append(append([]byte{}, password...), salt...))
+	k := first[:]
+
+	for round := 0; ; round++ {
+		block := append(append([]byte{}, password...), k...)
+		k1 := bytes.Repeat(block, 64)
+
+		c, err := aes.NewCipher(k[:16])
+		if err != nil {
+			return nil
+		}
+		e := make([]byte, len(k1))
+		cipher.NewCBCEncrypter(c, k[16:32]).CryptBlocks(e, k1)
+
+		sum := 0
+		for _, b := range e[:16] {
+			sum += int(b)
+		}
+
+		var h hash.Hash
+		switch sum % 3 {
+		case 0:
+			h = sha256.New()
+		case 1:
+			h = sha512.New384()
+		default:
+			h = sha512.New()
+		}
+		h.Write(e)
+		k = h.Sum(nil)
+
+		if round >= 63 && int(e[len(e)-1]) <= round-31 {
+			break
+		}
+	}
+
+	return k[:32]
+}
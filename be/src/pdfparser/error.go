@@ -0,0 +1,32 @@
+package pdfparser
+
+import "fmt"
+
+// Validation error codes returned to clients and stored in processing logs
+const (
+	CodeInvalidHeader      = "pdf_invalid_header"
+	CodeUnsupportedVersion = "pdf_unsupported_version"
+	CodeMissingEOF         = "pdf_missing_eof"
+	CodeMissingStartXref   = "pdf_missing_startxref"
+	CodeInvalidXref        = "pdf_invalid_xref"
+	CodeInvalidTrailer     = "pdf_invalid_trailer"
+	CodeInvalidCatalog     = "pdf_invalid_catalog"
+	CodeInvalidPageTree    = "pdf_invalid_page_tree"
+	CodeNoPages            = "pdf_no_pages"
+)
+
+type Error struct {
+	Code    string
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func newError(code, format string, args ...interface{}) *Error {
+	return &Error{
+		Code:    code,
+		Message: fmt.Sprintf(format, args...),
+	}
+}
@@ -0,0 +1,241 @@
+package pdfparser
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Pages inspected when looking for a text layer, large documents are sampled
+const maxTextLayerPages = 50
+
+var (
+	datePattern    = regexp.MustCompile(`^(?:D:)?(\d{4})(\d{2})?(\d{2})?(\d{2})?(\d{2})?(\d{2})?([Zz+\-])?(\d{2})?'?(\d{2})?'?`)
+	textBeginToken = regexp.MustCompile(`(?:^|[\s\]>)}])BT[\s/\[<(]`)
+)
+
+// pdfDocEncoding maps the bytes where PDFDocEncoding differs from Latin-1
+var pdfDocEncoding = map[byte]rune{
+	0x18: '˘', 0x19: 'ˇ', 0x1a: 'ˆ', 0x1b: '˙',
+	0x1c: '˝', 0x1d: '˛', 0x1e: '˚', 0x1f: '˜',
+	0x80: '•', 0x81: '†', 0x82: '‡', 0x83: '…',
+	0x84: '—', 0x85: '–', 0x86: 'ƒ', 0x87: '⁄',
+	0x88: '‹', 0x89: '›', 0x8a: '−', 0x8b: '‰',
+	0x8c: '„', 0x8d: '“', 0x8e: '”', 0x8f: '‘',
+	0x90: '’', 0x91: '‚', 0x92: '™', 0x93: 'ﬁ',
+	0x94: 'ﬂ', 0x95: 'Ł', 0x96: 'Œ', 0x97: 'Š',
+	0x98: 'Ÿ', 0x99: 'Ž', 0x9a: 'ı', 0x9b: 'ł',
+	0x9c: 'œ', 0x9d: 'š', 0x9e: 'ž', 0xa0: '€',
+}
+
+type Metadata struct {
+	Title        string
+	Author       string
+	Subject      string
+	Keywords     string
+	Creator      string
+	Producer     string
+	CreationDate *time.Time
+	ModDate      *time.Time
+}
+
+// Metadata returns the document information dictionary. Strings of encrypted
+// documents cannot be read without decrypting them, so an empty value is returned.
+func (d *Document) Metadata() Metadata {
+	var meta Metadata
+	if d.Encrypted {
+		return meta
+	}
+
+	info := d.reader.dict(d.reader.trailer["Info"])
+	if info == nil {
+		return meta
+	}
+
+	meta.Title = d.textString(info["Title"])
+	meta.Author = d.textString(info["Author"])
+	meta.Subject = d.textString(info["Subject"])
+	meta.Keywords = d.textString(info["Keywords"])
+	meta.Creator = d.textString(info["Creator"])
+	meta.Producer = d.textString(info["Producer"])
+	meta.CreationDate = ParseDate(d.textString(info["CreationDate"]))
+	meta.ModDate = ParseDate(d.textString(info["ModDate"]))
+
+	return meta
+}
+
+func (d *Document) textString(obj Object) string {
+	s, ok := d.reader.resolveQuiet(obj).(String)
+	if !ok {
+		return ""
+	}
+	return strings.TrimSpace(strings.ReplaceAll(DecodeTextString(s), "\x00", ""))
+}
+
+// DecodeTextString converts a PDF text string (UTF-16BE, UTF-8 or PDFDocEncoding) to UTF-8
+func DecodeTextString(s String) string {
+	b := []byte(s)
+
+	if len(b) >= 2 && b[0] == 0xfe && b[1] == 0xff {
+		units := make([]uint16, 0, (len(b)-2)/2)
+		for i := 2; i+1 < len(b); i += 2 {
+			units = append(units, uint16(b[i])<<8|uint16(b[i+1]))
+		}
+		return string(utf16.Decode(units))
+	}
+
+	if len(b) >= 3 && b[0] == 0xef && b[1] == 0xbb && b[2] == 0xbf && utf8.Valid(b[3:]) {
+		return string(b[3:])
+	}
+
+	var sb strings.Builder
+	for _, c := range b {
+		if r, ok := pdfDocEncoding[c]; ok {
+			sb.WriteRune(r)
+		} else {
+			sb.WriteRune(rune(c))
+		}
+	}
+	return sb.String()
+}
+
+// ParseDate parses dates of the form D:YYYYMMDDHHmmSSOHH'mm'
+func ParseDate(value string) *time.Time {
+	m := datePattern.FindStringSubmatch(strings.TrimSpace(value))
+	if m == nil {
+		return nil
+	}
+
+	part := func(i, fallback int) int {
+		if m[i] == "" {
+			return fallback
+		}
+		v, _ := strconv.Atoi(m[i])
+		return v
+	}
+
+	loc := time.UTC
+	if m[7] == "+" || m[7] == "-" {
+		offset := part(8, 0)*3600 + part(9, 0)*60
+		if m[7] == "-" {
+			offset = -offset
+		}
+		loc = time.FixedZone("", offset)
+	}
+
+	t := time.Date(part(1, 0), time.Month(part(2, 1)), part(3, 1), part(4, 0), part(5, 0), part(6, 0), 0, loc)
+	if t.Year() < 1900 || t.Month() != time.Month(part(2, 1)) {
+		return nil
+	}
+
+	utc := t.UTC()
+	return &utc
+}
+
+// HasTextLayer reports whether any of the sampled pages contains text
+func (d *Document) HasTextLayer() bool {
+	for i := range d.Pages {
+		if i >= maxTextLayerPages {
+			break
+		}
+		if d.PageHasText(i) {
+			return true
+		}
+	}
+	return false
+}
+
+// PageHasText reports whether the page at index uses fonts and shows text.
+// Pages that only paint images, such as scans, return false.
+func (d *Document) PageHasText(index int) bool {
+	if index < 0 || index >= len(d.Pages) {
+		return false
+	}
+	page := d.Pages[index]
+
+	if !d.resourcesHaveFonts(page.Resources, 0) {
+		return false
+	}
+
+	contents := d.contentStreams(page.Dict["Contents"])
+	if len(contents) == 0 {
+		return false
+	}
+
+	for _, stream := range contents {
+		data, err := d.reader.decodeStream(stream)
+		if err != nil {
+			// Undecodable content (e.g. encrypted), trust the font resources
+			return true
+		}
+		if textBeginToken.Match(data) {
+			return true
+		}
+	}
+
+	// Text may be drawn inside form XObjects referenced by the page
+	return d.xObjectsHaveText(page.Resources, 0)
+}
+
+func (d *Document) resourcesHaveFonts(resources Dict, depth int) bool {
+	if resources == nil || depth > 4 {
+		return false
+	}
+	if fonts := d.reader.dict(resources["Font"]); len(fonts) > 0 {
+		return true
+	}
+
+	xobjects := d.reader.dict(resources["XObject"])
+	for _, ref := range xobjects {
+		stream, ok := d.reader.resolveQuiet(ref).(*Stream)
+		if !ok || stream.Dict.Name("Subtype") != "Form" {
+			continue
+		}
+		if d.resourcesHaveFonts(d.reader.dict(stream.Dict["Resources"]), depth+1) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (d *Document) xObjectsHaveText(resources Dict, depth int) bool {
+	if resources == nil || depth > 4 {
+		return false
+	}
+
+	for _, ref := range d.reader.dict(resources["XObject"]) {
+		stream, ok := d.reader.resolveQuiet(ref).(*Stream)
+		if !ok || stream.Dict.Name("Subtype") != "Form" {
+			continue
+		}
+		data, err := d.reader.decodeStream(stream)
+		if err == nil && textBeginToken.Match(data) {
+			return true
+		}
+		if d.xObjectsHaveText(d.reader.dict(stream.Dict["Resources"]), depth+1) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (d *Document) contentStreams(obj Object) []*Stream {
+	switch v := d.reader.resolveQuiet(obj).(type) {
+	case *Stream:
+		return []*Stream{v}
+	case Array:
+		var streams []*Stream
+		for _, item := range v {
+			if s, ok := d.reader.resolveQuiet(item).(*Stream); ok {
+				streams = append(streams, s)
+			}
+		}
+		return streams
+	}
+	return nil
+}
@@ -0,0 +1,355 @@
+package pdfparser
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+type (
+	Object interface{}
+	Name   string
+	String string
+	Array  []Object
+	Dict   map[Name]Object
+)
+
+type Ref struct {
+	Num int
+	Gen int
+}
+
+type Stream struct {
+	Dict Dict
+	Raw  []byte
+}
+
+type keyword string
+
+var errUnexpectedEOF = errors.New("unexpected end of data")
+
+// parser reads PDF objects from a byte slice starting at pos
+type parser struct {
+	data []byte
+	pos  int
+}
+
+func isWhitespace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r' || c == '\n' || c == '\f' || c == 0
+}
+
+func isDelimiter(c byte) bool {
+	switch c {
+	case '(', ')', '<', '>', '[', ']', '{', '}', '/', '%':
+		return true
+	}
+	return false
+}
+
+func (p *parser) skipSpace() {
+	for p.pos < len(p.data) {
+		c := p.data[p.pos]
+		if isWhitespace(c) {
+			p.pos++
+			continue
+		}
+		if c == '%' {
+			for p.pos < len(p.data) && p.data[p.pos] != '\n' && p.data[p.pos] != '\r' {
+				p.pos++
+			}
+			continue
+		}
+		return
+	}
+}
+
+func (p *parser) readRegular() []byte {
+	start := p.pos
+	for p.pos < len(p.data) && !isWhitespace(p.data[p.pos]) && !isDelimiter(p.data[p.pos]) {
+		p.pos++
+	}
+	return p.data[start:p.pos]
+}
+
+// readToken returns the next token without interpreting composite objects
+func (p *parser) readToken() (Object, error) {
+	if p.pos < 0 {
+		return nil, fmt.Errorf("offset %d is outside the data", p.pos)
+	}
+	p.skipSpace()
+	if p.pos >= len(p.data) {
+		return nil, errUnexpectedEOF
+	}
+
+	c := p.data[p.pos]
+	switch c {
+	case '/':
+		p.pos++
+		return p.readName(), nil
+	case '(':
+		p.pos++
+		return p.readLiteralString()
+	case '<':
+		if p.pos+1 < len(p.data) && p.data[p.pos+1] == '<' {
+			p.pos += 2
+			return keyword("<<"), nil
+		}
+		p.pos++
+		return p.readHexString()
+	case '>':
+		if p.pos+1 < len(p.data) && p.data[p.pos+1] == '>' {
+			p.pos += 2
+			return keyword(">>"), nil
+		}
+		return nil, fmt.Errorf("unexpected '>' at offset %d", p.pos)
+	case '[', ']', '{', '}':
+		p.pos++
+		return keyword(string(c)), nil
+	case ')':
+		return nil, fmt.Errorf("unexpected ')' at offset %d", p.pos)
+	}
+
+	tok := p.readRegular()
+	if len(tok) == 0 {
+		return nil, fmt.Errorf("unexpected byte 0x%02x at offset %d", c, p.pos)
+	}
+
+	if (tok[0] >= '0' && tok[0] <= '9') || tok[0] == '-' || tok[0] == '+' || tok[0] == '.' {
+		if i, err := strconv.ParseInt(string(tok), 10, 64); err == nil {
+			return i, nil
+		}
+		if f, err := strconv.ParseFloat(string(tok), 64); err == nil {
+			return f, nil
+		}
+	}
+
+	switch string(tok) {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "null":
+		return nil, nil
+	}
+
+	return keyword(tok), nil
+}
+
+func (p *parser) readName() Name {
+	raw := p.readRegular()
+	if bytes.IndexByte(raw, '#') < 0 {
+		return Name(raw)
+	}
+
+	out := make([]byte, 0, len(raw))
+	for i := 0; i < len(raw); i++ {
+		if raw[i] == '#' && i+2 < len(raw) {
+			if v, err := strconv.ParseUint(string(raw[i+1:i+3]), 16, 8); err == nil {
+				out = append(out, byte(v))
+				i += 2
+				continue
+			}
+		}
+		out = append(out, raw[i])
+	}
+	return Name(out)
+}
+
+func (p *parser) readLiteralString() (String, error) {
+	var out []byte
+	depth := 1
+
+	for p.pos < len(p.data) {
+		c := p.data[p.pos]
+		p.pos++
+
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return String(out), nil
+			}
+		case '\\':
+			if p.pos >= len(p.data) {
+				return "", errUnexpectedEOF
+			}
+			c = p.data[p.pos]
+			p.pos++
+
+			switch c {
+			case 'n':
+				c = '\n'
+			case 'r':
+				c = '\r'
+			case 't':
+				c = '\t'
+			case 'b':
+				c = '\b'
+			case 'f':
+				c = '\f'
+			case '\r':
+				if p.pos < len(p.data) && p.data[p.pos] == '\n' {
+					p.pos++
+				}
+				continue
+			case '\n':
+				continue
+			default:
+				if c >= '0' && c <= '7' {
+					v := int(c - '0')
+					for i := 0; i < 2 && p.pos < len(p.data) && p.data[p.pos] >= '0' && p.data[p.pos] <= '7'; i++ {
+						v = v*8 + int(p.data[p.pos]-'0')
+						p.pos++
+					}
+					c = byte(v)
+				}
+			}
+		}
+		out = append(out, c)
+	}
+
+	return "", errUnexpectedEOF
+}
+
+func (p *parser) readHexString() (String, error) {
+	var out []byte
+	var hi byte
+	odd := false
+
+	for p.pos < len(p.data) {
+		c := p.data[p.pos]
+		p.pos++
+
+		if c == '>' {
+			if odd {
+				out = append(out, hi<<4)
+			}
+			return String(out), nil
+		}
+		if isWhitespace(c) {
+			continue
+		}
+
+		var v byte
+		switch {
+		case c >= '0' && c <= '9':
+			v = c - '0'
+		case c >= 'a' && c <= 'f':
+			v = c - 'a' + 10
+		case c >= 'A' && c <= 'F':
+			v = c - 'A' + 10
+		default:
+			return "", fmt.Errorf("invalid hex string byte 0x%02x", c)
+		}
+
+		if odd {
+			out = append(out, hi<<4|v)
+		} else {
+			hi = v
+		}
+		odd = !odd
+	}
+
+	return "", errUnexpectedEOF
+}
+
+// readObject parses a complete object, including arrays, dictionaries and references
+func (p *parser) readObject() (Object, error) {
+	tok, err := p.readToken()
+	if err != nil {
+		return nil, err
+	}
+	return p.completeObject(tok, 0)
+}
+
+func (p *parser) completeObject(tok Object, depth int) (Object, error) {
+	if depth > 64 {
+		return nil, errors.New("object nesting too deep")
+	}
+
+	switch t := tok.(type) {
+	case keyword:
+		switch t {
+		case "[":
+			arr := Array{}
+			for {
+				next, err := p.readToken()
+				if err != nil {
+					return nil, err
+				}
+				if next == keyword("]") {
+					return arr, nil
+				}
+				obj, err := p.completeObject(next, depth+1)
+				if err != nil {
+					return nil, err
+				}
+				arr = append(arr, obj)
+			}
+		case "<<":
+			dict := Dict{}
+			for {
+				next, err := p.readToken()
+				if err != nil {
+					return nil, err
+				}
+				if next == keyword(">>") {
+					return dict, nil
+				}
+				key, ok := next.(Name)
+				if !ok {
+					return nil, fmt.Errorf("dictionary key is not a name at offset %d", p.pos)
+				}
+				valTok, err := p.readToken()
+				if err != nil {
+					return nil, err
+				}
+				val, err := p.completeObject(valTok, depth+1)
+				if err != nil {
+					return nil, err
+				}
+				dict[key] = val
+			}
+		}
+		return t, nil
+
+	case int64:
+		// Look ahead for "gen R" to form an indirect reference
+		save := p.pos
+		gen, err := p.readToken()
+		if err == nil {
+			if g, ok := gen.(int64); ok {
+				r, err := p.readToken()
+				if err == nil && r == keyword("R") {
+					return Ref{Num: int(t), Gen: int(g)}, nil
+				}
+			}
+		}
+		p.pos = save
+		return t, nil
+	}
+
+	return tok, nil
+}
+
+func (d Dict) Name(key Name) Name {
+	n, _ := d[key].(Name)
+	return n
+}
+
+func (d Dict) Int(key Name) (int64, bool) {
+	return toInt(d[key])
+}
+
+func toInt(obj Object) (int64, bool) {
+	switch v := obj.(type) {
+	case int64:
+		return v, true
+	case float64:
+		return int64(v), true
+	}
+	return 0, false
+}
@@ -0,0 +1,167 @@
+package pdfparser
+
+import "strings"
+
+const (
+	maxOutlineItems = 10000
+	maxOutlineDepth = 32
+	maxNameTreeKids = 10000
+)
+
+// OutlineItem is a bookmark. Page is 1-based and 0 when the destination
+// cannot be resolved to a page of the document.
+type OutlineItem struct {
+	Title    string
+	Page     int
+	Children []OutlineItem
+}
+
+// Outline returns the bookmark tree of the document. Like the metadata,
+// titles of encrypted documents cannot be read and nil is returned.
+func (d *Document) Outline() []OutlineItem {
+	if d.Encrypted {
+		return nil
+	}
+
+	root := d.reader.dict(d.catalog["Outlines"])
+	if root == nil {
+		return nil
+	}
+
+	pageIndex := make(map[Ref]int, len(d.Pages))
+	for i, page := range d.Pages {
+		pageIndex[page.Ref] = i + 1
+	}
+
+	w := &outlineWalker{
+		doc:       d,
+		pageIndex: pageIndex,
+		visited:   map[Ref]bool{},
+	}
+	return w.siblings(root["First"], 0)
+}
+
+type outlineWalker struct {
+	doc       *Document
+	pageIndex map[Ref]int
+	visited   map[Ref]bool
+	count     int
+	names     map[string]Object
+}
+
+func (w *outlineWalker) siblings(first Object, depth int) []OutlineItem {
+	if depth > maxOutlineDepth {
+		return nil
+	}
+
+	var items []OutlineItem
+	for next := first; next != nil && w.count < maxOutlineItems; {
+		// Guards against /Next and /First chains pointing back to visited items
+		if ref, ok := next.(Ref); ok {
+			if w.visited[ref] {
+				break
+			}
+			w.visited[ref] = true
+		}
+
+		node := w.doc.reader.dict(next)
+		if node == nil {
+			break
+		}
+		w.count++
+
+		item := OutlineItem{
+			Title: strings.Join(strings.Fields(w.doc.textString(node["Title"])), " "),
+			Page:  w.destinationPage(node),
+		}
+		item.Children = w.siblings(node["First"], depth+1)
+		items = append(items, item)
+
+		next = node["Next"]
+	}
+	return items
+}
+
+// destinationPage resolves /Dest or a GoTo action, following named destinations
+func (w *outlineWalker) destinationPage(node Dict) int {
+	dest := node["Dest"]
+	if dest == nil {
+		if action := w.doc.reader.dict(node["A"]); action != nil && action.Name("S") == "GoTo" {
+			dest = action["D"]
+		}
+	}
+
+	for i := 0; i < 4 && dest != nil; i++ {
+		switch v := w.doc.reader.resolveQuiet(dest).(type) {
+		case Array:
+			if len(v) == 0 {
+				return 0
+			}
+			if ref, ok := v[0].(Ref); ok {
+				return w.pageIndex[ref]
+			}
+			// Remote destinations use a 0-based page number instead of a reference
+			if n, ok := toInt(v[0]); ok && n >= 0 && int(n) < len(w.doc.Pages) {
+				return int(n) + 1
+			}
+			return 0
+		case Dict:
+			dest = v["D"]
+		case Name:
+			dest = w.namedDestination(string(v))
+		case String:
+			dest = w.namedDestination(string(v))
+		default:
+			return 0
+		}
+	}
+	return 0
+}
+
+func (w *outlineWalker) namedDestination(name string) Object {
+	if w.names == nil {
+		w.names = map[string]Object{}
+
+		// PDF 1.1 style dictionary
+		if dests := w.doc.reader.dict(w.doc.catalog["Dests"]); dests != nil {
+			for key, value := range dests {
+				w.names[string(key)] = value
+			}
+		}
+		// PDF 1.2+ name tree
+		if names := w.doc.reader.dict(w.doc.catalog["Names"]); names != nil {
+			w.collectNames(names["Dests"], 0, map[Ref]bool{})
+		}
+	}
+	return w.names[name]
+}
+
+func (w *outlineWalker) collectNames(node Object, depth int, visited map[Ref]bool) {
+	if depth > maxOutlineDepth || len(w.names) >= maxNameTreeKids {
+		return
+	}
+	if ref, ok := node.(Ref); ok {
+		if visited[ref] {
+			return
+		}
+		visited[ref] = true
+	}
+
+	dict := w.doc.reader.dict(node)
+	if dict == nil {
+		return
+	}
+
+	if pairs, ok := w.doc.reader.resolveQuiet(dict["Names"]).(Array); ok {
+		for i := 0; i+1 < len(pairs); i += 2 {
+			if key, ok := w.doc.reader.resolveQuiet(pairs[i]).(String); ok {
+				w.names[string(key)] = pairs[i+1]
+			}
+		}
+	}
+	if kids, ok := w.doc.reader.resolveQuiet(dict["Kids"]).(Array); ok {
+		for _, kid := range kids {
+			w.collectNames(kid, depth+1, visited)
+		}
+	}
+}
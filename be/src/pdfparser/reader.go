@@ -0,0 +1,568 @@
+package pdfparser
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Upper bound for a single decoded stream, protects against decompression bombs
+const maxDecodedStreamSize = 64 * 1024 * 1024
+
+type xrefEntry struct {
+	typ    int // 0 = free, 1 = in use, 2 = compressed in an object stream
+	offset int64
+	gen    int
+	stream int
+	index  int
+}
+
+type objectStream struct {
+	data    []byte
+	first   int
+	offsets map[int]int
+}
+
+type reader struct {
+	data      []byte
+	xref      map[int]xrefEntry
+	trailer   Dict
+	encrypted bool
+	cache     map[int]Object
+	objStms   map[int]*objectStream
+	resolving map[int]bool
+}
+
+func newReader(data []byte) *reader {
+	return &reader{
+		data:      data,
+		xref:      map[int]xrefEntry{},
+		cache:     map[int]Object{},
+		objStms:   map[int]*objectStream{},
+		resolving: map[int]bool{},
+	}
+}
+
+// loadXref follows the xref chain starting at offset, newest section first
+func (r *reader) loadXref(offset int64) error {
+	visited := map[int64]bool{}
+
+	for {
+		if visited[offset] {
+			return fmt.Errorf("xref chain loops back to offset %d", offset)
+		}
+		visited[offset] = true
+
+		if offset < 0 || offset >= int64(len(r.data)) {
+			return fmt.Errorf("xref offset %d is outside the file", offset)
+		}
+
+		p := &parser{data: r.data, pos: int(offset)}
+		p.skipSpace()
+
+		var trailer Dict
+		var err error
+		if bytes.HasPrefix(r.data[p.pos:], []byte("xref")) {
+			p.pos += len("xref")
+			trailer, err = r.readXrefTable(p)
+		} else {
+			trailer, err = r.readXrefStream(offset)
+		}
+		if err != nil {
+			return err
+		}
+
+		if r.trailer == nil {
+			r.trailer = trailer
+		}
+
+		// Hybrid-reference files keep compressed entries in a separate xref stream
+		if xrefStm, ok := trailer.Int("XRefStm"); ok {
+			if _, err := r.readXrefStream(xrefStm); err != nil {
+				return err
+			}
+		}
+
+		prev, ok := trailer.Int("Prev")
+		if !ok {
+			return nil
+		}
+		offset = prev
+	}
+}
+
+func (r *reader) readXrefTable(p *parser) (Dict, error) {
+	for {
+		p.skipSpace()
+		if bytes.HasPrefix(r.data[p.pos:], []byte("trailer")) {
+			p.pos += len("trailer")
+			obj, err := p.readObject()
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse trailer: %w", err)
+			}
+			trailer, ok := obj.(Dict)
+			if !ok {
+				return nil, errors.New("trailer is not a dictionary")
+			}
+			return trailer, nil
+		}
+
+		startTok, err := p.readToken()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read xref subsection: %w", err)
+		}
+		countTok, err := p.readToken()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read xref subsection: %w", err)
+		}
+		start, ok1 := startTok.(int64)
+		count, ok2 := countTok.(int64)
+		if !ok1 || !ok2 || start < 0 || count < 0 {
+			return nil, fmt.Errorf("malformed xref subsection header at offset %d", p.pos)
+		}
+		if count*18 > int64(len(r.data)-p.pos) {
+			return nil, fmt.Errorf("xref subsection of %d entries exceeds file size", count)
+		}
+
+		for i := int64(0); i < count; i++ {
+			offTok, err1 := p.readToken()
+			genTok, err2 := p.readToken()
+			kindTok, err3 := p.readToken()
+			if err1 != nil || err2 != nil || err3 != nil {
+				return nil, errors.New("truncated xref table")
+			}
+			off, ok1 := offTok.(int64)
+			gen, ok2 := genTok.(int64)
+			kind, ok3 := kindTok.(keyword)
+			if !ok1 || !ok2 || !ok3 || (kind != "n" && kind != "f") {
+				return nil, fmt.Errorf("malformed xref entry for object %d", start+i)
+			}
+
+			num := int(start + i)
+			if _, exists := r.xref[num]; exists {
+				continue
+			}
+			entry := xrefEntry{offset: off, gen: int(gen)}
+			if kind == "n" {
+				entry.typ = 1
+			}
+			r.xref[num] = entry
+		}
+	}
+}
+
+func (r *reader) readXrefStream(offset int64) (Dict, error) {
+	_, _, obj, err := r.readIndirect(offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read xref stream: %w", err)
+	}
+	stream, ok := obj.(*Stream)
+	if !ok || stream.Dict.Name("Type") != "XRef" {
+		return nil, fmt.Errorf("no xref table or xref stream at offset %d", offset)
+	}
+
+	data, err := r.decodeStream(stream)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode xref stream: %w", err)
+	}
+
+	widthsArr, ok := stream.Dict["W"].(Array)
+	if !ok || len(widthsArr) != 3 {
+		return nil, errors.New("xref stream has an invalid /W entry")
+	}
+	widths := make([]int, 3)
+	rowLen := 0
+	for i, w := range widthsArr {
+		v, ok := toInt(w)
+		if !ok || v < 0 || v > 8 {
+			return nil, errors.New("xref stream has an invalid /W entry")
+		}
+		widths[i] = int(v)
+		rowLen += int(v)
+	}
+	if rowLen == 0 {
+		return nil, errors.New("xref stream has an empty /W entry")
+	}
+
+	size, _ := stream.Dict.Int("Size")
+	index := Array{int64(0), size}
+	if idx, ok := stream.Dict["Index"].(Array); ok {
+		index = idx
+	}
+	if len(index)%2 != 0 {
+		return nil, errors.New("xref stream has an invalid /Index entry")
+	}
+
+	pos := 0
+	for i := 0; i < len(index); i += 2 {
+		start, ok1 := toInt(index[i])
+		count, ok2 := toInt(index[i+1])
+		if !ok1 || !ok2 || start < 0 || count < 0 {
+			return nil, errors.New("xref stream has an invalid /Index entry")
+		}
+
+		for j := int64(0); j < count; j++ {
+			if pos+rowLen > len(data) {
+				return nil, errors.New("xref stream is truncated")
+			}
+			fields := [3]int64{1, 0, 0}
+			for k, w := range widths {
+				if w == 0 {
+					continue
+				}
+				var v int64
+				for _, b := range data[pos : pos+w] {
+					v = v<<8 | int64(b)
+				}
+				fields[k] = v
+				pos += w
+			}
+
+			num := int(start + j)
+			if _, exists := r.xref[num]; exists {
+				continue
+			}
+			switch fields[0] {
+			case 0:
+				r.xref[num] = xrefEntry{typ: 0}
+			case 1:
+				r.xref[num] = xrefEntry{typ: 1, offset: fields[1], gen: int(fields[2])}
+			case 2:
+				r.xref[num] = xrefEntry{typ: 2, stream: int(fields[1]), index: int(fields[2])}
+			}
+		}
+	}
+
+	return stream.Dict, nil
+}
+
+// readIndirect parses "num gen obj ... endobj" at offset
+func (r *reader) readIndirect(offset int64) (int, int, Object, error) {
+	if offset < 0 || offset >= int64(len(r.data)) {
+		return 0, 0, nil, fmt.Errorf("object offset %d is outside the file", offset)
+	}
+
+	p := &parser{data: r.data, pos: int(offset)}
+	numTok, err1 := p.readToken()
+	genTok, err2 := p.readToken()
+	objTok, err3 := p.readToken()
+	num, ok1 := numTok.(int64)
+	gen, ok2 := genTok.(int64)
+	if err1 != nil || err2 != nil || err3 != nil || !ok1 || !ok2 || objTok != keyword("obj") {
+		return 0, 0, nil, fmt.Errorf("no object header at offset %d", offset)
+	}
+
+	obj, err := p.readObject()
+	if err != nil {
+		return 0, 0, nil, fmt.Errorf("failed to parse object %d: %w", num, err)
+	}
+
+	dict, isDict := obj.(Dict)
+	if !isDict {
+		return int(num), int(gen), obj, nil
+	}
+
+	save := p.pos
+	tok, err := p.readToken()
+	if err != nil || tok != keyword("stream") {
+		p.pos = save
+		return int(num), int(gen), obj, nil
+	}
+
+	// Stream data begins after a single end-of-line marker
+	if p.pos < len(r.data) && r.data[p.pos] == '\r' {
+		p.pos++
+	}
+	if p.pos < len(r.data) && r.data[p.pos] == '\n' {
+		p.pos++
+	}
+	start := p.pos
+
+	end := -1
+	if length, ok := toInt(r.resolveQuiet(dict["Length"])); ok && length >= 0 && length <= int64(len(r.data)-start) {
+		candidate := start + int(length)
+		rest := &parser{data: r.data, pos: candidate}
+		rest.skipSpace()
+		if bytes.HasPrefix(r.data[rest.pos:], []byte("endstream")) {
+			end = candidate
+		}
+	}
+	if end < 0 {
+		idx := bytes.Index(r.data[start:], []byte("endstream"))
+		if idx < 0 {
+			return 0, 0, nil, fmt.Errorf("stream of object %d has no endstream", num)
+		}
+		end = start + idx
+		for end > start && (r.data[end-1] == '\n' || r.data[end-1] == '\r') {
+			end--
+		}
+	}
+
+	return int(num), int(gen), &Stream{Dict: dict, Raw: r.data[start:end]}, nil
+}
+
+func (r *reader) object(num int) (Object, error) {
+	if obj, ok := r.cache[num]; ok {
+		return obj, nil
+	}
+	if r.resolving[num] {
+		return nil, fmt.Errorf("object %d references itself", num)
+	}
+	r.resolving[num] = true
+	defer delete(r.resolving, num)
+
+	entry, ok := r.xref[num]
+	if !ok || entry.typ == 0 {
+		// Missing and free objects are treated as null per the specification
+		return nil, nil
+	}
+
+	var obj Object
+	var err error
+	if entry.typ == 1 {
+		var gotNum int
+		gotNum, _, obj, err = r.readIndirect(entry.offset)
+		if err == nil && gotNum != num {
+			err = fmt.Errorf("xref entry for object %d points to object %d", num, gotNum)
+		}
+	} else {
+		obj, err = r.compressedObject(entry.stream, entry.index, num)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	r.cache[num] = obj
+	return obj, nil
+}
+
+func (r *reader) compressedObject(streamNum, index, num int) (Object, error) {
+	objStm, ok := r.objStms[streamNum]
+	if !ok {
+		obj, err := r.object(streamNum)
+		if err != nil {
+			return nil, err
+		}
+		stream, isStream := obj.(*Stream)
+		if !isStream {
+			return nil, fmt.Errorf("object stream %d is not a stream", streamNum)
+		}
+		data, err := r.decodeStream(stream)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode object stream %d: %w", streamNum, err)
+		}
+		first, _ := stream.Dict.Int("First")
+		n, _ := stream.Dict.Int("N")
+		if first < 0 || first > int64(len(data)) {
+			return nil, fmt.Errorf("object stream %d has an invalid /First", streamNum)
+		}
+
+		objStm = &objectStream{data: data, first: int(first), offsets: map[int]int{}}
+		p := &parser{data: data[:first]}
+		for i := int64(0); i < n; i++ {
+			numTok, err1 := p.readToken()
+			offTok, err2 := p.readToken()
+			objNum, ok1 := numTok.(int64)
+			off, ok2 := offTok.(int64)
+			if err1 != nil || err2 != nil || !ok1 || !ok2 || off < 0 || off > int64(len(data)) {
+				return nil, fmt.Errorf("object stream %d has a malformed header", streamNum)
+			}
+			objStm.offsets[int(objNum)] = int(off)
+		}
+		r.objStms[streamNum] = objStm
+	}
+
+	off, ok := objStm.offsets[num]
+	if !ok || off < 0 || objStm.first+off >= len(objStm.data) {
+		return nil, fmt.Errorf("object %d not found in object stream %d", num, streamNum)
+	}
+	p := &parser{data: objStm.data, pos: objStm.first + off}
+	return p.readObject()
+}
+
+func (r *reader) resolve(obj Object) (Object, error) {
+	for i := 0; i < 32; i++ {
+		ref, ok := obj.(Ref)
+		if !ok {
+			return obj, nil
+		}
+		var err error
+		obj, err = r.object(ref.Num)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return nil, errors.New("reference chain too long")
+}
+
+func (r *reader) resolveQuiet(obj Object) Object {
+	resolved, err := r.resolve(obj)
+	if err != nil {
+		return nil
+	}
+	return resolved
+}
+
+func (r *reader) dict(obj Object) Dict {
+	switch v := r.resolveQuiet(obj).(type) {
+	case Dict:
+		return v
+	case *Stream:
+		return v.Dict
+	}
+	return nil
+}
+
+func (r *reader) decodeStream(s *Stream) ([]byte, error) {
+	var filters []Name
+	switch f := r.resolveQuiet(s.Dict["Filter"]).(type) {
+	case Name:
+		filters = []Name{f}
+	case Array:
+		for _, item := range f {
+			if n, ok := r.resolveQuiet(item).(Name); ok {
+				filters = append(filters, n)
+			}
+		}
+	}
+
+	var params []Dict
+	switch dp := r.resolveQuiet(s.Dict["DecodeParms"]).(type) {
+	case Dict:
+		params = []Dict{dp}
+	case Array:
+		for _, item := range dp {
+			d, _ := r.resolveQuiet(item).(Dict)
+			params = append(params, d)
+		}
+	}
+
+	data := s.Raw
+	for i, filter := range filters {
+		var parms Dict
+		if i < len(params) {
+			parms = params[i]
+		}
+
+		switch filter {
+		case "FlateDecode", "Fl":
+			out, err := inflate(data)
+			if err != nil {
+				return nil, err
+			}
+			data, err = applyPredictor(out, parms)
+			if err != nil {
+				return nil, err
+			}
+		default:
+			return nil, fmt.Errorf("unsupported stream filter %s", filter)
+		}
+	}
+
+	return data, nil
+}
+
+func inflate(data []byte) ([]byte, error) {
+	zr, err := zlib.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+
+	out, err := io.ReadAll(io.LimitReader(zr, maxDecodedStreamSize+1))
+	if len(out) > maxDecodedStreamSize {
+		return nil, errors.New("decoded stream exceeds size limit")
+	}
+	// Tolerate streams with a damaged checksum or trailing garbage
+	if err != nil && len(out) == 0 {
+		return nil, err
+	}
+	return out, nil
+}
+
+func applyPredictor(data []byte, parms Dict) ([]byte, error) {
+	predictor, _ := parms.Int("Predictor")
+	if predictor < 10 {
+		return data, nil
+	}
+
+	colors, ok := parms.Int("Colors")
+	if !ok || colors < 1 {
+		colors = 1
+	}
+	bpc, ok := parms.Int("BitsPerComponent")
+	if !ok || bpc < 1 {
+		bpc = 8
+	}
+	columns, ok := parms.Int("Columns")
+	if !ok || columns < 1 {
+		columns = 1
+	}
+	// Bounded before multiplying, a row can never be longer than the data
+	if colors > 32 || bpc > 16 || columns > int64(len(data))*8 {
+		return nil, errors.New("invalid predictor parameters")
+	}
+
+	bpp := int((colors*bpc + 7) / 8)
+	rowLen := int((colors*bpc*columns + 7) / 8)
+	if rowLen <= 0 {
+		return nil, errors.New("invalid predictor parameters")
+	}
+
+	out := make([]byte, 0, len(data))
+	prev := make([]byte, rowLen)
+	for pos := 0; pos+1+rowLen <= len(data); pos += 1 + rowLen {
+		filterType := data[pos]
+		row := make([]byte, rowLen)
+		copy(row, data[pos+1:pos+1+rowLen])
+
+		for i := 0; i < rowLen; i++ {
+			var left, upLeft byte
+			if i >= bpp {
+				left = row[i-bpp]
+				upLeft = prev[i-bpp]
+			}
+			up := prev[i]
+
+			switch filterType {
+			case 0:
+			case 1:
+				row[i] += left
+			case 2:
+				row[i] += up
+			case 3:
+				row[i] += byte((int(left) + int(up)) / 2)
+			case 4:
+				row[i] += paeth(left, up, upLeft)
+			default:
+				return nil, fmt.Errorf("unknown PNG predictor %d", filterType)
+			}
+		}
+
+		out = append(out, row...)
+		prev = row
+	}
+
+	return out, nil
+}
+
+func paeth(a, b, c byte) byte {
+	p := int(a) + int(b) - int(c)
+	pa, pb, pc := abs(p-int(a)), abs(p-int(b)), abs(p-int(c))
+	if pa <= pb && pa <= pc {
+		return a
+	}
+	if pb <= pc {
+		return b
+	}
+	return c
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
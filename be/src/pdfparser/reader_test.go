@@ -0,0 +1,310 @@
+package pdfparser
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// buildPDF lays out numbered objects after a header and finishes the file
+// with the given tail, which receives the offset of every object
+func buildPDF(objects []string, tail func(offsets []int, end int) string) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.7\n")
+
+	offsets := make([]int, len(objects)+1)
+	for i, obj := range objects {
+		offsets[i+1] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+
+	buf.WriteString(tail(offsets, buf.Len()))
+	return buf.Bytes()
+}
+
+// xrefTable writes a classic cross-reference table and trailer
+func xrefTable(trailer string) func(offsets []int, end int) string {
+	return func(offsets []int, end int) string {
+		var b bytes.Buffer
+		fmt.Fprintf(&b, "xref\n0 %d\n0000000000 65535 f \n", len(offsets))
+		for _, off := range offsets[1:] {
+			fmt.Fprintf(&b, "%010d 00000 n \n", off)
+		}
+		fmt.Fprintf(&b, "trailer\n%s\nstartxref\n%d\n%%%%EOF\n", trailer, end)
+		return b.String()
+	}
+}
+
+var simpleObjects = []string{
+	"<< /Type /Catalog /Pages 2 0 R >>",
+	"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+	"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] >>",
+}
+
+func deflate(t *testing.T, data []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := zlib.NewWriter(&buf)
+	if _, err := w.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// pngUp encodes rows with the PNG "Up" filter, as used by /Predictor 12
+func pngUp(rows [][]byte) []byte {
+	var out []byte
+	prev := make([]byte, len(rows[0]))
+	for _, row := range rows {
+		out = append(out, 2)
+		for i, b := range row {
+			out = append(out, b-prev[i])
+		}
+		prev = row
+	}
+	return out
+}
+
+func errorCode(err error) string {
+	var pdfErr *Error
+	if errors.As(err, &pdfErr) {
+		return pdfErr.Code
+	}
+	return ""
+}
+
+func TestParseValidXrefTable(t *testing.T) {
+	data := buildPDF(simpleObjects, xrefTable("<< /Size 4 /Root 1 0 R >>"))
+
+	doc, err := Parse(data)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if doc.PageCount != 1 {
+		t.Errorf("PageCount = %d, want 1", doc.PageCount)
+	}
+}
+
+func TestParseMalformedXref(t *testing.T) {
+	tests := []struct {
+		name string
+		tail func(offsets []int, end int) string
+	}{
+		{
+			name: "startxref outside the file",
+			tail: func(offsets []int, end int) string {
+				return "startxref\n999999\n%%EOF\n"
+			},
+		},
+		{
+			name: "negative startxref",
+			tail: func(offsets []int, end int) string {
+				return "startxref\n-5\n%%EOF\n"
+			},
+		},
+		{
+			name: "subsection header is not numeric",
+			tail: func(offsets []int, end int) string {
+				return fmt.Sprintf("xref\nzero four\ntrailer\n<< /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", end)
+			},
+		},
+		{
+			name: "subsection larger than the file",
+			tail: func(offsets []int, end int) string {
+				return fmt.Sprintf("xref\n0 9223372036854775807\ntrailer\n<< /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", end)
+			},
+		},
+		{
+			name: "truncated table",
+			tail: func(offsets []int, end int) string {
+				return fmt.Sprintf("xref\n0 4\n0000000000 65535 f \n0000000009 00000\nstartxref\n%d\n%%%%EOF\n", end)
+			},
+		},
+		{
+			name: "unknown entry kind",
+			tail: func(offsets []int, end int) string {
+				return fmt.Sprintf("xref\n0 1\n0000000000 65535 x \ntrailer\n<< /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", end)
+			},
+		},
+		{
+			name: "prev chain loops",
+			tail: func(offsets []int, end int) string {
+				return fmt.Sprintf("xref\n0 1\n0000000000 65535 f \ntrailer\n<< /Root 1 0 R /Prev %d >>\nstartxref\n%d\n%%%%EOF\n", end, end)
+			},
+		},
+		{
+			name: "neither table nor stream",
+			tail: func(offsets []int, end int) string {
+				return fmt.Sprintf("startxref\n%d\n%%%%EOF\n", offsets[1])
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(buildPDF(simpleObjects, tt.tail))
+			if err == nil {
+				t.Fatal("Parse() succeeded, want an error")
+			}
+			if code := errorCode(err); code != CodeInvalidXref {
+				t.Errorf("error code = %q, want %q (%v)", code, CodeInvalidXref, err)
+			}
+		})
+	}
+}
+
+// objectStreamPDF stores the page tree in an object stream with the given
+// header and indexes it with a predictor-encoded xref stream
+func objectStreamPDF(t *testing.T, header func(second int) string) []byte {
+	t.Helper()
+
+	pages := "<< /Type /Pages /Kids [3 0 R] /Count 1 >>"
+	page := "<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] >>"
+	body := pages + " " + page
+	head := header(len(pages) + 1)
+	content := head + body
+
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		fmt.Sprintf("<< /Type /ObjStm /N 2 /First %d /Length %d >>\nstream\n%s\nendstream", len(head), len(content), content),
+	}
+
+	return buildPDF([]string{objects[0], "null", "null", objects[1]}, func(offsets []int, end int) string {
+		// Rows of /W [1 2 1]: type, offset or stream number, generation or index
+		rows := [][]byte{
+			{0, 0, 0, 0},
+			{1, byte(offsets[1] >> 8), byte(offsets[1]), 0},
+			{2, 0, 4, 0},
+			{2, 0, 4, 1},
+			{1, byte(offsets[4] >> 8), byte(offsets[4]), 0},
+			{1, byte(end >> 8), byte(end), 0},
+		}
+		stream := deflate(t, pngUp(rows))
+		return fmt.Sprintf("5 0 obj\n<< /Type /XRef /Size 6 /W [1 2 1] /Root 1 0 R /Filter /FlateDecode /DecodeParms << /Predictor 12 /Columns 4 >> /Length %d >>\nstream\n%s\nendstream\nendobj\nstartxref\n%d\n%%%%EOF\n",
+			len(stream), stream, end)
+	})
+}
+
+func TestParseObjectStream(t *testing.T) {
+	data := objectStreamPDF(t, func(second int) string {
+		return fmt.Sprintf("2 0 3 %d\n", second)
+	})
+
+	doc, err := Parse(data)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if doc.PageCount != 1 {
+		t.Errorf("PageCount = %d, want 1", doc.PageCount)
+	}
+}
+
+func TestParseMalformedObjectStream(t *testing.T) {
+	tests := []struct {
+		name   string
+		header func(second int) string
+	}{
+		{"negative offset", func(int) string { return "2 -100 3 -200\n" }},
+		{"offset past the end", func(int) string { return "2 0 3 100000\n" }},
+		{"non-numeric header", func(int) string { return "2 zero 3 one\n" }},
+		{"short header", func(int) string { return "2 0\n" }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(objectStreamPDF(t, tt.header))
+			if err == nil {
+				t.Fatal("Parse() succeeded, want an error")
+			}
+		})
+	}
+}
+
+func TestCompressedObjectNegativeOffset(t *testing.T) {
+	r := newReader(nil)
+	r.objStms[4] = &objectStream{data: []byte("<< >>"), first: 0, offsets: map[int]int{2: -3}}
+
+	if _, err := r.compressedObject(4, 0, 2); err == nil {
+		t.Fatal("compressedObject() succeeded, want an error")
+	}
+}
+
+func TestParserNegativeOffset(t *testing.T) {
+	p := &parser{data: []byte("1 0 obj"), pos: -1}
+	if _, err := p.readToken(); err == nil {
+		t.Fatal("readToken() succeeded, want an error")
+	}
+}
+
+func TestApplyPredictor(t *testing.T) {
+	rows := [][]byte{{1, 2, 3}, {4, 6, 8}, {4, 6, 9}}
+	want := []byte{1, 2, 3, 4, 6, 8, 4, 6, 9}
+
+	tests := []struct {
+		name  string
+		data  []byte
+		parms Dict
+		want  []byte
+	}{
+		{"no predictor", []byte{9, 9}, nil, []byte{9, 9}},
+		{"tiff predictor is passed through", []byte{9, 9}, Dict{"Predictor": int64(2)}, []byte{9, 9}},
+		{"png up", pngUp(rows), Dict{"Predictor": int64(12), "Columns": int64(3)}, want},
+		{"png sub", []byte{1, 1, 1, 1}, Dict{"Predictor": int64(11), "Columns": int64(3)}, []byte{1, 2, 3}},
+		{"png average", []byte{3, 2, 2, 2}, Dict{"Predictor": int64(13), "Columns": int64(3)}, []byte{2, 3, 3}},
+		{"png paeth", []byte{4, 1, 1, 1}, Dict{"Predictor": int64(14), "Columns": int64(3)}, []byte{1, 2, 3}},
+		{"trailing partial row is dropped", append(pngUp(rows), 2, 1), Dict{"Predictor": int64(12), "Columns": int64(3)}, want},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := applyPredictor(tt.data, tt.parms)
+			if err != nil {
+				t.Fatalf("applyPredictor() error = %v", err)
+			}
+			if !bytes.Equal(got, tt.want) {
+				t.Errorf("applyPredictor() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyPredictorInvalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		data  []byte
+		parms Dict
+	}{
+		{"unknown row filter", []byte{7, 1, 2, 3}, Dict{"Predictor": int64(12), "Columns": int64(3)}},
+		{"huge columns", []byte{2, 1, 2, 3}, Dict{"Predictor": int64(12), "Columns": int64(1) << 62}},
+		{"huge colors", []byte{2, 1, 2, 3}, Dict{"Predictor": int64(12), "Colors": int64(1) << 40}},
+		{"huge bits per component", []byte{2, 1, 2, 3}, Dict{"Predictor": int64(12), "BitsPerComponent": int64(1) << 40}},
+		{"overflowing product", []byte{2, 1, 2, 3}, Dict{"Predictor": int64(12), "Colors": int64(32), "BitsPerComponent": int64(16), "Columns": int64(1) << 60}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := applyPredictor(tt.data, tt.parms); err == nil {
+				t.Fatal("applyPredictor() succeeded, want an error")
+			}
+		})
+	}
+}
+
+func TestReadIndirectHugeLength(t *testing.T) {
+	data := []byte("1 0 obj\n<< /Length 9223372036854775807 >>\nstream\nabc\nendstream\nendobj\n")
+	r := newReader(data)
+
+	_, _, obj, err := r.readIndirect(0)
+	if err != nil {
+		t.Fatalf("readIndirect() error = %v", err)
+	}
+	stream, ok := obj.(*Stream)
+	if !ok || string(stream.Raw) != "abc" {
+		t.Errorf("readIndirect() = %#v, want stream with data abc", obj)
+	}
+}
@@ -0,0 +1,21 @@
+package router
+
+import (
+	"app/src/controller"
+	"app/src/middleware"
+	"app/src/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func AdminRoutes(v1 fiber.Router, pdfService service.PDFService, summaryCache service.SummaryCacheService, searchService service.SearchService, chunkService service.ChunkService, entityService service.EntityService) {
+	adminController := controller.NewAdminController(pdfService, summaryCache, searchService, chunkService, entityService)
+
+	admin := v1.Group("/admin", middleware.Admin())
+
+	admin.Delete("/summary-cache", adminController.InvalidateSummaryCache)
+	admin.Post("/search/reindex", adminController.ReindexSearch)
+	admin.Post("/search/embeddings", adminController.ReindexEmbeddings)
+	admin.Post("/entities/reindex", adminController.ReindexEntities)
+	admin.Post("/outlines/reindex", adminController.ReindexOutlines)
+}
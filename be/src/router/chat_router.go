@@ -0,0 +1,18 @@
+package router
+
+import (
+	"app/src/controller"
+	"app/src/middleware"
+	"app/src/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func ChatRoutes(v1 fiber.Router, chatService service.ChatService) {
+	chatController := controller.NewChatController(chatService)
+
+	v1.Post("/pdfs/:id/chat", middleware.Auth(), chatController.Ask)
+	v1.Get("/pdfs/:id/chats", middleware.Auth(), chatController.GetConversations)
+	v1.Get("/chats/:id", middleware.Auth(), chatController.GetConversation)
+	v1.Delete("/chats/:id", middleware.Auth(), chatController.DeleteConversation)
+}
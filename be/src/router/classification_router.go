@@ -0,0 +1,24 @@
+package router
+
+import (
+	"app/src/controller"
+	"app/src/middleware"
+	"app/src/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func ClassificationRoutes(v1 fiber.Router, classificationService service.ClassificationService) {
+	classificationController := controller.NewClassificationController(classificationService)
+
+	v1.Get("/categories", classificationController.GetCategories)
+	v1.Put("/pdfs/:id/category", middleware.Auth(), classificationController.SetCategory)
+	v1.Post("/pdfs/:id/classify", middleware.Auth(), classificationController.Classify)
+
+	admin := v1.Group("/admin/classification", middleware.Admin())
+
+	admin.Get("/rules", classificationController.GetRules)
+	admin.Put("/rules/:category", classificationController.UpsertRule)
+	admin.Delete("/rules/:category", classificationController.DeleteRule)
+	admin.Post("/retrain", classificationController.Retrain)
+}
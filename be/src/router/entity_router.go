@@ -0,0 +1,16 @@
+package router
+
+import (
+	"app/src/controller"
+	"app/src/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func EntityRoutes(v1 fiber.Router, entityService service.EntityService) {
+	entityController := controller.NewEntityController(entityService)
+
+	v1.Get("/entities", entityController.GetTop)
+	v1.Get("/entities/:id/pdfs", entityController.GetEntityPDFs)
+	v1.Get("/pdfs/:id/entities", entityController.GetPDFEntities)
+}
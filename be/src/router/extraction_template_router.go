@@ -0,0 +1,27 @@
+package router
+
+import (
+	"app/src/controller"
+	"app/src/middleware"
+	"app/src/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func ExtractionTemplateRoutes(v1 fiber.Router, templateService service.ExtractionTemplateService, idempotencyService service.IdempotencyService) {
+	templateController := controller.NewExtractionTemplateController(templateService)
+	idempotent := middleware.Idempotency(idempotencyService)
+
+	templates := v1.Group("/extraction-templates", middleware.Auth())
+
+	templates.Post("/", templateController.CreateTemplate)
+	templates.Get("/", templateController.GetTemplates)
+	templates.Get("/:id", templateController.GetTemplate)
+	templates.Put("/:id", templateController.UpdateTemplate)
+	templates.Delete("/:id", templateController.DeleteTemplate)
+	templates.Get("/:id/results", templateController.GetTemplateResults)
+
+	v1.Post("/pdfs/:id/extract", middleware.Auth(), idempotent, templateController.Run)
+	v1.Get("/pdfs/:id/extractions", middleware.Auth(), templateController.GetPDFResults)
+	v1.Get("/extractions/:id", middleware.Auth(), templateController.GetResult)
+}
@@ -2,26 +2,40 @@ package router
 
 import (
 	"app/src/controller"
+	"app/src/middleware"
 	"app/src/service"
 
 	"github.com/gofiber/fiber/v2"
 )
 
-func PDFRoutes(v1 fiber.Router, pdfService service.PDFService, summaryService service.SummaryService) {
+func PDFRoutes(v1 fiber.Router, pdfService service.PDFService, summaryService service.SummaryService, idempotencyService service.IdempotencyService) {
 	pdfController := controller.NewPDFController(pdfService, summaryService)
+	idempotent := middleware.Idempotency(idempotencyService)
 
 	pdfs := v1.Group("/pdfs")
 
-	pdfs.Post("/upload", pdfController.Upload)
+	pdfs.Post("/upload", idempotent, pdfController.Upload)
 	pdfs.Get("/", pdfController.GetAllPDFs)
 	pdfs.Get("/:id", pdfController.GetPDF)
 	pdfs.Delete("/:id", pdfController.DeletePDF)
-	pdfs.Post("/:id/generate", pdfController.GenerateSummary)
+	pdfs.Get("/:id/outline", pdfController.GetOutline)
+	pdfs.Get("/:id/file", middleware.SignedURLOrAuth(), pdfController.GetFile)
+	pdfs.Post("/:id/file/sign", middleware.Auth(), pdfController.SignFileURL)
+	pdfs.Post("/:id/unlock", pdfController.UnlockPDF)
+	pdfs.Post("/:id/generate", idempotent, pdfController.GenerateSummary)
 	pdfs.Get("/:id/summaries", pdfController.GetSummaries)
 
+	v1.Post("/summaries/synthesis", idempotent, pdfController.GenerateSynthesis)
+
 	summary := v1.Group("/summary")
 
 	summary.Get("/:id", pdfController.GetSummaryByID)
 	summary.Put("/:id", pdfController.UpdateSummary)
 	summary.Delete("/:id", pdfController.DeleteSummary)
+	summary.Get("/:id/revisions", pdfController.GetRevisions)
+	summary.Get("/:id/revisions/:rev", pdfController.GetRevision)
+	summary.Post("/:id/revisions/:rev/restore", pdfController.RestoreRevision)
+	summary.Get("/:id/diff", pdfController.DiffRevisions)
+	summary.Get("/:id/diff/:other", pdfController.DiffSummaries)
+	summary.Get("/:id/attributions", pdfController.GetSummaryAttributions)
 }
\ No newline at end of file
@@ -12,12 +12,44 @@ import (
 func Routes(app *fiber.App, db *gorm.DB) {
 	validate := validation.Validator()
 
-	pdfService := service.NewPDFService(db, validate)
-	summaryService := service.NewSummaryService(db, validate, )
+	scanner := service.NewNoopScanner()
+	if config.ClamAVAddress != "" {
+		scanner = service.NewClamAVScanner(config.ClamAVAddress, config.ClamAVTimeout)
+	}
+
+	ocrEngine := service.NewNoopOCR()
+	if config.OCREngine == "tesseract" {
+		ocrEngine = service.NewTesseractOCR(config.TesseractPath, config.PdftoppmPath, config.QpdfPath, config.OCRLanguages, config.OCRDPI)
+	}
+
+	embedder := service.NewHashEmbedder(config.EmbeddingDimensions)
+	if config.EmbeddingProvider == "openai" {
+		embedder = service.NewOpenAIEmbedder(config.EmbeddingURL, config.EmbeddingAPIKey, config.EmbeddingModel)
+	}
+
+	chunkService := service.NewChunkService(db, embedder, config.SemanticMinScore)
+	extractionService := service.NewExtractionService(db, ocrEngine, chunkService)
+	classificationService := service.NewClassificationService(db, extractionService)
+	pdfService := service.NewPDFService(db, validate, extractionService, scanner, classificationService)
+	summaryCache := service.NewSummaryCacheService(db)
+	entityService := service.NewEntityService(db, extractionService)
+	summaryService := service.NewSummaryService(db, validate, extractionService, summaryCache, entityService)
+	shareService := service.NewShareService(db, validate)
+	idempotencyService := service.NewIdempotencyService(db, config.IdempotencyTTL)
+	searchService := service.NewSearchService(db, chunkService)
+	chatService := service.NewChatService(db, extractionService, chunkService)
+	templateService := service.NewExtractionTemplateService(db, extractionService)
 
 	v1 := app.Group("/v1")
 
-	PDFRoutes(v1, pdfService, summaryService)
+	PDFRoutes(v1, pdfService, summaryService, idempotencyService)
+	ShareRoutes(v1, shareService)
+	AdminRoutes(v1, pdfService, summaryCache, searchService, chunkService, entityService)
+	SearchRoutes(v1, searchService)
+	ChatRoutes(v1, chatService)
+	ExtractionTemplateRoutes(v1, templateService, idempotencyService)
+	EntityRoutes(v1, entityService)
+	ClassificationRoutes(v1, classificationService)
 	// TODO: add another routes here...
 
 	if !config.IsProd {
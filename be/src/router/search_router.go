@@ -0,0 +1,14 @@
+package router
+
+import (
+	"app/src/controller"
+	"app/src/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func SearchRoutes(v1 fiber.Router, searchService service.SearchService) {
+	searchController := controller.NewSearchController(searchService)
+
+	v1.Get("/search", searchController.Search)
+}
@@ -0,0 +1,23 @@
+package router
+
+import (
+	"app/src/controller"
+	"app/src/middleware"
+	"app/src/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func ShareRoutes(v1 fiber.Router, shareService service.ShareService) {
+	shareController := controller.NewShareController(shareService)
+
+	v1.Post("/summary/:id/share", middleware.Auth(), shareController.CreateShare)
+	v1.Get("/pdfs/:id/shares", middleware.Auth(), shareController.GetShares)
+	v1.Delete("/shares/:id", middleware.Auth(), shareController.RevokeShare)
+
+	public := v1.Group("/public")
+	shareLimiter := middleware.ShareLimiter()
+
+	public.Get("/shares/:token", shareLimiter, middleware.ErrorStatus, shareController.ViewShare)
+	public.Post("/shares/:token", shareLimiter, middleware.ErrorStatus, shareController.ViewShare)
+}
@@ -0,0 +1,396 @@
+package service
+
+import (
+	"app/src/config"
+	"app/src/model"
+	"app/src/utils"
+	"app/src/validation"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const (
+	chatPassages         = 6
+	chatHistoryMessages  = 10
+	chatTitleRunes       = 80
+	citationExcerptRunes = 200
+)
+
+var (
+	ErrConversationNotFound = errors.New("conversation not found")
+	ErrDocumentNotIndexed   = errors.New("document text has not been extracted yet")
+	ErrAIService            = errors.New("AI service error")
+)
+
+// citationPattern matches passage references like [2] or [1, 3]
+var citationPattern = regexp.MustCompile(`\[(\d+(?:\s*,\s*\d+)*)\]`)
+
+type ChatAnswer struct {
+	Conversation *model.ChatConversation `json:"conversation"`
+	Question     *model.ChatMessage      `json:"question"`
+	Answer       *model.ChatMessage      `json:"answer"`
+}
+
+type ChatService interface {
+	Ask(ctx context.Context, pdfID uuid.UUID, payload validation.AskQuestion, createdBy string) (*ChatAnswer, error)
+	GetConversations(ctx context.Context, pdfID uuid.UUID, owner string) ([]model.ChatConversation, error)
+	GetConversation(ctx context.Context, id uuid.UUID, owner string) (*model.ChatConversation, error)
+	DeleteConversation(ctx context.Context, id uuid.UUID, owner string) error
+}
+
+type chatService struct {
+	Log               *logrus.Logger
+	DB                *gorm.DB
+	ExtractionService ExtractionService
+	ChunkService      ChunkService
+}
+
+func NewChatService(db *gorm.DB, extractionService ExtractionService, chunkService ChunkService) ChatService {
+	return &chatService{
+		Log:               utils.Log,
+		DB:                db,
+		ExtractionService: extractionService,
+		ChunkService:      chunkService,
+	}
+}
+
+type answerPassage struct {
+	Ref  int    `json:"ref"`
+	Page int    `json:"page"`
+	Text string `json:"text"`
+}
+
+type answerMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type answerRequest struct {
+	Question string          `json:"question"`
+	Passages []answerPassage `json:"passages"`
+	History  []answerMessage `json:"history"`
+	Language string          `json:"language"`
+}
+
+type answerResponse struct {
+	Answer string `json:"answer"`
+	Model  string `json:"model"`
+}
+
+// Ask answers a question about a PDF from its most relevant chunks. The
+// question and the answer are only stored once the AI service has answered.
+func (s *chatService) Ask(ctx context.Context, pdfID uuid.UUID, payload validation.AskQuestion, createdBy string) (*ChatAnswer, error) {
+	var pdf model.PDF
+	if err := s.DB.WithContext(ctx).Select("id").First(&pdf, "id = ?", pdfID).Error; err != nil {
+		return nil, err
+	}
+
+	conversation := &model.ChatConversation{
+		ID:        uuid.New(),
+		PDFID:     pdfID,
+		Title:     truncateRunes(strings.Join(strings.Fields(payload.Question), " "), chatTitleRunes),
+		Language:  payload.Language,
+		CreatedBy: createdBy,
+		CreatedAt: time.Now(),
+	}
+	isNew := payload.ConversationID == nil
+	if !isNew {
+		if err := s.DB.WithContext(ctx).
+			Where("id = ? AND pdf_id = ? AND created_by = ?", *payload.ConversationID, pdfID, createdBy).
+			First(conversation).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, ErrConversationNotFound
+			}
+			return nil, err
+		}
+	}
+	if conversation.Language == "" {
+		conversation.Language = "EN"
+	}
+	if payload.Language != "" {
+		conversation.Language = payload.Language
+	}
+
+	var history []model.ChatMessage
+	if !isNew {
+		if err := s.DB.WithContext(ctx).
+			Where("conversation_id = ?", conversation.ID).
+			Order("created_at DESC").
+			Limit(chatHistoryMessages).
+			Find(&history).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	// Follow-up questions often refer to the previous one ("and in 2023?")
+	retrievalQuery := payload.Question
+	for _, message := range history {
+		if message.Role == "user" {
+			retrievalQuery = message.Content + "\n" + payload.Question
+			break
+		}
+	}
+
+	chunks, err := s.retrieve(ctx, pdfID, retrievalQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	request := answerRequest{
+		Question: payload.Question,
+		Language: conversation.Language,
+	}
+	for i, chunk := range chunks {
+		request.Passages = append(request.Passages, answerPassage{Ref: i + 1, Page: chunk.PageNumber, Text: chunk.Content})
+	}
+	for i := len(history) - 1; i >= 0; i-- {
+		request.History = append(request.History, answerMessage{Role: history[i].Role, Content: history[i].Content})
+	}
+
+	answer, err := s.callAnswer(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	citationsJSON, _ := json.Marshal(extractCitations(answer.Answer, chunks))
+	citations := json.RawMessage(citationsJSON)
+
+	now := time.Now()
+	question := &model.ChatMessage{
+		ConversationID: conversation.ID,
+		Role:           "user",
+		Content:        payload.Question,
+		CreatedAt:      now,
+	}
+	reply := &model.ChatMessage{
+		ConversationID: conversation.ID,
+		Role:           "assistant",
+		Content:        answer.Answer,
+		Citations:      &citations,
+		Model:          answer.Model,
+		// Keeps the answer after the question when both are ordered by time
+		CreatedAt: now.Add(time.Millisecond),
+	}
+	conversation.UpdatedAt = now
+
+	err = s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if isNew {
+			if err := tx.Create(conversation).Error; err != nil {
+				return err
+			}
+		} else if err := tx.Model(&model.ChatConversation{}).
+			Where("id = ?", conversation.ID).
+			Updates(map[string]interface{}{
+				"language":   conversation.Language,
+				"updated_at": now,
+			}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Create(question).Error; err != nil {
+			return err
+		}
+		return tx.Create(reply).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.createProcessingLog(ctx, "pdf", pdfID, "chat", "success", "Question answered", map[string]interface{}{
+		"conversation_id": conversation.ID,
+		"passages":        len(chunks),
+		"model":           answer.Model,
+	})
+
+	return &ChatAnswer{
+		Conversation: conversation,
+		Question:     question,
+		Answer:       reply,
+	}, nil
+}
+
+// retrieve returns the chunks closest to the query. PDFs extracted before the
+// chunk index existed, or whose indexing is still running, are indexed first.
+func (s *chatService) retrieve(ctx context.Context, pdfID uuid.UUID, query string) ([]ChunkMatch, error) {
+	chunks, err := s.ChunkService.Nearest(ctx, query, pdfID, chatPassages)
+	if err != nil || len(chunks) > 0 {
+		return chunks, err
+	}
+
+	pages, err := s.ExtractionService.GetPages(ctx, pdfID)
+	if err != nil {
+		return nil, err
+	}
+	if len(pages) == 0 {
+		return nil, ErrDocumentNotIndexed
+	}
+	if err := s.ChunkService.IndexPages(ctx, pdfID, pages); err != nil {
+		return nil, err
+	}
+
+	chunks, err = s.ChunkService.Nearest(ctx, query, pdfID, chatPassages)
+	if err != nil {
+		return nil, err
+	}
+	if len(chunks) == 0 {
+		return nil, ErrDocumentNotIndexed
+	}
+	return chunks, nil
+}
+
+func (s *chatService) callAnswer(ctx context.Context, request answerRequest) (*answerResponse, error) {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", config.MLServiceURL+"/answer", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := &http.Client{
+		Timeout: 2 * time.Minute,
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrAIService, err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to read response: %v", ErrAIService, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		errMsg := strings.TrimSpace(string(respBody))
+		if errMsg == "" {
+			errMsg = fmt.Sprintf("status %d", resp.StatusCode)
+		}
+		return nil, fmt.Errorf("%w: %s", ErrAIService, errMsg)
+	}
+
+	var parsed answerResponse
+	if err := json.Unmarshal(respBody, &parsed); err != nil || strings.TrimSpace(parsed.Answer) == "" {
+		return nil, fmt.Errorf("%w: invalid answer response", ErrAIService)
+	}
+	return &parsed, nil
+}
+
+// extractCitations maps the passage numbers cited in the answer back to their
+// chunks, in order of first citation
+func extractCitations(answer string, chunks []ChunkMatch) []model.ChatCitation {
+	citations := []model.ChatCitation{}
+	seen := map[int]bool{}
+
+	for _, match := range citationPattern.FindAllStringSubmatch(answer, -1) {
+		for _, part := range strings.Split(match[1], ",") {
+			ref, err := strconv.Atoi(strings.TrimSpace(part))
+			if err != nil || ref < 1 || ref > len(chunks) || seen[ref] {
+				continue
+			}
+			seen[ref] = true
+
+			chunk := chunks[ref-1]
+			citations = append(citations, model.ChatCitation{
+				Ref:        ref,
+				PageNumber: chunk.PageNumber,
+				ChunkID:    chunk.ChunkID,
+				Excerpt:    truncateRunes(chunk.Content, citationExcerptRunes),
+			})
+		}
+	}
+	return citations
+}
+
+func truncateRunes(text string, max int) string {
+	runes := []rune(text)
+	if len(runes) <= max {
+		return text
+	}
+	return strings.TrimSpace(string(runes[:max-1])) + "…"
+}
+
+// ownedBy limits a query to the conversations of owner, an empty owner is
+// used for admins and sees every conversation
+func ownedBy(db *gorm.DB, owner string) *gorm.DB {
+	if owner == "" {
+		return db
+	}
+	return db.Where("created_by = ?", owner)
+}
+
+func (s *chatService) GetConversations(ctx context.Context, pdfID uuid.UUID, owner string) ([]model.ChatConversation, error) {
+	conversations := []model.ChatConversation{}
+	if err := ownedBy(s.DB.WithContext(ctx), owner).
+		Where("pdf_id = ?", pdfID).
+		Order("updated_at DESC").
+		Find(&conversations).Error; err != nil {
+		return nil, err
+	}
+	return conversations, nil
+}
+
+func (s *chatService) GetConversation(ctx context.Context, id uuid.UUID, owner string) (*model.ChatConversation, error) {
+	var conversation model.ChatConversation
+	if err := ownedBy(s.DB.WithContext(ctx), owner).
+		Preload("Messages", func(db *gorm.DB) *gorm.DB {
+			return db.Order("created_at ASC")
+		}).
+		First(&conversation, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrConversationNotFound
+		}
+		return nil, err
+	}
+	return &conversation, nil
+}
+
+func (s *chatService) DeleteConversation(ctx context.Context, id uuid.UUID, owner string) error {
+	result := ownedBy(s.DB.WithContext(ctx), owner).Delete(&model.ChatConversation{}, "id = ?", id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrConversationNotFound
+	}
+	return nil
+}
+
+func (s *chatService) createProcessingLog(ctx context.Context, entityType string, entityID uuid.UUID, action, status, message string, metadata map[string]interface{}) {
+	var metaJSON *json.RawMessage
+
+	if metadata != nil {
+		b, _ := json.Marshal(metadata)
+		raw := json.RawMessage(b)
+		metaJSON = &raw
+	}
+
+	log := &model.ProcessingLog{
+		EntityType: entityType,
+		EntityID:   entityID,
+		Action:     action,
+		Status:     status,
+		Message:    message,
+		Metadata:   metaJSON,
+		CreatedAt:  time.Now(),
+	}
+
+	if err := s.DB.WithContext(ctx).Create(log).Error; err != nil {
+		s.Log.WithError(err).Error("Failed to create processing log")
+	}
+}
@@ -0,0 +1,316 @@
+package service
+
+import (
+	"app/src/model"
+	"app/src/utils"
+	"container/heap"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const (
+	chunkRunes        = 1000
+	chunkOverlapRunes = 200
+)
+
+type ChunkMatch struct {
+	ChunkID    uuid.UUID
+	PDFID      uuid.UUID
+	PageNumber int
+	ChunkIndex int
+	Content    string
+	Score      float64
+}
+
+type ChunkService interface {
+	IndexPages(ctx context.Context, pdfID uuid.UUID, pages []model.PDFPage) error
+	Search(ctx context.Context, query string, pdfID *uuid.UUID, limit, offset int) ([]ChunkMatch, int64, error)
+	Nearest(ctx context.Context, query string, pdfID uuid.UUID, k int) ([]ChunkMatch, error)
+	Reindex(ctx context.Context) (int, error)
+}
+
+type chunkService struct {
+	Log      *logrus.Logger
+	DB       *gorm.DB
+	Embedder Embedder
+	MinScore float64
+}
+
+func NewChunkService(db *gorm.DB, embedder Embedder, minScore float64) ChunkService {
+	return &chunkService{
+		Log:      utils.Log,
+		DB:       db,
+		Embedder: embedder,
+		MinScore: minScore,
+	}
+}
+
+// IndexPages splits the pages into overlapping chunks, embeds them and
+// replaces the chunks of the PDF for the current embedding model
+func (s *chunkService) IndexPages(ctx context.Context, pdfID uuid.UUID, pages []model.PDFPage) error {
+	var chunks []model.PDFChunk
+	var texts []string
+	for _, page := range pages {
+		for i, text := range chunkText(page.Content(), chunkRunes, chunkOverlapRunes) {
+			chunks = append(chunks, model.PDFChunk{
+				PDFID:      pdfID,
+				PageNumber: page.PageNumber,
+				ChunkIndex: i,
+				Content:    text,
+				Model:      s.Embedder.Model(),
+				CreatedAt:  time.Now(),
+			})
+			texts = append(texts, text)
+		}
+	}
+
+	if len(texts) > 0 {
+		vectors, err := s.Embedder.Embed(ctx, texts)
+		if err != nil {
+			return fmt.Errorf("failed to embed chunks: %w", err)
+		}
+		for i := range chunks {
+			chunks[i].Embedding = encodeVector(vectors[i])
+		}
+	}
+
+	return s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Extraction and chat both index in the background, one run at a time
+		if err := lockPDF(tx, "pdf_chunks", pdfID); err != nil {
+			return err
+		}
+		if err := tx.Where("pdf_id = ? AND model = ?", pdfID, s.Embedder.Model()).Delete(&model.PDFChunk{}).Error; err != nil {
+			return err
+		}
+		if len(chunks) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(&chunks, 100).Error
+	})
+}
+
+// Search embeds the query and ranks the stored chunks by cosine similarity.
+// Vectors are compared in Go, only the best offset+limit chunks are kept in
+// memory. The total counts the chunks scoring at least MinScore.
+func (s *chunkService) Search(ctx context.Context, query string, pdfID *uuid.UUID, limit, offset int) ([]ChunkMatch, int64, error) {
+	return s.search(ctx, query, pdfID, limit, offset, s.MinScore)
+}
+
+// Nearest returns the k chunks of a PDF closest to the query regardless of
+// their score, used to ground answers in the document
+func (s *chunkService) Nearest(ctx context.Context, query string, pdfID uuid.UUID, k int) ([]ChunkMatch, error) {
+	matches, _, err := s.search(ctx, query, &pdfID, k, 0, math.Inf(-1))
+	return matches, err
+}
+
+func (s *chunkService) search(ctx context.Context, query string, pdfID *uuid.UUID, limit, offset int, minScore float64) ([]ChunkMatch, int64, error) {
+	vectors, err := s.Embedder.Embed(ctx, []string{query})
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to embed query: %w", err)
+	}
+	queryVector := vectors[0]
+
+	db := s.DB.WithContext(ctx).
+		Model(&model.PDFChunk{}).
+		Select("pdf_chunks.id", "pdf_chunks.embedding").
+		Joins("JOIN pdf_documents p ON p.id = pdf_chunks.pdf_id AND p.deleted_at IS NULL").
+		Where("pdf_chunks.model = ?", s.Embedder.Model())
+	if pdfID != nil {
+		db = db.Where("pdf_chunks.pdf_id = ?", *pdfID)
+	}
+
+	best := &chunkHeap{}
+	keep := offset + limit
+	var total int64
+
+	var batch []model.PDFChunk
+	err = db.FindInBatches(&batch, 1000, func(tx *gorm.DB, n int) error {
+		for _, chunk := range batch {
+			score, ok := cosineSimilarity(queryVector, chunk.Embedding)
+			if !ok || score < minScore {
+				continue
+			}
+			total++
+
+			heap.Push(best, scoredChunk{ID: chunk.ID, Score: score})
+			if best.Len() > keep {
+				heap.Pop(best)
+			}
+		}
+		return nil
+	}).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// The heap pops the lowest score first
+	ranked := make([]scoredChunk, best.Len())
+	for i := len(ranked) - 1; i >= 0; i-- {
+		ranked[i] = heap.Pop(best).(scoredChunk)
+	}
+	if offset >= len(ranked) {
+		return []ChunkMatch{}, total, nil
+	}
+	ranked = ranked[offset:]
+
+	ids := make([]uuid.UUID, len(ranked))
+	for i, r := range ranked {
+		ids[i] = r.ID
+	}
+
+	var chunks []model.PDFChunk
+	if err := s.DB.WithContext(ctx).
+		Select("id", "pdf_id", "page_number", "chunk_index", "content").
+		Where("id IN ?", ids).
+		Find(&chunks).Error; err != nil {
+		return nil, 0, err
+	}
+	byID := make(map[uuid.UUID]model.PDFChunk, len(chunks))
+	for _, chunk := range chunks {
+		byID[chunk.ID] = chunk
+	}
+
+	matches := make([]ChunkMatch, 0, len(ranked))
+	for _, r := range ranked {
+		chunk, ok := byID[r.ID]
+		if !ok {
+			continue
+		}
+		matches = append(matches, ChunkMatch{
+			ChunkID:    chunk.ID,
+			PDFID:      chunk.PDFID,
+			PageNumber: chunk.PageNumber,
+			ChunkIndex: chunk.ChunkIndex,
+			Content:    chunk.Content,
+			Score:      r.Score,
+		})
+	}
+	return matches, total, nil
+}
+
+// Reindex embeds the pages of every PDF without chunks for the current model,
+// e.g. after switching the embedding provider
+func (s *chunkService) Reindex(ctx context.Context) (int, error) {
+	var pdfIDs []uuid.UUID
+	if err := s.DB.WithContext(ctx).
+		Model(&model.PDFPage{}).
+		Distinct("pdf_pages.pdf_id").
+		Joins("JOIN pdf_documents p ON p.id = pdf_pages.pdf_id AND p.deleted_at IS NULL").
+		Where("NOT EXISTS (SELECT 1 FROM pdf_chunks c WHERE c.pdf_id = pdf_pages.pdf_id AND c.model = ?)", s.Embedder.Model()).
+		Pluck("pdf_pages.pdf_id", &pdfIDs).Error; err != nil {
+		return 0, err
+	}
+
+	for i, pdfID := range pdfIDs {
+		var pages []model.PDFPage
+		if err := s.DB.WithContext(ctx).
+			Where("pdf_id = ?", pdfID).
+			Order("page_number ASC").
+			Find(&pages).Error; err != nil {
+			return i, err
+		}
+		if err := s.IndexPages(ctx, pdfID, pages); err != nil {
+			return i, err
+		}
+	}
+
+	s.Log.WithField("pdfs", len(pdfIDs)).Info("Chunk embeddings reindexed")
+	return len(pdfIDs), nil
+}
+
+// chunkText splits text into chunks of at most size runes that overlap by
+// about overlap runes, preferring to cut at whitespace
+func chunkText(text string, size, overlap int) []string {
+	runes := []rune(strings.Join(strings.Fields(text), " "))
+	if len(runes) == 0 {
+		return nil
+	}
+
+	var chunks []string
+	for start := 0; start < len(runes); {
+		end := start + size
+		if end >= len(runes) {
+			chunks = append(chunks, string(runes[start:]))
+			break
+		}
+
+		// Back off to the last space in the second half of the chunk
+		for cut := end; cut > start+size/2; cut-- {
+			if unicode.IsSpace(runes[cut]) {
+				end = cut
+				break
+			}
+		}
+		chunks = append(chunks, strings.TrimSpace(string(runes[start:end])))
+
+		next := end - overlap
+		for next > start && next < end && !unicode.IsSpace(runes[next-1]) {
+			next++
+		}
+		// Text without spaces (e.g. CJK) keeps the plain overlap
+		if next >= end {
+			next = end - overlap
+		}
+		if next <= start {
+			next = end
+		}
+		start = next
+	}
+	return chunks
+}
+
+func encodeVector(v []float32) []byte {
+	buf := make([]byte, 4*len(v))
+	for i, x := range v {
+		binary.LittleEndian.PutUint32(buf[4*i:], math.Float32bits(x))
+	}
+	return buf
+}
+
+// cosineSimilarity compares a unit query vector with an encoded unit vector.
+// ok is false when the dimensions differ.
+func cosineSimilarity(query []float32, encoded []byte) (float64, bool) {
+	if len(encoded) != 4*len(query) {
+		return 0, false
+	}
+	var dot float64
+	for i, q := range query {
+		x := math.Float32frombits(binary.LittleEndian.Uint32(encoded[4*i:]))
+		dot += float64(q) * float64(x)
+	}
+	return dot, true
+}
+
+type scoredChunk struct {
+	ID    uuid.UUID
+	Score float64
+}
+
+// chunkHeap is a min-heap on score holding the best chunks seen so far
+type chunkHeap []scoredChunk
+
+func (h chunkHeap) Len() int            { return len(h) }
+func (h chunkHeap) Less(i, j int) bool  { return h[i].Score < h[j].Score }
+func (h chunkHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *chunkHeap) Push(x interface{}) { *h = append(*h, x.(scoredChunk)) }
+func (h *chunkHeap) Pop() interface{} {
+	old := *h
+	item := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return item
+}
+
+// lockPDF serializes writers of one kind of per-PDF data until the
+// transaction ends, without blocking updates of the PDF row itself
+func lockPDF(tx *gorm.DB, scope string, pdfID uuid.UUID) error {
+	return tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", scope+":"+pdfID.String()).Error
+}
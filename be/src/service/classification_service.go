@@ -0,0 +1,640 @@
+package service
+
+import (
+	"app/src/config"
+	"app/src/model"
+	"app/src/utils"
+	"app/src/validation"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	classificationPages = 5
+	classificationRunes = 12000
+
+	// Retraining needs a few labeled documents per category and keeps the
+	// terms found in most of them but rarely elsewhere
+	minTrainingDocuments = 2
+	minLearnedSupport    = 0.5
+	minLearnedWeight     = 0.3
+	maxLearnedKeywords   = 30
+)
+
+var (
+	ErrUnknownCategory = errors.New("unknown category")
+	ErrInvalidCategory = errors.New("category must consist of lowercase letters, digits and underscores")
+)
+
+var categoryPattern = regexp.MustCompile(`^[a-z0-9_]+$`)
+
+// RetrainResult reports, per category, how many labeled documents were used
+// and how many terms were learned. Categories with too few labels are skipped.
+type RetrainResult struct {
+	Categories   map[string]RetrainCategory `json:"categories"`
+	Reclassified int                        `json:"reclassified"`
+}
+
+type RetrainCategory struct {
+	Documents int  `json:"documents"`
+	Terms     int  `json:"terms"`
+	Skipped   bool `json:"skipped,omitempty"`
+}
+
+type ClassificationService interface {
+	Classify(ctx context.Context, pdfID uuid.UUID) (*model.PDF, error)
+	SetCategory(ctx context.Context, pdfID uuid.UUID, category *string, version int) (*model.PDF, error)
+	GetRules(ctx context.Context, activeOnly bool) ([]model.ClassificationRule, error)
+	UpsertRule(ctx context.Context, category string, payload validation.UpsertClassificationRule) (*model.ClassificationRule, error)
+	DeleteRule(ctx context.Context, category string) error
+	Retrain(ctx context.Context, reclassify bool) (*RetrainResult, error)
+}
+
+type classificationService struct {
+	Log               *logrus.Logger
+	DB                *gorm.DB
+	ExtractionService ExtractionService
+}
+
+func NewClassificationService(db *gorm.DB, extractionService ExtractionService) ClassificationService {
+	return &classificationService{
+		Log:               utils.Log,
+		DB:                db,
+		ExtractionService: extractionService,
+	}
+}
+
+// Classify assigns a category to a PDF with the ML service, or with the
+// keyword rules when it is disabled or fails. Manually categorized PDFs are
+// returned unchanged.
+func (s *classificationService) Classify(ctx context.Context, pdfID uuid.UUID) (*model.PDF, error) {
+	return s.classify(ctx, pdfID, config.Classifier == "ai")
+}
+
+func (s *classificationService) classify(ctx context.Context, pdfID uuid.UUID, useAI bool) (*model.PDF, error) {
+	pdf := &model.PDF{}
+	if err := s.DB.WithContext(ctx).First(pdf, "id = ?", pdfID).Error; err != nil {
+		return nil, err
+	}
+	if pdf.CategorySource != nil && *pdf.CategorySource == "manual" {
+		return pdf, nil
+	}
+
+	text, err := s.classificationText(ctx, pdf)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(text) == "" {
+		return pdf, nil
+	}
+
+	rules, err := s.GetRules(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+	if len(rules) == 0 {
+		return pdf, nil
+	}
+
+	source := "rules"
+	var category string
+	var confidence float64
+	if useAI {
+		category, confidence, err = s.callClassify(ctx, text, rules)
+		if err != nil {
+			s.Log.WithError(err).WithField("pdf_id", pdfID).Warn("AI classification failed, using rules")
+		} else {
+			source = "ai"
+		}
+	}
+	if source == "rules" {
+		category, confidence = scoreRules(text, ruleTerms(rules))
+	}
+
+	known := false
+	for _, rule := range rules {
+		known = known || rule.Category == category
+	}
+
+	var assigned *string
+	if known && confidence >= config.ClassificationMinConfidence {
+		assigned = &category
+	}
+	confidence = math.Round(confidence*1000) / 1000
+	now := time.Now()
+
+	// The category may have been set manually while the text was classified
+	result := s.DB.WithContext(ctx).Model(&model.PDF{}).
+		Where("id = ? AND category_source IS DISTINCT FROM 'manual'", pdfID).
+		UpdateColumns(map[string]interface{}{
+			"category":            assigned,
+			"category_confidence": confidence,
+			"category_source":     source,
+			"classified_at":       now,
+			"version":             gorm.Expr("version + 1"),
+			"updated_at":          now,
+		})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	if err := s.DB.WithContext(ctx).First(pdf, "id = ?", pdfID).Error; err != nil {
+		return nil, err
+	}
+	if result.RowsAffected == 0 {
+		return pdf, nil
+	}
+
+	s.createProcessingLog(ctx, "pdf", pdfID, "classify", "completed", "PDF classified", map[string]interface{}{
+		"category":   category,
+		"assigned":   assigned != nil,
+		"confidence": confidence,
+		"source":     source,
+	})
+
+	return pdf, nil
+}
+
+// classificationText is the document metadata followed by the text of the
+// first pages, which is where the type of a document shows
+func (s *classificationService) classificationText(ctx context.Context, pdf *model.PDF) (string, error) {
+	pages, err := s.ExtractionService.GetPages(ctx, pdf.ID)
+	if err != nil {
+		return "", err
+	}
+	if len(pages) > classificationPages {
+		pages = pages[:classificationPages]
+	}
+
+	text := strings.TrimSpace(strings.Join([]string{
+		pdf.Title, pdf.Subject, pdf.Keywords, joinPageText(pages),
+	}, "\n"))
+	return truncateRunes(text, classificationRunes), nil
+}
+
+type classifyCategory struct {
+	Category    string `json:"category"`
+	Label       string `json:"label"`
+	Description string `json:"description,omitempty"`
+}
+
+// callClassify asks the ML service for the category of a document. It
+// answers "other" when no category fits.
+func (s *classificationService) callClassify(ctx context.Context, text string, rules []model.ClassificationRule) (string, float64, error) {
+	categories := make([]classifyCategory, 0, len(rules))
+	for _, rule := range rules {
+		categories = append(categories, classifyCategory{
+			Category:    rule.Category,
+			Label:       rule.Label,
+			Description: rule.Description,
+		})
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"text":       text,
+		"categories": categories,
+	})
+	if err != nil {
+		return "", 0, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", config.MLServiceURL+"/classify", bytes.NewReader(body))
+	if err != nil {
+		return "", 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := &http.Client{
+		Timeout: time.Minute,
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", 0, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", 0, err
+	}
+	if resp.StatusCode != 200 {
+		return "", 0, fmt.Errorf("AI returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+
+	var parsed struct {
+		Category   string  `json:"category"`
+		Confidence float64 `json:"confidence"`
+	}
+	if err := json.Unmarshal(respBody, &parsed); err != nil {
+		return "", 0, err
+	}
+	return parsed.Category, math.Max(0, math.Min(1, parsed.Confidence)), nil
+}
+
+// ruleTerms merges the keywords of every rule with its learned terms. Admin
+// keywords weigh 1, learned terms their computed weight.
+func ruleTerms(rules []model.ClassificationRule) map[string]map[string]float64 {
+	terms := make(map[string]map[string]float64, len(rules))
+	for _, rule := range rules {
+		weights := map[string]float64{}
+
+		var learned map[string]float64
+		if rule.LearnedKeywords != nil {
+			_ = json.Unmarshal(*rule.LearnedKeywords, &learned)
+		}
+		for term, weight := range learned {
+			weights[normalizeTerm(term)] = weight
+		}
+
+		var keywords []string
+		_ = json.Unmarshal(rule.Keywords, &keywords)
+		for _, keyword := range keywords {
+			if term := normalizeTerm(keyword); term != "" {
+				weights[term] = 1
+			}
+		}
+
+		terms[rule.Category] = weights
+	}
+	return terms
+}
+
+// scoreRules scores every category by its weighted keyword occurrences with
+// diminishing returns for repeats. The confidence is the share of the best
+// category, damped while the evidence is thin.
+func scoreRules(text string, terms map[string]map[string]float64) (string, float64) {
+	normalized := " " + normalizeTerm(text) + " "
+
+	categories := make([]string, 0, len(terms))
+	for category := range terms {
+		categories = append(categories, category)
+	}
+	sort.Strings(categories)
+
+	var best string
+	var bestScore, total float64
+	for _, category := range categories {
+		var score float64
+		for term, weight := range terms[category] {
+			var count int
+			if utils.ContainsCJK(term) {
+				count = strings.Count(normalized, term)
+			} else {
+				count = strings.Count(normalized, " "+term+" ")
+			}
+			score += weight * math.Log1p(float64(count))
+		}
+		total += score
+		if score > bestScore {
+			best, bestScore = category, score
+		}
+	}
+
+	if bestScore == 0 {
+		return "", 0
+	}
+	return best, bestScore / total * (1 - math.Exp(-bestScore/2))
+}
+
+// normalizeTerm lowercases text and reduces it to words separated by single
+// spaces, so phrases match regardless of punctuation and line breaks
+func normalizeTerm(text string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	}), " ")
+}
+
+// SetCategory overrides the category of a PDF. A nil category removes the
+// override and classifies the PDF again. A non-zero version must match the
+// current one, otherwise ErrVersionConflict is returned.
+func (s *classificationService) SetCategory(ctx context.Context, pdfID uuid.UUID, category *string, version int) (*model.PDF, error) {
+	pdf := &model.PDF{}
+	if err := s.DB.WithContext(ctx).First(pdf, "id = ?", pdfID).Error; err != nil {
+		return nil, err
+	}
+	if version > 0 && pdf.Version != version {
+		return nil, ErrVersionConflict
+	}
+
+	if category == nil {
+		if err := s.updateCategory(ctx, pdfID, version, map[string]interface{}{
+			"category_source": nil,
+		}); err != nil {
+			return nil, err
+		}
+		return s.Classify(ctx, pdfID)
+	}
+
+	var count int64
+	if err := s.DB.WithContext(ctx).Model(&model.ClassificationRule{}).
+		Where("category = ?", *category).
+		Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownCategory, *category)
+	}
+
+	if err := s.updateCategory(ctx, pdfID, version, map[string]interface{}{
+		"category":            *category,
+		"category_confidence": 1.0,
+		"category_source":     "manual",
+		"classified_at":       time.Now(),
+	}); err != nil {
+		return nil, err
+	}
+
+	if err := s.DB.WithContext(ctx).First(pdf, "id = ?", pdfID).Error; err != nil {
+		return nil, err
+	}
+
+	s.createProcessingLog(ctx, "pdf", pdfID, "classify", "manual", "Category set manually", map[string]interface{}{
+		"category": *category,
+	})
+
+	return pdf, nil
+}
+
+// updateCategory writes category columns of a PDF and bumps its version, as
+// they are part of the PDF representation
+func (s *classificationService) updateCategory(ctx context.Context, pdfID uuid.UUID, version int, columns map[string]interface{}) error {
+	columns["version"] = gorm.Expr("version + 1")
+	columns["updated_at"] = time.Now()
+
+	query := s.DB.WithContext(ctx).Model(&model.PDF{}).Where("id = ?", pdfID)
+	if version > 0 {
+		query = query.Where("version = ?", version)
+	}
+	result := query.UpdateColumns(columns)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		if version > 0 {
+			return ErrVersionConflict
+		}
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (s *classificationService) GetRules(ctx context.Context, activeOnly bool) ([]model.ClassificationRule, error) {
+	query := s.DB.WithContext(ctx).Order("category ASC")
+	if activeOnly {
+		query = query.Where("is_active = ?", true)
+	}
+
+	var rules []model.ClassificationRule
+	if err := query.Find(&rules).Error; err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// UpsertRule creates a category or replaces its label, description and
+// keywords. Learned terms are kept until the next retraining.
+func (s *classificationService) UpsertRule(ctx context.Context, category string, payload validation.UpsertClassificationRule) (*model.ClassificationRule, error) {
+	if !categoryPattern.MatchString(category) {
+		return nil, ErrInvalidCategory
+	}
+
+	keywords := make([]string, 0, len(payload.Keywords))
+	seen := map[string]bool{}
+	for _, keyword := range payload.Keywords {
+		keyword = strings.ToLower(strings.Join(strings.Fields(keyword), " "))
+		if keyword == "" || seen[keyword] {
+			continue
+		}
+		seen[keyword] = true
+		keywords = append(keywords, keyword)
+	}
+	keywordsJSON, _ := json.Marshal(keywords)
+
+	rule := &model.ClassificationRule{
+		Category:    category,
+		Label:       payload.Label,
+		Description: payload.Description,
+		Keywords:    keywordsJSON,
+		IsActive:    payload.IsActive == nil || *payload.IsActive,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+
+	if err := s.DB.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "category"}},
+		DoUpdates: clause.AssignmentColumns([]string{"label", "description", "keywords", "is_active", "updated_at"}),
+	}).Create(rule).Error; err != nil {
+		return nil, err
+	}
+
+	if err := s.DB.WithContext(ctx).First(rule, "category = ?", category).Error; err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+// DeleteRule removes a category, its PDFs become unclassified
+func (s *classificationService) DeleteRule(ctx context.Context, category string) error {
+	return s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.PDF{}).
+			Where("category = ?", category).
+			UpdateColumns(map[string]interface{}{
+				"category":            nil,
+				"category_confidence": nil,
+				"category_source":     nil,
+				"classified_at":       nil,
+				"version":             gorm.Expr("version + 1"),
+				"updated_at":          time.Now(),
+			}).Error; err != nil {
+			return err
+		}
+
+		result := tx.Delete(&model.ClassificationRule{}, "category = ?", category)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+}
+
+// Retrain learns keyword weights from the manually categorized PDFs. A term
+// is weighted by how much more often it occurs in the documents of a category
+// than in the other labeled documents. With reclassify, PDFs classified by
+// rules or not at all are classified again with the new rules.
+func (s *classificationService) Retrain(ctx context.Context, reclassify bool) (*RetrainResult, error) {
+	var labeled []model.PDF
+	if err := s.DB.WithContext(ctx).
+		Where("category_source = 'manual' AND category IS NOT NULL").
+		Find(&labeled).Error; err != nil {
+		return nil, err
+	}
+
+	// Document frequency of every term, per category and overall
+	documents := map[string]int{}
+	categoryDF := map[string]map[string]int{}
+	totalDF := map[string]int{}
+	for i := range labeled {
+		pdf := &labeled[i]
+		text, err := s.classificationText(ctx, pdf)
+		if err != nil {
+			return nil, err
+		}
+
+		category := *pdf.Category
+		documents[category]++
+		if categoryDF[category] == nil {
+			categoryDF[category] = map[string]int{}
+		}
+		for term := range trainingTerms(text) {
+			categoryDF[category][term]++
+			totalDF[term]++
+		}
+	}
+
+	rules, err := s.GetRules(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &RetrainResult{Categories: map[string]RetrainCategory{}}
+	now := time.Now()
+	for _, rule := range rules {
+		n := documents[rule.Category]
+		if n < minTrainingDocuments {
+			result.Categories[rule.Category] = RetrainCategory{Documents: n, Skipped: true}
+			continue
+		}
+
+		others := len(labeled) - n
+		type weightedTerm struct {
+			term   string
+			weight float64
+		}
+		var candidates []weightedTerm
+		for term, df := range categoryDF[rule.Category] {
+			support := float64(df) / float64(n)
+			if support < minLearnedSupport {
+				continue
+			}
+			elsewhere := 0.0
+			if others > 0 {
+				elsewhere = float64(totalDF[term]-df) / float64(others)
+			}
+			if weight := support - elsewhere; weight >= minLearnedWeight {
+				candidates = append(candidates, weightedTerm{term, math.Round(weight*100) / 100})
+			}
+		}
+		sort.Slice(candidates, func(i, j int) bool {
+			if candidates[i].weight != candidates[j].weight {
+				return candidates[i].weight > candidates[j].weight
+			}
+			return candidates[i].term < candidates[j].term
+		})
+		if len(candidates) > maxLearnedKeywords {
+			candidates = candidates[:maxLearnedKeywords]
+		}
+
+		learned := make(map[string]float64, len(candidates))
+		for _, c := range candidates {
+			learned[c.term] = c.weight
+		}
+		learnedJSON, _ := json.Marshal(learned)
+
+		if err := s.DB.WithContext(ctx).Model(&model.ClassificationRule{}).
+			Where("category = ?", rule.Category).
+			Updates(map[string]interface{}{
+				"learned_keywords": string(learnedJSON),
+				"trained_at":       now,
+				"updated_at":       now,
+			}).Error; err != nil {
+			return nil, err
+		}
+		result.Categories[rule.Category] = RetrainCategory{Documents: n, Terms: len(learned)}
+	}
+
+	s.createProcessingLog(ctx, "classification", uuid.Nil, "retrain", "completed", "Classification rules retrained", map[string]interface{}{
+		"labeled_documents": len(labeled),
+		"categories":        result.Categories,
+	})
+
+	if reclassify {
+		var pdfs []model.PDF
+		err := s.DB.WithContext(ctx).Select("id").
+			Where("category_source IS NULL OR category_source = 'rules'").
+			FindInBatches(&pdfs, reindexBatchSize, func(tx *gorm.DB, batch int) error {
+				for _, pdf := range pdfs {
+					if _, err := s.classify(ctx, pdf.ID, false); err != nil {
+						s.Log.WithError(err).WithField("pdf_id", pdf.ID).Warn("Failed to reclassify PDF")
+						continue
+					}
+					result.Reclassified++
+				}
+				return nil
+			}).Error
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// trainingTerms returns the distinct words of at least four letters and the
+// two-word phrases of a text
+func trainingTerms(text string) map[string]bool {
+	terms := map[string]bool{}
+	words := strings.Fields(normalizeTerm(text))
+	for i, word := range words {
+		if len([]rune(word)) < 4 || strings.IndexFunc(word, unicode.IsLetter) < 0 {
+			continue
+		}
+		terms[word] = true
+		if i+1 < len(words) && len([]rune(words[i+1])) >= 4 {
+			terms[word+" "+words[i+1]] = true
+		}
+	}
+	return terms
+}
+
+func (s *classificationService) createProcessingLog(ctx context.Context, entityType string, entityID uuid.UUID, action, status, message string, metadata map[string]interface{}) {
+	var metaJSON *json.RawMessage
+
+	if metadata != nil {
+		b, _ := json.Marshal(metadata)
+		raw := json.RawMessage(b)
+		metaJSON = &raw
+	}
+
+	log := &model.ProcessingLog{
+		EntityType: entityType,
+		EntityID:   entityID,
+		Action:     action,
+		Status:     status,
+		Message:    message,
+		Metadata:   metaJSON,
+		CreatedAt:  time.Now(),
+	}
+
+	if err := s.DB.WithContext(ctx).Create(log).Error; err != nil {
+		s.Log.WithError(err).Error("Failed to create processing log")
+	}
+}
@@ -0,0 +1,197 @@
+package service
+
+import (
+	"app/src/utils"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Embedder turns texts into vectors whose cosine similarity reflects how close
+// their meaning is. Model identifies the vector space, vectors of different
+// models are never compared.
+type Embedder interface {
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+	Model() string
+}
+
+// hashEmbedder hashes words and CJK bigrams into a fixed number of buckets. It
+// needs no external service and is deterministic, which makes it suitable for
+// local development and tests, but it only captures shared vocabulary.
+type hashEmbedder struct {
+	Dimensions int
+}
+
+func NewHashEmbedder(dimensions int) Embedder {
+	return &hashEmbedder{
+		Dimensions: dimensions,
+	}
+}
+
+func (e *hashEmbedder) Model() string {
+	return fmt.Sprintf("hash-%d", e.Dimensions)
+}
+
+func (e *hashEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		vector := make([]float32, e.Dimensions)
+		for _, token := range embeddingTokens(text) {
+			h := fnv.New64a()
+			h.Write([]byte(token))
+			sum := h.Sum64()
+
+			// The top bit picks the sign so colliding tokens tend to cancel out
+			weight := float32(1)
+			if sum>>63 == 1 {
+				weight = -1
+			}
+			vector[sum%uint64(e.Dimensions)] += weight
+		}
+		vectors[i] = normalizeVector(vector)
+	}
+	return vectors, nil
+}
+
+func embeddingTokens(text string) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+
+	tokens := make([]string, 0, len(words))
+	for _, word := range words {
+		if utils.ContainsCJK(word) {
+			tokens = append(tokens, strings.Fields(utils.SegmentCJK(word))...)
+			continue
+		}
+		if len(word) > 1 {
+			tokens = append(tokens, word)
+		}
+	}
+	return tokens
+}
+
+// openAIEmbedder calls an OpenAI compatible /embeddings endpoint
+type openAIEmbedder struct {
+	BaseURL   string
+	APIKey    string
+	ModelName string
+	BatchSize int
+	Client    *http.Client
+}
+
+func NewOpenAIEmbedder(baseURL, apiKey, model string) Embedder {
+	return &openAIEmbedder{
+		BaseURL:   strings.TrimRight(baseURL, "/"),
+		APIKey:    apiKey,
+		ModelName: model,
+		BatchSize: 64,
+		Client: &http.Client{
+			Timeout: time.Minute,
+		},
+	}
+}
+
+func (e *openAIEmbedder) Model() string {
+	return "openai:" + e.ModelName
+}
+
+type embeddingRequest struct {
+	Model string   `json:"model"`
+	Input []string `json:"input"`
+}
+
+type embeddingResponse struct {
+	Data []struct {
+		Index     int       `json:"index"`
+		Embedding []float32 `json:"embedding"`
+	} `json:"data"`
+}
+
+func (e *openAIEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, 0, len(texts))
+	for start := 0; start < len(texts); start += e.BatchSize {
+		end := start + e.BatchSize
+		if end > len(texts) {
+			end = len(texts)
+		}
+
+		batch, err := e.embedBatch(ctx, texts[start:end])
+		if err != nil {
+			return nil, err
+		}
+		vectors = append(vectors, batch...)
+	}
+	return vectors, nil
+}
+
+func (e *openAIEmbedder) embedBatch(ctx context.Context, texts []string) ([][]float32, error) {
+	body, err := json.Marshal(embeddingRequest{Model: e.ModelName, Input: texts})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", e.BaseURL+"/embeddings", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if e.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+e.APIKey)
+	}
+
+	resp, err := e.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("embedding request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read embedding response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("embedding service returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+
+	var parsed embeddingResponse
+	if err := json.Unmarshal(respBody, &parsed); err != nil {
+		return nil, fmt.Errorf("invalid embedding response: %w", err)
+	}
+	if len(parsed.Data) != len(texts) {
+		return nil, fmt.Errorf("embedding service returned %d vectors for %d inputs", len(parsed.Data), len(texts))
+	}
+
+	vectors := make([][]float32, len(texts))
+	for _, item := range parsed.Data {
+		if item.Index < 0 || item.Index >= len(texts) {
+			return nil, fmt.Errorf("invalid embedding index %d", item.Index)
+		}
+		vectors[item.Index] = normalizeVector(item.Embedding)
+	}
+	return vectors, nil
+}
+
+// normalizeVector scales v to unit length so cosine similarity is a dot product
+func normalizeVector(v []float32) []float32 {
+	var sum float64
+	for _, x := range v {
+		sum += float64(x) * float64(x)
+	}
+	if sum == 0 {
+		return v
+	}
+	norm := float32(math.Sqrt(sum))
+	for i := range v {
+		v[i] /= norm
+	}
+	return v
+}
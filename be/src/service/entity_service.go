@@ -0,0 +1,398 @@
+package service
+
+import (
+	"app/src/config"
+	"app/src/model"
+	"app/src/utils"
+	"app/src/validation"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	defaultTopEntities = 20
+	maxEntitiesPerPDF  = 200
+)
+
+// EntityCount is an entity with the number of PDFs and mentions it has
+type EntityCount struct {
+	model.Entity
+	DocumentCount int64 `json:"document_count"`
+	Mentions      int64 `json:"mentions"`
+}
+
+type EntityService interface {
+	IndexPDF(ctx context.Context, pdfID uuid.UUID) (int, error)
+	Reindex(ctx context.Context) (map[string]int64, error)
+	GetTop(ctx context.Context, params validation.EntityQuery) ([]EntityCount, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*model.Entity, error)
+	GetPDFs(ctx context.Context, entityID uuid.UUID, params validation.EntityPDFsQuery) ([]model.PDFEntity, *model.PaginationMeta, error)
+	GetForPDF(ctx context.Context, pdfID uuid.UUID) ([]model.PDFEntity, error)
+}
+
+type entityService struct {
+	Log               *logrus.Logger
+	DB                *gorm.DB
+	ExtractionService ExtractionService
+}
+
+func NewEntityService(db *gorm.DB, extractionService ExtractionService) EntityService {
+	return &entityService{
+		Log:               utils.Log,
+		DB:                db,
+		ExtractionService: extractionService,
+	}
+}
+
+// IndexPDF replaces the entities of a PDF. They are taken from its latest
+// completed full summary, or from the extracted text when it has none. The ML
+// service finds them, the rule-based extractor is used when it fails.
+func (s *entityService) IndexPDF(ctx context.Context, pdfID uuid.UUID) (int, error) {
+	pages, err := s.ExtractionService.GetPages(ctx, pdfID)
+	if err != nil {
+		return 0, err
+	}
+	documentText := joinPageText(pages)
+
+	var summary model.Summary
+	err = s.DB.WithContext(ctx).
+		Where("pdf_id = ? AND status = 'completed' AND kind = 'single' AND page_from IS NULL", pdfID).
+		Order("updated_at DESC").
+		First(&summary).Error
+	hasSummary := err == nil
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, err
+	}
+
+	source, text := "text", documentText
+	var summaryID *uuid.UUID
+	if hasSummary {
+		source, text = "summary", summary.Content
+		summaryID = &summary.ID
+	}
+	if strings.TrimSpace(text) == "" {
+		return 0, nil
+	}
+
+	method := "ai"
+	entities, err := s.callEntities(ctx, text)
+	if err != nil {
+		s.Log.WithError(err).WithField("pdf_id", pdfID).Warn("Entity extraction failed, using rules")
+		method = "rules"
+		entities = utils.ExtractEntities(text)
+		if hasSummary && summary.Structured == nil {
+			for _, keyword := range utils.MarkdownKeywords(summary.Content) {
+				entities = append(entities, utils.Entity{Type: utils.EntityKeyword, Name: keyword})
+			}
+		}
+	}
+
+	// Keywords of the structured summary are authoritative, they come first
+	// so their spelling is kept and they are never cut below
+	authoritative := map[string]bool{}
+	if hasSummary && summary.Structured != nil {
+		var structured model.StructuredSummary
+		if err := json.Unmarshal(*summary.Structured, &structured); err == nil {
+			keywords := make([]utils.Entity, 0, len(structured.Keywords))
+			for _, keyword := range structured.Keywords {
+				keywords = append(keywords, utils.Entity{Type: utils.EntityKeyword, Name: keyword})
+				authoritative[utils.NormalizeEntity(keyword)] = true
+			}
+			entities = append(keywords, entities...)
+		}
+	}
+
+	// Mentions are counted in the document, summaries repeat little
+	mentionText := documentText
+	if mentionText == "" {
+		mentionText = text
+	}
+
+	entities = utils.DedupeEntities(entities)
+	mentions := make([]int, len(entities))
+	for i, found := range entities {
+		mentions[i] = utils.CountMentions(mentionText, found.Name)
+	}
+	entities, mentions = topEntities(entities, mentions, authoritative, maxEntitiesPerPDF)
+
+	err = s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := lockPDF(tx, "pdf_entities", pdfID); err != nil {
+			return err
+		}
+		if err := tx.Where("pdf_id = ?", pdfID).Delete(&model.PDFEntity{}).Error; err != nil {
+			return err
+		}
+
+		links := make([]model.PDFEntity, 0, len(entities))
+		for i, found := range entities {
+			entity := model.Entity{
+				Type:       found.Type,
+				Name:       found.Name,
+				Normalized: utils.NormalizeEntity(found.Name),
+				CreatedAt:  time.Now(),
+				UpdatedAt:  time.Now(),
+			}
+			// The first spelling is kept, RETURNING still reports the existing ID
+			if err := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "type"}, {Name: "normalized"}},
+				DoUpdates: clause.AssignmentColumns([]string{"updated_at"}),
+			}).Create(&entity).Error; err != nil {
+				return err
+			}
+
+			links = append(links, model.PDFEntity{
+				PDFID:     pdfID,
+				EntityID:  entity.ID,
+				Mentions:  mentions[i],
+				Source:    source,
+				Method:    method,
+				SummaryID: summaryID,
+				CreatedAt: time.Now(),
+			})
+		}
+
+		if len(links) == 0 {
+			return nil
+		}
+		return tx.Create(&links).Error
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	s.createProcessingLog(ctx, "pdf", pdfID, "index_entities", "completed", "Entities indexed", map[string]interface{}{
+		"entities": len(entities),
+		"source":   source,
+		"method":   method,
+	})
+
+	return len(entities), nil
+}
+
+// topEntities keeps at most limit entities: the authoritative keywords first,
+// then the most mentioned ones. The kept entities stay in their given order.
+func topEntities(entities []utils.Entity, mentions []int, authoritative map[string]bool, limit int) ([]utils.Entity, []int) {
+	if len(entities) <= limit {
+		return entities, mentions
+	}
+
+	isAuthoritative := func(i int) bool {
+		return entities[i].Type == utils.EntityKeyword && authoritative[utils.NormalizeEntity(entities[i].Name)]
+	}
+	order := make([]int, len(entities))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		a, b := order[i], order[j]
+		if isAuthoritative(a) != isAuthoritative(b) {
+			return isAuthoritative(a)
+		}
+		return mentions[a] > mentions[b]
+	})
+
+	order = order[:limit]
+	sort.Ints(order)
+	kept, keptMentions := make([]utils.Entity, 0, limit), make([]int, 0, limit)
+	for _, i := range order {
+		kept = append(kept, entities[i])
+		keptMentions = append(keptMentions, mentions[i])
+	}
+	return kept, keptMentions
+}
+
+// callEntities sends text to the ML service and returns the entities it found
+func (s *entityService) callEntities(ctx context.Context, text string) ([]utils.Entity, error) {
+	body, err := json.Marshal(map[string]string{"text": text})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", config.MLServiceURL+"/entities", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := &http.Client{
+		Timeout: 2 * time.Minute,
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("AI returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+
+	var parsed struct {
+		Entities []utils.Entity `json:"entities"`
+	}
+	if err := json.Unmarshal(respBody, &parsed); err != nil {
+		return nil, err
+	}
+	return parsed.Entities, nil
+}
+
+// Reindex indexes the entities of every PDF that has extracted text or a
+// completed summary
+func (s *entityService) Reindex(ctx context.Context) (map[string]int64, error) {
+	counts := map[string]int64{}
+	db := s.DB.WithContext(ctx)
+
+	var pdfs []model.PDF
+	err := db.Select("id").
+		Where("id IN (SELECT pdf_id FROM pdf_pages) OR id IN (SELECT pdf_id FROM summaries WHERE status = 'completed')").
+		FindInBatches(&pdfs, reindexBatchSize, func(tx *gorm.DB, batch int) error {
+			for _, pdf := range pdfs {
+				n, err := s.IndexPDF(ctx, pdf.ID)
+				if err != nil {
+					s.Log.WithError(err).WithField("pdf_id", pdf.ID).Warn("Failed to index entities")
+					counts["failed"]++
+					continue
+				}
+				counts["pdfs"]++
+				counts["entities"] += int64(n)
+			}
+			return nil
+		}).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return counts, nil
+}
+
+// GetTop returns the entities mentioned in the most PDFs
+func (s *entityService) GetTop(ctx context.Context, params validation.EntityQuery) ([]EntityCount, error) {
+	if params.Limit < 1 {
+		params.Limit = defaultTopEntities
+	}
+
+	query := s.DB.WithContext(ctx).Table("entities AS e").
+		Select("e.*, COUNT(DISTINCT pe.pdf_id) AS document_count, SUM(pe.mentions) AS mentions").
+		Joins("JOIN pdf_entities pe ON pe.entity_id = e.id").
+		Joins("JOIN pdf_documents p ON p.id = pe.pdf_id AND p.deleted_at IS NULL")
+
+	if params.Type != "" {
+		query = query.Where("e.type = ?", params.Type)
+	}
+	if params.Name != "" {
+		query = query.Where("e.normalized = ?", utils.NormalizeEntity(params.Name))
+	}
+	if params.Search != "" {
+		query = query.Where("e.normalized LIKE ?", "%"+utils.NormalizeEntity(params.Search)+"%")
+	}
+
+	var entities []EntityCount
+	if err := query.Group("e.id").
+		Order("document_count DESC, mentions DESC, e.normalized ASC").
+		Limit(params.Limit).
+		Scan(&entities).Error; err != nil {
+		return nil, err
+	}
+	return entities, nil
+}
+
+func (s *entityService) GetByID(ctx context.Context, id uuid.UUID) (*model.Entity, error) {
+	var entity model.Entity
+	if err := s.DB.WithContext(ctx).First(&entity, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &entity, nil
+}
+
+// GetPDFs returns the PDFs mentioning an entity, most mentions first
+func (s *entityService) GetPDFs(ctx context.Context, entityID uuid.UUID, params validation.EntityPDFsQuery) ([]model.PDFEntity, *model.PaginationMeta, error) {
+	if _, err := s.GetByID(ctx, entityID); err != nil {
+		return nil, nil, err
+	}
+
+	if params.Page < 1 {
+		params.Page = 1
+	}
+	if params.Limit < 1 {
+		params.Limit = 10
+	}
+
+	query := s.DB.WithContext(ctx).Model(&model.PDFEntity{}).
+		Joins("JOIN pdf_documents ON pdf_documents.id = pdf_entities.pdf_id AND pdf_documents.deleted_at IS NULL").
+		Where("pdf_entities.entity_id = ?", entityID)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, nil, err
+	}
+
+	var links []model.PDFEntity
+	if err := query.Preload("PDF").
+		Order("pdf_entities.mentions DESC, pdf_documents.uploaded_at DESC").
+		Limit(params.Limit).
+		Offset(params.GetOffset()).
+		Find(&links).Error; err != nil {
+		return nil, nil, err
+	}
+
+	meta := model.NewPaginationMeta(params.Page, params.Limit, total)
+	return links, &meta, nil
+}
+
+// GetForPDF returns the entities of a PDF grouped by type
+func (s *entityService) GetForPDF(ctx context.Context, pdfID uuid.UUID) ([]model.PDFEntity, error) {
+	if err := s.DB.WithContext(ctx).Select("id").First(&model.PDF{}, "id = ?", pdfID).Error; err != nil {
+		return nil, err
+	}
+
+	var links []model.PDFEntity
+	if err := s.DB.WithContext(ctx).
+		Joins("Entity").
+		Where("pdf_entities.pdf_id = ?", pdfID).
+		Order(`"Entity"."type" ASC, pdf_entities.mentions DESC`).
+		Find(&links).Error; err != nil {
+		return nil, err
+	}
+	return links, nil
+}
+
+func (s *entityService) createProcessingLog(ctx context.Context, entityType string, entityID uuid.UUID, action, status, message string, metadata map[string]interface{}) {
+	var metaJSON *json.RawMessage
+
+	if metadata != nil {
+		b, _ := json.Marshal(metadata)
+		raw := json.RawMessage(b)
+		metaJSON = &raw
+	}
+
+	log := &model.ProcessingLog{
+		EntityType: entityType,
+		EntityID:   entityID,
+		Action:     action,
+		Status:     status,
+		Message:    message,
+		Metadata:   metaJSON,
+		CreatedAt:  time.Now(),
+	}
+
+	if err := s.DB.WithContext(ctx).Create(log).Error; err != nil {
+		s.Log.WithError(err).Error("Failed to create processing log")
+	}
+}
@@ -0,0 +1,280 @@
+package service
+
+import (
+	"app/src/config"
+	"app/src/model"
+	"app/src/pdfparser"
+	"app/src/utils"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrInvalidPassword = errors.New("invalid PDF password")
+
+type ExtractionService interface {
+	Extract(ctx context.Context, pdf *model.PDF, password string) ([]model.PDFPage, error)
+	ExtractOutline(ctx context.Context, pdf *model.PDF, password string) ([]pdfparser.OutlineItem, error)
+	GetPages(ctx context.Context, pdfID uuid.UUID) ([]model.PDFPage, error)
+}
+
+type extractionService struct {
+	Log          *logrus.Logger
+	DB           *gorm.DB
+	OCREngine    OCREngine
+	ChunkService ChunkService
+}
+
+func NewExtractionService(db *gorm.DB, ocrEngine OCREngine, chunkService ChunkService) ExtractionService {
+	return &extractionService{
+		Log:          utils.Log,
+		DB:           db,
+		OCREngine:    ocrEngine,
+		ChunkService: chunkService,
+	}
+}
+
+type extractResponse struct {
+	Pages []struct {
+		Page int    `json:"page"`
+		Text string `json:"text"`
+	} `json:"pages"`
+}
+
+// Extract sends the PDF to the ML service and stores the text of every page.
+// Pages without a text layer are sent through OCR. The password is only
+// forwarded for decryption and never persisted.
+func (s *extractionService) Extract(ctx context.Context, pdf *model.PDF, password string) ([]model.PDFPage, error) {
+	respBody, err := s.postPDF(ctx, "/extract", pdf, password)
+	if err != nil {
+		return nil, err
+	}
+
+	var parsed extractResponse
+	if err := json.Unmarshal(respBody, &parsed); err != nil {
+		return nil, fmt.Errorf("invalid extraction response: %w", err)
+	}
+
+	doc := s.parseDocument(pdf.FilePath)
+
+	// The OCR session is opened for the first scanned page and shared by the
+	// others, so an encrypted file is decrypted once
+	var ocr OCRSession
+	var ocrErr error
+	defer func() {
+		if ocr != nil {
+			ocr.Close()
+		}
+	}()
+
+	pages := make([]model.PDFPage, 0, len(parsed.Pages))
+	for _, p := range parsed.Pages {
+		page := model.PDFPage{
+			PDFID:        pdf.ID,
+			PageNumber:   p.Page,
+			Text:         p.Text,
+			HasTextLayer: strings.TrimSpace(p.Text) != "",
+			CreatedAt:    time.Now(),
+		}
+		if doc != nil && !doc.Encrypted && !doc.PageHasText(p.Page-1) {
+			page.HasTextLayer = false
+		}
+
+		if !page.HasTextLayer && ocrErr == nil {
+			if ocr == nil {
+				if ocr, ocrErr = s.OCREngine.Open(ctx, pdf.FilePath, password); ocrErr != nil {
+					s.Log.WithError(ocrErr).Warnf("OCR unavailable for PDF %s", pdf.ID)
+				}
+			}
+			if ocr != nil {
+				s.recognizePage(ctx, ocr, pdf, &page)
+			}
+		}
+		page.SearchTokens = utils.SegmentCJK(page.Text + " " + page.OCRText)
+
+		pages = append(pages, page)
+	}
+
+	err = s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Serializes concurrent extractions of the same PDF (upload and summary)
+		var locked model.PDF
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id").
+			First(&locked, "id = ?", pdf.ID).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("pdf_id = ?", pdf.ID).Delete(&model.PDFPage{}).Error; err != nil {
+			return err
+		}
+		if len(pages) == 0 {
+			return nil
+		}
+		return tx.Create(&pages).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to store extracted pages: %w", err)
+	}
+
+	// Embedding may call an external service, it must not delay the caller
+	go s.indexChunks(pdf.ID, pages)
+
+	return pages, nil
+}
+
+func (s *extractionService) indexChunks(pdfID uuid.UUID, pages []model.PDFPage) {
+	if err := s.ChunkService.IndexPages(context.Background(), pdfID, pages); err != nil {
+		s.Log.WithError(err).Warnf("Failed to index chunks of PDF %s", pdfID)
+	}
+}
+
+func (s *extractionService) recognizePage(ctx context.Context, ocr OCRSession, pdf *model.PDF, page *model.PDFPage) {
+	result, err := ocr.RecognizePage(ctx, page.PageNumber)
+	if err != nil {
+		s.Log.WithError(err).Warnf("OCR failed for page %d of PDF %s", page.PageNumber, pdf.ID)
+		return
+	}
+	if strings.TrimSpace(result.Text) == "" {
+		return
+	}
+
+	confidence := result.Confidence
+	page.OCRText = result.Text
+	page.OCRConfidence = &confidence
+}
+
+// ExtractOutline reads the bookmarks with the ML service, which unlike the
+// structure parser can decrypt documents
+func (s *extractionService) ExtractOutline(ctx context.Context, pdf *model.PDF, password string) ([]pdfparser.OutlineItem, error) {
+	respBody, err := s.postPDF(ctx, "/outline", pdf, password)
+	if err != nil {
+		return nil, err
+	}
+
+	var parsed struct {
+		Outline []outlineNode `json:"outline"`
+	}
+	if err := json.Unmarshal(respBody, &parsed); err != nil {
+		return nil, fmt.Errorf("invalid outline response: %w", err)
+	}
+	return convertOutline(parsed.Outline, pdf.PageCount, 0), nil
+}
+
+type outlineNode struct {
+	Title    string        `json:"title"`
+	Page     int           `json:"page"`
+	Children []outlineNode `json:"children"`
+}
+
+func convertOutline(nodes []outlineNode, pageCount, depth int) []pdfparser.OutlineItem {
+	if depth > 32 {
+		return nil
+	}
+
+	items := make([]pdfparser.OutlineItem, 0, len(nodes))
+	for _, node := range nodes {
+		item := pdfparser.OutlineItem{
+			Title:    strings.Join(strings.Fields(node.Title), " "),
+			Children: convertOutline(node.Children, pageCount, depth+1),
+		}
+		if node.Page > 0 && (pageCount == 0 || node.Page <= pageCount) {
+			item.Page = node.Page
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+// postPDF uploads the file of a PDF to an ML service endpoint. The password is
+// only forwarded for decryption and never persisted.
+func (s *extractionService) postPDF(ctx context.Context, endpoint string, pdf *model.PDF, password string) ([]byte, error) {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	if password != "" {
+		_ = writer.WriteField("password", password)
+	}
+
+	file, err := os.Open(pdf.FilePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open PDF: %w", err)
+	}
+	defer file.Close()
+
+	fw, err := writer.CreateFormFile("file", pdf.OriginalName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create form file: %w", err)
+	}
+	if _, err := io.Copy(fw, file); err != nil {
+		return nil, fmt.Errorf("failed to copy PDF: %w", err)
+	}
+	writer.Close()
+
+	req, err := http.NewRequestWithContext(ctx, "POST", config.MLServiceURL+endpoint, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+
+	client := &http.Client{
+		Timeout: 2 * time.Minute,
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s request failed: %w", strings.TrimPrefix(endpoint, "/"), err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s response: %w", strings.TrimPrefix(endpoint, "/"), err)
+	}
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		return nil, ErrInvalidPassword
+	}
+	if resp.StatusCode != http.StatusOK {
+		errMsg := strings.TrimSpace(string(respBody))
+		if errMsg == "" {
+			errMsg = fmt.Sprintf("%s returned status %d", strings.TrimPrefix(endpoint, "/"), resp.StatusCode)
+		}
+		return nil, errors.New(errMsg)
+	}
+
+	return respBody, nil
+}
+
+func (s *extractionService) parseDocument(path string) *pdfparser.Document {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	doc, err := pdfparser.Parse(data)
+	if err != nil {
+		return nil
+	}
+	return doc
+}
+
+func (s *extractionService) GetPages(ctx context.Context, pdfID uuid.UUID) ([]model.PDFPage, error) {
+	var pages []model.PDFPage
+	if err := s.DB.WithContext(ctx).
+		Where("pdf_id = ?", pdfID).
+		Order("page_number ASC").
+		Find(&pages).Error; err != nil {
+		return nil, err
+	}
+	return pages, nil
+}
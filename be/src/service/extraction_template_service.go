@@ -0,0 +1,474 @@
+package service
+
+import (
+	"app/src/config"
+	"app/src/model"
+	"app/src/utils"
+	"app/src/validation"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// A result that does not match the schema is sent back once with its errors
+const extractionAttempts = 2
+
+var (
+	ErrInvalidSchema     = errors.New("invalid extraction schema")
+	ErrTemplateNameTaken = errors.New("an extraction template with this name already exists")
+)
+
+type ExtractionTemplateService interface {
+	Create(ctx context.Context, payload validation.CreateExtractionTemplate, createdBy string) (*model.ExtractionTemplate, error)
+	GetAll(ctx context.Context) ([]model.ExtractionTemplate, error)
+	Get(ctx context.Context, ref string) (*model.ExtractionTemplate, error)
+	Update(ctx context.Context, id uuid.UUID, payload validation.UpdateExtractionTemplate) (*model.ExtractionTemplate, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+	Run(ctx context.Context, pdfID uuid.UUID, templateRef, createdBy string) (*model.ExtractionResult, error)
+	GetResult(ctx context.Context, id uuid.UUID) (*model.ExtractionResult, error)
+	GetResults(ctx context.Context, pdfID uuid.UUID) ([]model.ExtractionResult, error)
+	GetTemplateResults(ctx context.Context, templateID uuid.UUID, params validation.ExtractionResultsQuery) ([]model.ExtractionResult, *model.PaginationMeta, error)
+}
+
+type extractionTemplateService struct {
+	Log               *logrus.Logger
+	DB                *gorm.DB
+	ExtractionService ExtractionService
+}
+
+func NewExtractionTemplateService(db *gorm.DB, extractionService ExtractionService) ExtractionTemplateService {
+	return &extractionTemplateService{
+		Log:               utils.Log,
+		DB:                db,
+		ExtractionService: extractionService,
+	}
+}
+
+func (s *extractionTemplateService) Create(ctx context.Context, payload validation.CreateExtractionTemplate, createdBy string) (*model.ExtractionTemplate, error) {
+	schema, err := compactSchema(payload.Schema)
+	if err != nil {
+		return nil, err
+	}
+
+	template := &model.ExtractionTemplate{
+		Name:        strings.TrimSpace(payload.Name),
+		Description: payload.Description,
+		Schema:      schema,
+		Version:     1,
+		CreatedBy:   createdBy,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+
+	err = s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := s.checkName(tx, template.Name, uuid.Nil); err != nil {
+			return err
+		}
+		return tx.Create(template).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return template, nil
+}
+
+func (s *extractionTemplateService) GetAll(ctx context.Context) ([]model.ExtractionTemplate, error) {
+	var templates []model.ExtractionTemplate
+	if err := s.DB.WithContext(ctx).Order("name ASC").Find(&templates).Error; err != nil {
+		return nil, err
+	}
+	return templates, nil
+}
+
+// Get finds a template by ID or, case-insensitively, by name
+func (s *extractionTemplateService) Get(ctx context.Context, ref string) (*model.ExtractionTemplate, error) {
+	var template model.ExtractionTemplate
+	query := s.DB.WithContext(ctx)
+	if id, err := uuid.Parse(ref); err == nil {
+		query = query.Where("id = ?", id)
+	} else {
+		query = query.Where("lower(name) = lower(?)", strings.TrimSpace(ref))
+	}
+	if err := query.First(&template).Error; err != nil {
+		return nil, err
+	}
+	return &template, nil
+}
+
+func (s *extractionTemplateService) Update(ctx context.Context, id uuid.UUID, payload validation.UpdateExtractionTemplate) (*model.ExtractionTemplate, error) {
+	var template model.ExtractionTemplate
+
+	err := s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&template, "id = ?", id).Error; err != nil {
+			return err
+		}
+
+		updates := map[string]interface{}{
+			"updated_at": time.Now(),
+		}
+		if payload.Name != nil {
+			name := strings.TrimSpace(*payload.Name)
+			if err := s.checkName(tx, name, id); err != nil {
+				return err
+			}
+			updates["name"] = name
+		}
+		if payload.Description != nil {
+			updates["description"] = *payload.Description
+		}
+		if len(payload.Schema) > 0 {
+			schema, err := compactSchema(payload.Schema)
+			if err != nil {
+				return err
+			}
+			if !bytes.Equal(schema, template.Schema) {
+				updates["schema"] = string(schema)
+				updates["version"] = gorm.Expr("version + 1")
+			}
+		}
+
+		if err := tx.Model(&template).Updates(updates).Error; err != nil {
+			return err
+		}
+		return tx.First(&template, "id = ?", id).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &template, nil
+}
+
+// Delete hides the template, its results stay available by ID
+func (s *extractionTemplateService) Delete(ctx context.Context, id uuid.UUID) error {
+	result := s.DB.WithContext(ctx).Delete(&model.ExtractionTemplate{}, "id = ?", id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (s *extractionTemplateService) checkName(tx *gorm.DB, name string, exclude uuid.UUID) error {
+	var count int64
+	if err := tx.Model(&model.ExtractionTemplate{}).
+		Where("lower(name) = lower(?) AND id <> ?", name, exclude).
+		Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrTemplateNameTaken
+	}
+	return nil
+}
+
+// compactSchema validates a template schema and returns it without whitespace
+func compactSchema(data json.RawMessage) (json.RawMessage, error) {
+	if _, err := utils.ParseSchema(data); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSchema, err)
+	}
+
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, data); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSchema, err)
+	}
+	return buf.Bytes(), nil
+}
+
+// Run starts extracting the fields of a template from a PDF. The result is
+// returned with status "processing" and completed in the background.
+func (s *extractionTemplateService) Run(ctx context.Context, pdfID uuid.UUID, templateRef, createdBy string) (*model.ExtractionResult, error) {
+	template, err := s.Get(ctx, templateRef)
+	if err != nil {
+		return nil, err
+	}
+
+	pdf := &model.PDF{}
+	if err := s.DB.WithContext(ctx).First(pdf, "id = ?", pdfID).Error; err != nil {
+		return nil, err
+	}
+	if pdf.Status == "locked" {
+		return nil, ErrPDFLocked
+	}
+	if pdf.Status == "failed" {
+		return nil, ErrPDFUnavailable
+	}
+
+	result := &model.ExtractionResult{
+		TemplateID:      template.ID,
+		TemplateVersion: template.Version,
+		PDFID:           pdfID,
+		Status:          "processing",
+		CreatedBy:       createdBy,
+		CreatedAt:       time.Now(),
+		UpdatedAt:       time.Now(),
+	}
+	if err := s.DB.WithContext(ctx).Create(result).Error; err != nil {
+		return nil, err
+	}
+
+	s.createProcessingLog(ctx, "extraction", result.ID, "extract", "started", "Starting template extraction", map[string]interface{}{
+		"pdf_id":           pdfID.String(),
+		"template_id":      template.ID.String(),
+		"template_version": template.Version,
+	})
+
+	go s.extract(context.Background(), result, template, pdf)
+
+	return result, nil
+}
+
+type extractFieldsRequest struct {
+	Text   string              `json:"text"`
+	Schema json.RawMessage     `json:"schema"`
+	Errors []utils.SchemaError `json:"errors,omitempty"`
+}
+
+func (s *extractionTemplateService) extract(ctx context.Context, result *model.ExtractionResult, template *model.ExtractionTemplate, pdf *model.PDF) {
+	start := time.Now()
+
+	schema, err := utils.ParseSchema(template.Schema)
+	if err != nil {
+		s.failResult(ctx, result.ID, "Stored schema is invalid", err)
+		return
+	}
+
+	pages, err := s.ExtractionService.GetPages(ctx, pdf.ID)
+	if err == nil && len(pages) == 0 {
+		pages, err = s.ExtractionService.Extract(ctx, pdf, "")
+	}
+	if err != nil {
+		s.failResult(ctx, result.ID, "Failed to extract text from PDF", err)
+		return
+	}
+
+	text := joinPageText(pages)
+	if text == "" {
+		s.failResult(ctx, result.ID, "No text found in PDF, including OCR", nil)
+		return
+	}
+
+	request := extractFieldsRequest{
+		Text:   text,
+		Schema: template.Schema,
+	}
+
+	var data json.RawMessage
+	var violations []utils.SchemaError
+	var aiModel string
+	for attempt := 1; attempt <= extractionAttempts; attempt++ {
+		data, aiModel, err = s.callExtract(ctx, request)
+		if err != nil {
+			s.failResult(ctx, result.ID, "AI service error", err)
+			return
+		}
+
+		var value interface{}
+		if err := json.Unmarshal(data, &value); err != nil {
+			s.failResult(ctx, result.ID, "Invalid AI JSON response", err)
+			return
+		}
+
+		violations = utils.ValidateSchema(schema, value)
+		if len(violations) == 0 {
+			break
+		}
+		request.Errors = violations
+	}
+
+	status := "completed"
+	updates := map[string]interface{}{
+		"data":       string(data),
+		"errors":     nil,
+		"model":      aiModel,
+		"updated_at": time.Now(),
+	}
+	if len(violations) > 0 {
+		status = "invalid"
+		errorsJSON, _ := json.Marshal(violations)
+		updates["errors"] = string(errorsJSON)
+	}
+	updates["status"] = status
+
+	if err := s.DB.WithContext(ctx).Model(&model.ExtractionResult{}).
+		Where("id = ?", result.ID).
+		Updates(updates).Error; err != nil {
+		s.Log.WithError(err).Error("Failed to store extraction result")
+		return
+	}
+
+	s.createProcessingLog(ctx, "extraction", result.ID, "extract", status, "Template extraction finished", map[string]interface{}{
+		"violations":         len(violations),
+		"processing_time_ms": time.Since(start).Milliseconds(),
+	})
+}
+
+// callExtract sends document text and schema to the ML service and returns
+// the extracted JSON and the model that produced it
+func (s *extractionTemplateService) callExtract(ctx context.Context, request extractFieldsRequest) (json.RawMessage, string, error) {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return nil, "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", config.MLServiceURL+"/extract-fields", bytes.NewReader(body))
+	if err != nil {
+		return nil, "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := &http.Client{
+		Timeout: 2 * time.Minute,
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", err
+	}
+	if resp.StatusCode != 200 {
+		errMsg := strings.TrimSpace(string(respBody))
+		if errMsg == "" {
+			errMsg = fmt.Sprintf("AI returned status %d", resp.StatusCode)
+		}
+		return nil, "", errors.New(errMsg)
+	}
+
+	var parsed struct {
+		Data  json.RawMessage `json:"data"`
+		Model string          `json:"model"`
+	}
+	if err := json.Unmarshal(respBody, &parsed); err != nil {
+		return nil, "", err
+	}
+	if len(parsed.Data) == 0 {
+		return nil, "", errors.New("AI response missing data")
+	}
+	return parsed.Data, parsed.Model, nil
+}
+
+func (s *extractionTemplateService) failResult(ctx context.Context, id uuid.UUID, msg string, err error) {
+	meta := map[string]interface{}{}
+	message := msg
+	if err != nil {
+		meta["error"] = err.Error()
+		message = msg + ": " + err.Error()
+	}
+
+	errorsJSON, _ := json.Marshal([]utils.SchemaError{{Path: "$", Message: message}})
+	if dbErr := s.DB.WithContext(ctx).Model(&model.ExtractionResult{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":     "failed",
+			"errors":     string(errorsJSON),
+			"updated_at": time.Now(),
+		}).Error; dbErr != nil {
+		s.Log.WithError(dbErr).Error("Failed to mark extraction as failed")
+	}
+
+	s.createProcessingLog(ctx, "extraction", id, "extract", "failed", msg, meta)
+}
+
+func (s *extractionTemplateService) GetResult(ctx context.Context, id uuid.UUID) (*model.ExtractionResult, error) {
+	var result model.ExtractionResult
+	if err := s.DB.WithContext(ctx).First(&result, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (s *extractionTemplateService) GetResults(ctx context.Context, pdfID uuid.UUID) ([]model.ExtractionResult, error) {
+	var results []model.ExtractionResult
+	if err := s.DB.WithContext(ctx).
+		Where("pdf_id = ?", pdfID).
+		Order("created_at DESC").
+		Find(&results).Error; err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// GetTemplateResults returns the latest result of a template for every PDF,
+// optionally filtered by status or by the text value of a field
+func (s *extractionTemplateService) GetTemplateResults(ctx context.Context, templateID uuid.UUID, params validation.ExtractionResultsQuery) ([]model.ExtractionResult, *model.PaginationMeta, error) {
+	if err := s.DB.WithContext(ctx).First(&model.ExtractionTemplate{}, "id = ?", templateID).Error; err != nil {
+		return nil, nil, err
+	}
+
+	if params.Page < 1 {
+		params.Page = 1
+	}
+	if params.Limit < 1 {
+		params.Limit = 10
+	}
+
+	query := s.DB.WithContext(ctx).Model(&model.ExtractionResult{}).
+		Where("id IN (SELECT DISTINCT ON (pdf_id) id FROM extraction_results WHERE template_id = ? ORDER BY pdf_id, created_at DESC)", templateID)
+
+	if params.Status != "" {
+		query = query.Where("status = ?", params.Status)
+	}
+
+	if params.Field != "" {
+		path := "{" + strings.Join(strings.Split(params.Field, "."), ",") + "}"
+		query = query.Where("data #>> CAST(? AS text[]) = ?", path, params.Value)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, nil, err
+	}
+
+	var results []model.ExtractionResult
+	if err := query.Preload("PDF").
+		Order("created_at DESC").
+		Limit(params.Limit).
+		Offset(params.GetOffset()).
+		Find(&results).Error; err != nil {
+		return nil, nil, err
+	}
+
+	meta := model.NewPaginationMeta(params.Page, params.Limit, total)
+	return results, &meta, nil
+}
+
+func (s *extractionTemplateService) createProcessingLog(ctx context.Context, entityType string, entityID uuid.UUID, action, status, message string, metadata map[string]interface{}) {
+	var metaJSON *json.RawMessage
+
+	if metadata != nil {
+		b, _ := json.Marshal(metadata)
+		raw := json.RawMessage(b)
+		metaJSON = &raw
+	}
+
+	log := &model.ProcessingLog{
+		EntityType: entityType,
+		EntityID:   entityID,
+		Action:     action,
+		Status:     status,
+		Message:    message,
+		Metadata:   metaJSON,
+		CreatedAt:  time.Now(),
+	}
+
+	if err := s.DB.WithContext(ctx).Create(log).Error; err != nil {
+		s.Log.WithError(err).Error("Failed to create processing log")
+	}
+}
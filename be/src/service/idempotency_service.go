@@ -0,0 +1,102 @@
+package service
+
+import (
+	"app/src/model"
+	"app/src/utils"
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrIdempotencyKeyReused   = errors.New("idempotency key was already used with a different request")
+	ErrIdempotencyKeyInFlight = errors.New("a request with this idempotency key is still being processed")
+)
+
+type IdempotencyService interface {
+	Begin(ctx context.Context, principal, key, fingerprint string) (*model.IdempotencyKey, bool, error)
+	Complete(ctx context.Context, id uuid.UUID, status int, contentType string, body []byte) error
+	Release(ctx context.Context, id uuid.UUID) error
+}
+
+type idempotencyService struct {
+	Log *logrus.Logger
+	DB  *gorm.DB
+	TTL time.Duration
+}
+
+func NewIdempotencyService(db *gorm.DB, ttl time.Duration) IdempotencyService {
+	return &idempotencyService{
+		Log: utils.Log,
+		DB:  db,
+		TTL: ttl,
+	}
+}
+
+// Begin claims the key for a new request and returns true. When the key is
+// already known the stored record is returned for replay, unless it belongs to
+// a different request or the first request has not finished yet.
+func (s *idempotencyService) Begin(ctx context.Context, principal, key, fingerprint string) (*model.IdempotencyKey, bool, error) {
+	if err := s.DB.WithContext(ctx).
+		Where("expires_at < ?", time.Now()).
+		Delete(&model.IdempotencyKey{}).Error; err != nil {
+		s.Log.WithError(err).Warn("Failed to remove expired idempotency keys")
+	}
+
+	record := &model.IdempotencyKey{
+		ID:          uuid.New(),
+		Principal:   principal,
+		Key:         key,
+		Fingerprint: fingerprint,
+		Status:      "processing",
+		CreatedAt:   time.Now(),
+		ExpiresAt:   time.Now().Add(s.TTL),
+	}
+
+	result := s.DB.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(record)
+	if result.Error != nil {
+		return nil, false, result.Error
+	}
+	if result.RowsAffected == 1 {
+		return record, true, nil
+	}
+
+	var existing model.IdempotencyKey
+	if err := s.DB.WithContext(ctx).
+		Where("principal = ? AND key = ?", principal, key).
+		First(&existing).Error; err != nil {
+		return nil, false, err
+	}
+
+	if existing.Fingerprint != fingerprint {
+		return nil, false, ErrIdempotencyKeyReused
+	}
+	if existing.Status != "completed" {
+		return nil, false, ErrIdempotencyKeyInFlight
+	}
+
+	return &existing, false, nil
+}
+
+func (s *idempotencyService) Complete(ctx context.Context, id uuid.UUID, status int, contentType string, body []byte) error {
+	return s.DB.WithContext(ctx).Model(&model.IdempotencyKey{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":          "completed",
+			"response_status": status,
+			"response_type":   contentType,
+			"response_body":   body,
+		}).Error
+}
+
+// Release forgets a key whose request failed, so the client can retry it
+func (s *idempotencyService) Release(ctx context.Context, id uuid.UUID) error {
+	return s.DB.WithContext(ctx).Delete(&model.IdempotencyKey{}, id).Error
+}
@@ -0,0 +1,193 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+type OCRResult struct {
+	Text       string
+	Confidence float64
+}
+
+type OCREngine interface {
+	// Open prepares a document for recognition. Password-protected files are
+	// decrypted once here, the session must be closed when done.
+	Open(ctx context.Context, pdfPath string, password string) (OCRSession, error)
+}
+
+// OCRSession recognizes the pages of one opened document
+type OCRSession interface {
+	RecognizePage(ctx context.Context, pageNumber int) (*OCRResult, error)
+	Close() error
+}
+
+// tesseractOCR renders a page with pdftoppm and recognizes it with the tesseract CLI.
+// Password-protected files are decrypted with qpdf first, which reads the
+// password from stdin so it never shows up in the process list.
+type tesseractOCR struct {
+	TesseractPath string
+	PdftoppmPath  string
+	QpdfPath      string
+	Languages     string
+	DPI           int
+}
+
+func NewTesseractOCR(tesseractPath, pdftoppmPath, qpdfPath, languages string, dpi int) OCREngine {
+	return &tesseractOCR{
+		TesseractPath: tesseractPath,
+		PdftoppmPath:  pdftoppmPath,
+		QpdfPath:      qpdfPath,
+		Languages:     languages,
+		DPI:           dpi,
+	}
+}
+
+// tesseractSession keeps its rendered pages, and the decrypted copy of an
+// encrypted document, in a temp directory removed by Close
+type tesseractSession struct {
+	engine  *tesseractOCR
+	dir     string
+	pdfPath string
+}
+
+func (e *tesseractOCR) Open(ctx context.Context, pdfPath string, password string) (OCRSession, error) {
+	dir, err := os.MkdirTemp("", "ocr-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp directory: %w", err)
+	}
+
+	if password != "" {
+		if pdfPath, err = e.decrypt(ctx, dir, pdfPath, password); err != nil {
+			os.RemoveAll(dir)
+			return nil, err
+		}
+	}
+
+	return &tesseractSession{engine: e, dir: dir, pdfPath: pdfPath}, nil
+}
+
+func (s *tesseractSession) RecognizePage(ctx context.Context, pageNumber int) (*OCRResult, error) {
+	e := s.engine
+	page := strconv.Itoa(pageNumber)
+	prefix := filepath.Join(s.dir, "page-"+page)
+	defer os.Remove(prefix + ".png")
+	args := []string{"-f", page, "-l", page, "-r", strconv.Itoa(e.DPI), "-png", "-singlefile", s.pdfPath, prefix}
+
+	var stderr bytes.Buffer
+	render := exec.CommandContext(ctx, e.PdftoppmPath, args...)
+	render.Stderr = &stderr
+	if err := render.Run(); err != nil {
+		return nil, fmt.Errorf("failed to render page %d: %w: %s", pageNumber, err, strings.TrimSpace(stderr.String()))
+	}
+
+	stderr.Reset()
+	var stdout bytes.Buffer
+	recognize := exec.CommandContext(ctx, e.TesseractPath, prefix+".png", "stdout", "-l", e.Languages, "tsv")
+	recognize.Stdout = &stdout
+	recognize.Stderr = &stderr
+	if err := recognize.Run(); err != nil {
+		return nil, fmt.Errorf("tesseract failed on page %d: %w: %s", pageNumber, err, strings.TrimSpace(stderr.String()))
+	}
+
+	return parseTesseractTSV(stdout.String()), nil
+}
+
+func (s *tesseractSession) Close() error {
+	return os.RemoveAll(s.dir)
+}
+
+// decrypt writes a decrypted copy of the PDF into dir. The copy is created
+// with 0600 permissions and removed together with dir, so an extraction
+// decrypts the file once however many pages it recognizes.
+func (e *tesseractOCR) decrypt(ctx context.Context, dir, pdfPath, password string) (string, error) {
+	out, err := os.CreateTemp(dir, "decrypted-*.pdf")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp file: %w", err)
+	}
+	out.Close()
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, e.QpdfPath, "--password-file=-", "--decrypt", pdfPath, out.Name())
+	cmd.Stdin = strings.NewReader(password + "\n")
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		// Exit status 3 means qpdf succeeded with warnings
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) || exitErr.ExitCode() != 3 {
+			return "", fmt.Errorf("failed to decrypt PDF: %w: %s", err, strings.TrimSpace(stderr.String()))
+		}
+	}
+	return out.Name(), nil
+}
+
+// parseTesseractTSV rebuilds lines from word rows and averages word confidences
+func parseTesseractTSV(output string) *OCRResult {
+	var lines []string
+	var current []string
+	var lineKey string
+	var confSum float64
+	var words int
+
+	for i, row := range strings.Split(output, "\n") {
+		if i == 0 || row == "" {
+			continue
+		}
+		cols := strings.Split(row, "\t")
+		if len(cols) < 12 || cols[0] != "5" {
+			continue
+		}
+
+		text := strings.TrimSpace(cols[11])
+		conf, err := strconv.ParseFloat(cols[10], 64)
+		if text == "" || err != nil || conf < 0 {
+			continue
+		}
+
+		key := cols[2] + "-" + cols[3] + "-" + cols[4]
+		if key != lineKey && len(current) > 0 {
+			lines = append(lines, strings.Join(current, " "))
+			current = nil
+		}
+		lineKey = key
+		current = append(current, text)
+
+		confSum += conf
+		words++
+	}
+	if len(current) > 0 {
+		lines = append(lines, strings.Join(current, " "))
+	}
+
+	result := &OCRResult{Text: strings.Join(lines, "\n")}
+	if words > 0 {
+		result.Confidence = confSum / float64(words) / 100
+	}
+	return result
+}
+
+// noopOCR is used when OCR is disabled, scanned pages stay without text
+type noopOCR struct{}
+
+func NewNoopOCR() OCREngine {
+	return &noopOCR{}
+}
+
+func (e *noopOCR) Open(ctx context.Context, pdfPath string, password string) (OCRSession, error) {
+	return e, nil
+}
+
+func (e *noopOCR) RecognizePage(ctx context.Context, pageNumber int) (*OCRResult, error) {
+	return &OCRResult{}, nil
+}
+
+func (e *noopOCR) Close() error {
+	return nil
+}
@@ -3,22 +3,30 @@ package service
 import (
 	"app/src/config"
 	"app/src/model"
+	"app/src/pdfparser"
 	"app/src/utils"
 	"app/src/validation"
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"mime/multipart"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
+	"golang.org/x/text/unicode/norm"
 	"gorm.io/gorm"
 )
 
@@ -36,21 +44,36 @@ type PDFService interface {
 	Create(ctx context.Context, file *multipart.FileHeader) (*model.PDF, error)
 	GetByID(ctx context.Context, id uuid.UUID) (*model.PDF, error)
 	GetAll(ctx context.Context, params validation.QueryParams) ([]model.PDF, *model.PaginationMeta, error)
-	Delete(ctx context.Context, id uuid.UUID) error
+	Delete(ctx context.Context, id uuid.UUID, version int) error
 	UpdateStatus(ctx context.Context, id uuid.UUID, status string) error
+	Unlock(ctx context.Context, id uuid.UUID, password string) (*model.PDF, error)
+	SignedFileURL(id uuid.UUID, ttl time.Duration) (string, time.Time)
+	GetOutline(ctx context.Context, id uuid.UUID) ([]model.PDFOutlineItem, error)
+	ReindexOutlines(ctx context.Context) (map[string]int64, error)
 }
 
+var (
+	ErrPDFNotLocked    = errors.New("PDF is not locked")
+	ErrVersionConflict = errors.New("resource has been modified, reload it and try again")
+)
+
 type pdfService struct {
-	Log      *logrus.Logger
-	DB       *gorm.DB
-	Validate *validator.Validate
+	Log                   *logrus.Logger
+	DB                    *gorm.DB
+	Validate              *validator.Validate
+	ExtractionService     ExtractionService
+	Scanner               Scanner
+	ClassificationService ClassificationService
 }
 
-func NewPDFService(db *gorm.DB, validate *validator.Validate) PDFService {
+func NewPDFService(db *gorm.DB, validate *validator.Validate, extractionService ExtractionService, scanner Scanner, classificationService ClassificationService) PDFService {
 	return &pdfService{
-		Log:      utils.Log,
-		DB:       db,
-		Validate: validate,
+		Log:                   utils.Log,
+		DB:                    db,
+		Validate:              validate,
+		ExtractionService:     extractionService,
+		Scanner:               scanner,
+		ClassificationService: classificationService,
 	}
 }
 
@@ -79,12 +102,50 @@ func (s *pdfService) validatePDFFile(file multipart.File) error {
 	}
 
 	if !isValidPDF {
-		return fmt.Errorf("file is not a valid PDF (invalid magic number)")
+		return &pdfparser.Error{
+			Code:    pdfparser.CodeInvalidHeader,
+			Message: "file is not a valid PDF (invalid magic number)",
+		}
 	}
 
 	return nil
 }
 
+func (s *pdfService) validatePDFStructure(file multipart.File) (*pdfparser.Document, error) {
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	_, err = file.Seek(0, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reset file pointer: %w", err)
+	}
+
+	doc, err := pdfparser.Parse(data)
+	if err != nil {
+		return nil, err
+	}
+
+	version, err := strconv.ParseFloat(doc.Version, 64)
+	if err != nil || version < config.MinPDFVersion || version > config.MaxPDFVersion {
+		return nil, &pdfparser.Error{
+			Code:    pdfparser.CodeUnsupportedVersion,
+			Message: fmt.Sprintf("unsupported PDF version %s (supported %.1f to %.1f)", doc.Version, config.MinPDFVersion, config.MaxPDFVersion),
+		}
+	}
+
+	return doc, nil
+}
+
+func pdfErrorCode(err error) string {
+	var pdfErr *pdfparser.Error
+	if errors.As(err, &pdfErr) {
+		return pdfErr.Code
+	}
+	return ""
+}
+
 func (s *pdfService) validateFileSize(size int64) error {
 	if size < config.MinFileSize {
 		return fmt.Errorf("file is too small (minimum %d KB)", config.GetMinFileSizeKB())
@@ -105,22 +166,38 @@ func (s *pdfService) validateFileExtension(filename string) error {
 	return nil
 }
 
-func (s *pdfService) sanitizeFilename(filename string) string {
-	filename = filepath.Base(filename)
+// displayName keeps the original Unicode name for display and downloads. It is
+// normalized to NFC so names typed on different platforms compare equal, and
+// path components and control characters are removed. It is never used on disk.
+func (s *pdfService) displayName(filename string) string {
+	filename = norm.NFC.String(filename)
+	if i := strings.LastIndexAny(filename, "/\\"); i >= 0 {
+		filename = filename[i+1:]
+	}
 
-	safe := ""
-	for _, char := range filename {
-		if (char >= 'a' && char <= 'z') ||
-			(char >= 'A' && char <= 'Z') ||
-			(char >= '0' && char <= '9') ||
-			char == '.' || char == '-' || char == '_' {
-			safe += string(char)
-		} else {
-			safe += "_"
+	filename = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) || r == utf8.RuneError {
+			return -1
 		}
+		return r
+	}, filename)
+	filename = strings.TrimSpace(filename)
+
+	// original_name is varchar(255), keep the extension when truncating
+	if runes := []rune(filename); len(runes) > 255 {
+		ext := []rune(filepath.Ext(filename))
+		filename = string(runes[:255-len(ext)]) + string(ext)
+	}
+
+	if filename == "" || filename == filepath.Ext(filename) {
+		return "document.pdf"
 	}
+	return filename
+}
 
-	return safe
+// storageName is the key used on disk, independent of the user supplied name
+func (s *pdfService) storageName(id uuid.UUID) string {
+	return id.String() + ".pdf"
 }
 
 func (s *pdfService) Create(ctx context.Context, file *multipart.FileHeader) (*model.PDF, error) {
@@ -156,6 +233,17 @@ func (s *pdfService) Create(ctx context.Context, file *multipart.FileHeader) (*m
 	if err := s.validatePDFFile(src); err != nil {
 		s.createProcessingLog(ctx, "pdf", pdfID, "upload", "failed", "PDF magic number validation failed", map[string]interface{}{
 			"error":    err.Error(),
+			"code":     pdfErrorCode(err),
+			"filename": file.Filename,
+		})
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	doc, err := s.validatePDFStructure(src)
+	if err != nil {
+		s.createProcessingLog(ctx, "pdf", pdfID, "upload", "failed", "PDF structure validation failed", map[string]interface{}{
+			"error":    err.Error(),
+			"code":     pdfErrorCode(err),
 			"filename": file.Filename,
 		})
 		return nil, fmt.Errorf("validation failed: %w", err)
@@ -169,12 +257,12 @@ func (s *pdfService) Create(ctx context.Context, file *multipart.FileHeader) (*m
 		return nil, fmt.Errorf("failed to create upload directory: %w", err)
 	}
 
-	sanitizedName := s.sanitizeFilename(file.Filename)
-	filename := fmt.Sprintf("%s_%s", pdfID.String(), sanitizedName)
+	originalName := s.displayName(file.Filename)
+	filename := s.storageName(pdfID)
 	filePath := filepath.Join(uploadDir, filename)
 
 	normalizedPath := s.normalizePathForURL(filePath)
-	fileURL := fmt.Sprintf("/uploads/%s", filename)
+	fileURL, _ := s.SignedFileURL(pdfID, config.SignedURLTTL)
 
 	_, err = src.Seek(0, 0)
 	if err != nil {
@@ -191,9 +279,10 @@ func (s *pdfService) Create(ctx context.Context, file *multipart.FileHeader) (*m
 		})
 		return nil, fmt.Errorf("failed to create file: %w", err)
 	}
-	defer dst.Close()
 
-	written, err := io.Copy(dst, src)
+	hash := sha256.New()
+	written, err := io.Copy(io.MultiWriter(dst, hash), src)
+	dst.Close()
 	if err != nil {
 		os.Remove(filePath)
 		s.createProcessingLog(ctx, "pdf", pdfID, "upload", "failed", "Failed to save file content", map[string]interface{}{
@@ -211,19 +300,41 @@ func (s *pdfService) Create(ctx context.Context, file *multipart.FileHeader) (*m
 		return nil, fmt.Errorf("file size mismatch: expected %d, got %d", file.Size, written)
 	}
 
-	pdf := &model.PDF{
-		ID:           pdfID,
-		Filename:     filename,
-		OriginalName: file.Filename,
-		FilePath:     normalizedPath,
-		FileSize:     file.Size,
-		MimeType:     AllowedMimeType,
-		Status:       "completed",
-		UploadedAt:   time.Now(),
-		UpdatedAt:    time.Now(),
-		URL:          fileURL,
+	status := "completed"
+	if doc.RequiresPassword() {
+		status = "locked"
 	}
 
+	meta := doc.Metadata()
+
+	pdf := &model.PDF{
+		ID:            pdfID,
+		Filename:      filename,
+		OriginalName:  originalName,
+		FilePath:      normalizedPath,
+		FileSize:      file.Size,
+		MimeType:      AllowedMimeType,
+		ContentHash:   hex.EncodeToString(hash.Sum(nil)),
+		Status:        status,
+		Version:       1,
+		PageCount:     doc.PageCount,
+		PDFVersion:    doc.Version,
+		Title:         meta.Title,
+		Author:        meta.Author,
+		Subject:       meta.Subject,
+		Keywords:      meta.Keywords,
+		Producer:      meta.Producer,
+		PDFCreatedAt:  meta.CreationDate,
+		PDFModifiedAt: meta.ModDate,
+		HasTextLayer:  doc.HasTextLayer(),
+		UploadedAt:    time.Now(),
+		UpdatedAt:     time.Now(),
+		URL:           fileURL,
+	}
+	pdf.SearchTokens = utils.SegmentCJK(strings.Join([]string{
+		pdf.Title, pdf.OriginalName, pdf.Subject, pdf.Keywords, pdf.Author,
+	}, " "))
+
 	if err := s.DB.WithContext(ctx).Create(pdf).Error; err != nil {
 		os.Remove(filePath)
 		s.createProcessingLog(ctx, "pdf", pdfID, "upload", "failed", "Failed to create PDF record in database", map[string]interface{}{
@@ -233,15 +344,323 @@ func (s *pdfService) Create(ctx context.Context, file *multipart.FileHeader) (*m
 	}
 
 	s.createProcessingLog(ctx, "pdf", pdfID, "upload", "success", "PDF uploaded successfully", map[string]interface{}{
-		"filename":       file.Filename,
-		"size":           file.Size,
-		"sanitized_name": sanitizedName,
-		"file_path":      normalizedPath,
+		"filename":     originalName,
+		"size":         file.Size,
+		"storage_name": filename,
+		"file_path":    normalizedPath,
+		"pdf_version":  doc.Version,
+		"page_count":   doc.PageCount,
+		"encrypted":    doc.Encrypted,
 	})
 
+	if err := s.scanFile(ctx, pdf); err != nil {
+		return nil, err
+	}
+
+	// Bookmarks of encrypted files are read by the ML service once they open
+	if !doc.Encrypted {
+		s.storeOutline(ctx, pdfID, doc.Outline(), doc.PageCount)
+	}
+
+	if status == "locked" {
+		s.createProcessingLog(ctx, "pdf", pdfID, "upload", "locked", "PDF is password protected and must be unlocked before summarization", nil)
+	} else {
+		go s.extractText(*pdf, doc.Encrypted)
+	}
+
 	return pdf, nil
 }
 
+// storeOutline saves the bookmarks of the document unless it already has an
+// outline. Failures are only logged, the upload itself succeeded.
+func (s *pdfService) storeOutline(ctx context.Context, pdfID uuid.UUID, outline []pdfparser.OutlineItem, pageCount int) int {
+	items := flattenOutline(pdfID, outline, pageCount)
+	if len(items) == 0 {
+		return 0
+	}
+
+	err := s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := lockPDF(tx, "pdf_outline_items", pdfID); err != nil {
+			return err
+		}
+		var existing int64
+		if err := tx.Model(&model.PDFOutlineItem{}).Where("pdf_id = ?", pdfID).Count(&existing).Error; err != nil {
+			return err
+		}
+		if existing > 0 {
+			items = nil
+			return nil
+		}
+		return tx.CreateInBatches(&items, 200).Error
+	})
+	if err != nil {
+		s.createProcessingLog(ctx, "pdf", pdfID, "outline", "failed", "Failed to store outline", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return 0
+	}
+	if len(items) == 0 {
+		return 0
+	}
+
+	s.createProcessingLog(ctx, "pdf", pdfID, "outline", "success", "Outline extracted", map[string]interface{}{
+		"items": len(items),
+	})
+	return len(items)
+}
+
+// storeDecryptedOutline reads the bookmarks of an encrypted PDF with the ML
+// service, using the password when the file needs one
+func (s *pdfService) storeDecryptedOutline(ctx context.Context, pdf *model.PDF, password string) int {
+	outline, err := s.ExtractionService.ExtractOutline(ctx, pdf, password)
+	if err != nil {
+		s.createProcessingLog(ctx, "pdf", pdf.ID, "outline", "failed", "Failed to read outline", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return 0
+	}
+	return s.storeOutline(ctx, pdf.ID, outline, pdf.PageCount)
+}
+
+// ReindexOutlines reads the bookmarks of PDFs that have none stored, e.g.
+// uploaded before outlines were extracted. Files that need a password are
+// skipped, their outline is read when they are unlocked.
+func (s *pdfService) ReindexOutlines(ctx context.Context) (map[string]int64, error) {
+	counts := map[string]int64{}
+
+	var pdfs []model.PDF
+	err := s.DB.WithContext(ctx).
+		Where("status NOT IN ('locked', 'failed')").
+		Where("NOT EXISTS (SELECT 1 FROM pdf_outline_items o WHERE o.pdf_id = pdf_documents.id)").
+		FindInBatches(&pdfs, reindexBatchSize, func(tx *gorm.DB, batch int) error {
+			for i := range pdfs {
+				pdf := &pdfs[i]
+				data, err := os.ReadFile(pdf.FilePath)
+				if err != nil {
+					counts["failed"]++
+					continue
+				}
+				doc, err := pdfparser.Parse(data)
+				if err != nil {
+					counts["failed"]++
+					continue
+				}
+
+				var stored int
+				switch {
+				case !doc.Encrypted:
+					stored = s.storeOutline(ctx, pdf.ID, doc.Outline(), doc.PageCount)
+				case !doc.RequiresPassword():
+					stored = s.storeDecryptedOutline(ctx, pdf, "")
+				default:
+					counts["skipped"]++
+					continue
+				}
+				counts["pdfs"]++
+				counts["items"] += int64(stored)
+			}
+			return nil
+		}).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return counts, nil
+}
+
+// flattenOutline lists the bookmarks in document order. An item spans from its
+// page to the page before the next item of the same or a higher level, the
+// last ones run to the end of the document.
+func flattenOutline(pdfID uuid.UUID, outline []pdfparser.OutlineItem, pageCount int) []model.PDFOutlineItem {
+	var items []model.PDFOutlineItem
+
+	var walk func(nodes []pdfparser.OutlineItem, parentID *uuid.UUID, level int)
+	walk = func(nodes []pdfparser.OutlineItem, parentID *uuid.UUID, level int) {
+		for _, node := range nodes {
+			item := model.PDFOutlineItem{
+				ID:       uuid.New(),
+				PDFID:    pdfID,
+				ParentID: parentID,
+				Position: len(items),
+				Level:    level,
+				Title:    node.Title,
+			}
+			if node.Page > 0 {
+				page := node.Page
+				item.PageFrom = &page
+			}
+			items = append(items, item)
+
+			id := item.ID
+			walk(node.Children, &id, level+1)
+		}
+	}
+	walk(outline, nil, 0)
+
+	for i := range items {
+		if items[i].PageFrom == nil {
+			continue
+		}
+		end := pageCount
+		for j := i + 1; j < len(items); j++ {
+			if items[j].Level <= items[i].Level && items[j].PageFrom != nil {
+				end = *items[j].PageFrom - 1
+				break
+			}
+		}
+		if end < *items[i].PageFrom {
+			end = *items[i].PageFrom
+		}
+		items[i].PageTo = &end
+	}
+
+	return items
+}
+
+// GetOutline returns the bookmark tree of a PDF
+func (s *pdfService) GetOutline(ctx context.Context, id uuid.UUID) ([]model.PDFOutlineItem, error) {
+	if _, err := s.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+
+	var items []model.PDFOutlineItem
+	if err := s.DB.WithContext(ctx).
+		Where("pdf_id = ?", id).
+		Order("position ASC").
+		Find(&items).Error; err != nil {
+		return nil, err
+	}
+
+	return buildOutlineTree(items), nil
+}
+
+// buildOutlineTree nests items stored in document order under their parents
+func buildOutlineTree(items []model.PDFOutlineItem) []model.PDFOutlineItem {
+	children := map[uuid.UUID][]int{}
+	var roots []int
+	for i, item := range items {
+		if item.ParentID == nil {
+			roots = append(roots, i)
+		} else {
+			children[*item.ParentID] = append(children[*item.ParentID], i)
+		}
+	}
+
+	var build func(indexes []int) []model.PDFOutlineItem
+	build = func(indexes []int) []model.PDFOutlineItem {
+		nodes := make([]model.PDFOutlineItem, 0, len(indexes))
+		for _, i := range indexes {
+			node := items[i]
+			node.Children = build(children[node.ID])
+			nodes = append(nodes, node)
+		}
+		return nodes
+	}
+	return build(roots)
+}
+
+// extractText indexes the page text right after upload so the document is
+// searchable before any summary is generated
+func (s *pdfService) extractText(pdf model.PDF, encrypted bool) {
+	ctx := context.Background()
+
+	if encrypted {
+		s.storeDecryptedOutline(ctx, &pdf, "")
+	}
+
+	pages, err := s.ExtractionService.Extract(ctx, &pdf, "")
+	if err != nil {
+		s.createProcessingLog(ctx, "pdf", pdf.ID, "extract", "failed", "Background text extraction failed", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	s.createProcessingLog(ctx, "pdf", pdf.ID, "extract", "success", "Text extracted for search", map[string]interface{}{
+		"page_count": len(pages),
+	})
+
+	s.classify(pdf.ID)
+}
+
+// classify assigns a category once the text is available. Failures are only
+// logged, the PDF stays unclassified until it is classified again.
+func (s *pdfService) classify(id uuid.UUID) {
+	if _, err := s.ClassificationService.Classify(context.Background(), id); err != nil {
+		s.Log.WithError(err).WithField("pdf_id", id).Warn("Failed to classify PDF")
+	}
+}
+
+func (s *pdfService) scanFile(ctx context.Context, pdf *model.PDF) error {
+	file, err := os.Open(pdf.FilePath)
+	if err != nil {
+		return fmt.Errorf("failed to open file for scanning: %w", err)
+	}
+	result, err := s.Scanner.Scan(ctx, file)
+	file.Close()
+
+	if err != nil {
+		s.markFailed(ctx, pdf, "Malware scan could not be completed")
+		s.createProcessingLog(ctx, "pdf", pdf.ID, "scan", "failed", "Malware scan could not be completed", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return fmt.Errorf("malware scan failed: %w", err)
+	}
+
+	if !result.Infected {
+		s.createProcessingLog(ctx, "pdf", pdf.ID, "scan", "success", "No malware detected", nil)
+		return nil
+	}
+
+	quarantinePath, err := s.quarantine(pdf)
+	if err != nil {
+		// Never leave an infected file in the public upload directory
+		os.Remove(pdf.FilePath)
+		s.Log.WithError(err).Error("Failed to quarantine infected file, file removed")
+	} else {
+		pdf.FilePath = quarantinePath
+	}
+
+	s.markFailed(ctx, pdf, fmt.Sprintf("Malware detected: %s", result.Signature))
+	s.createProcessingLog(ctx, "pdf", pdf.ID, "scan", "infected", "Malware detected, file quarantined", map[string]interface{}{
+		"signature":       result.Signature,
+		"quarantine_path": quarantinePath,
+	})
+
+	return ErrMalwareDetected
+}
+
+func (s *pdfService) quarantine(pdf *model.PDF) (string, error) {
+	if err := os.MkdirAll(config.QuarantineDir, 0o700); err != nil {
+		return "", err
+	}
+
+	target := s.normalizePathForURL(filepath.Join(config.QuarantineDir, pdf.Filename))
+	if err := os.Rename(pdf.FilePath, target); err != nil {
+		return "", err
+	}
+
+	return target, nil
+}
+
+func (s *pdfService) markFailed(ctx context.Context, pdf *model.PDF, reason string) {
+	pdf.Status = "failed"
+	pdf.StatusReason = reason
+	pdf.Version++
+
+	if err := s.DB.WithContext(ctx).Model(&model.PDF{}).
+		Where("id = ?", pdf.ID).
+		Updates(map[string]interface{}{
+			"status":        pdf.Status,
+			"status_reason": reason,
+			"file_path":     pdf.FilePath,
+			"version":       gorm.Expr("version + 1"),
+			"updated_at":    time.Now(),
+		}).Error; err != nil {
+		s.Log.WithError(err).Error("Failed to mark PDF as failed")
+	}
+}
+
 func (s *pdfService) GetByID(ctx context.Context, id uuid.UUID) (*model.PDF, error) {
 	var pdf model.PDF
 	if err := s.DB.WithContext(ctx).Where("id = ?", id).First(&pdf).Error; err != nil {
@@ -250,6 +669,16 @@ func (s *pdfService) GetByID(ctx context.Context, id uuid.UUID) (*model.PDF, err
 	return &pdf, nil
 }
 
+// SignedFileURL returns a link to the file endpoint that works without an API
+// key until it expires, so the file can be embedded in viewers and iframes.
+func (s *pdfService) SignedFileURL(id uuid.UUID, ttl time.Duration) (string, time.Time) {
+	expires := time.Now().Add(ttl)
+	path := fmt.Sprintf("/v1/pdfs/%s/file", id)
+	signature := utils.SignPath(config.SigningSecret, path, expires.Unix())
+
+	return fmt.Sprintf("%s?expires=%d&signature=%s", path, expires.Unix(), signature), expires
+}
+
 func (s *pdfService) GetAll(ctx context.Context, params validation.QueryParams) ([]model.PDF, *model.PaginationMeta, error) {
 	var pdfs []model.PDF
 	var total int64
@@ -257,13 +686,47 @@ func (s *pdfService) GetAll(ctx context.Context, params validation.QueryParams)
 	query := s.DB.WithContext(ctx).Model(&model.PDF{})
 
 	if params.Search != "" {
-		query = query.Where("original_name ILIKE ?", "%"+params.Search+"%")
+		query = query.Where("(original_name ILIKE ? OR title ILIKE ?)", "%"+params.Search+"%", "%"+params.Search+"%")
 	}
 
 	if params.Status != "" {
 		query = query.Where("status = ?", params.Status)
 	}
 
+	if params.Category == "unclassified" {
+		query = query.Where("category IS NULL")
+	} else if params.Category != "" {
+		query = query.Where("category = ?", params.Category)
+	}
+
+	if params.Author != "" {
+		query = query.Where("author ILIKE ?", "%"+params.Author+"%")
+	}
+
+	if params.Keyword != "" {
+		query = query.Where("keywords ILIKE ?", "%"+params.Keyword+"%")
+	}
+
+	if params.Producer != "" {
+		query = query.Where("producer ILIKE ?", "%"+params.Producer+"%")
+	}
+
+	if params.PDFVersion != "" {
+		query = query.Where("pdf_version = ?", params.PDFVersion)
+	}
+
+	if params.HasTextLayer != "" {
+		query = query.Where("has_text_layer = ?", params.HasTextLayer == "true")
+	}
+
+	if params.MinPages > 0 {
+		query = query.Where("page_count >= ?", params.MinPages)
+	}
+
+	if params.MaxPages > 0 {
+		query = query.Where("page_count <= ?", params.MaxPages)
+	}
+
 	if params.DateFrom != "" {
 		query = query.Where("uploaded_at >= ?", params.DateFrom+" 00:00:00")
 	}
@@ -277,10 +740,16 @@ func (s *pdfService) GetAll(ctx context.Context, params validation.QueryParams)
 	}
 
 	validSortFields := map[string]string{
-		"uploaded_at":   "uploaded_at",
-		"updated_at":    "updated_at",
-		"original_name": "original_name",
-		"status":        "status",
+		"uploaded_at":     "uploaded_at",
+		"updated_at":      "updated_at",
+		"original_name":   "original_name",
+		"status":          "status",
+		"page_count":      "page_count",
+		"title":           "title",
+		"author":          "author",
+		"pdf_created_at":  "pdf_created_at",
+		"pdf_modified_at": "pdf_modified_at",
+		"category":        "category",
 	}
 
 	sortField := validSortFields[params.SortBy]
@@ -305,19 +774,32 @@ func (s *pdfService) GetAll(ctx context.Context, params validation.QueryParams)
 	return pdfs, &meta, nil
 }
 
-func (s *pdfService) Delete(ctx context.Context, id uuid.UUID) error {
+// Delete removes the PDF and its file. A non-zero version must match the
+// current one, otherwise ErrVersionConflict is returned.
+func (s *pdfService) Delete(ctx context.Context, id uuid.UUID, version int) error {
 	pdf, err := s.GetByID(ctx, id)
 	if err != nil {
 		return err
 	}
+	if version > 0 && pdf.Version != version {
+		return ErrVersionConflict
+	}
 
 	s.createProcessingLog(ctx, "pdf", id, "delete", "started", "Starting PDF deletion", nil)
 
-	if err := s.DB.WithContext(ctx).Delete(&model.PDF{}, id).Error; err != nil {
+	query := s.DB.WithContext(ctx)
+	if version > 0 {
+		query = query.Where("version = ?", version)
+	}
+	result := query.Delete(&model.PDF{}, id)
+	if result.Error != nil {
 		s.createProcessingLog(ctx, "pdf", id, "delete", "failed", "Failed to delete PDF from database", map[string]interface{}{
-			"error": err.Error(),
+			"error": result.Error.Error(),
 		})
-		return err
+		return result.Error
+	}
+	if version > 0 && result.RowsAffected == 0 {
+		return ErrVersionConflict
 	}
 
 	osFilePath := strings.ReplaceAll(pdf.FilePath, "/", string(filepath.Separator))
@@ -335,10 +817,48 @@ func (s *pdfService) UpdateStatus(ctx context.Context, id uuid.UUID, status stri
 		Where("id = ?", id).
 		Updates(map[string]interface{}{
 			"status":     status,
+			"version":    gorm.Expr("version + 1"),
 			"updated_at": time.Now(),
 		}).Error
 }
 
+func (s *pdfService) Unlock(ctx context.Context, id uuid.UUID, password string) (*model.PDF, error) {
+	pdf, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if pdf.Status != "locked" {
+		return nil, ErrPDFNotLocked
+	}
+
+	s.createProcessingLog(ctx, "pdf", id, "unlock", "started", "Starting PDF unlock", nil)
+
+	pages, err := s.ExtractionService.Extract(ctx, pdf, password)
+	if err != nil {
+		s.createProcessingLog(ctx, "pdf", id, "unlock", "failed", "Failed to unlock PDF", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return nil, err
+	}
+
+	if err := s.UpdateStatus(ctx, id, "completed"); err != nil {
+		return nil, err
+	}
+	pdf.Status = "completed"
+	pdf.Version++
+
+	s.createProcessingLog(ctx, "pdf", id, "unlock", "success", "PDF unlocked and text extracted", map[string]interface{}{
+		"page_count": len(pages),
+	})
+
+	s.storeDecryptedOutline(ctx, pdf, password)
+
+	go s.classify(id)
+
+	return pdf, nil
+}
+
 func (s *pdfService) createProcessingLog(ctx context.Context, entityType string, entityID uuid.UUID, action, status, message string, metadata map[string]interface{}) {
 	var metaJSON *json.RawMessage
 
@@ -0,0 +1,126 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+)
+
+var ErrMalwareDetected = errors.New("malware detected in uploaded file")
+
+type ScanResult struct {
+	Infected  bool
+	Signature string
+}
+
+type Scanner interface {
+	Scan(ctx context.Context, r io.Reader) (*ScanResult, error)
+}
+
+// clamAVScanner streams files to clamd using the INSTREAM command
+type clamAVScanner struct {
+	Network   string
+	Address   string
+	Timeout   time.Duration
+	ChunkSize int
+}
+
+// NewClamAVScanner accepts "host:port" for TCP or "unix:/path/to/clamd.sock"
+func NewClamAVScanner(address string, timeout time.Duration) Scanner {
+	network := "tcp"
+	if strings.HasPrefix(address, "unix:") {
+		network = "unix"
+		address = strings.TrimPrefix(address, "unix:")
+	}
+
+	return &clamAVScanner{
+		Network:   network,
+		Address:   address,
+		Timeout:   timeout,
+		ChunkSize: 64 * 1024,
+	}
+}
+
+func (s *clamAVScanner) Scan(ctx context.Context, r io.Reader) (*ScanResult, error) {
+	dialer := &net.Dialer{Timeout: s.Timeout}
+	conn, err := dialer.DialContext(ctx, s.Network, s.Address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to clamd: %w", err)
+	}
+	defer conn.Close()
+
+	if err := conn.SetDeadline(time.Now().Add(s.Timeout)); err != nil {
+		return nil, err
+	}
+
+	if _, err := conn.Write([]byte("zINSTREAM\x00")); err != nil {
+		return nil, fmt.Errorf("failed to send INSTREAM command: %w", err)
+	}
+
+	buf := make([]byte, s.ChunkSize)
+	size := make([]byte, 4)
+	for {
+		n, readErr := r.Read(buf)
+		if n > 0 {
+			binary.BigEndian.PutUint32(size, uint32(n))
+			if _, err := conn.Write(size); err != nil {
+				return nil, fmt.Errorf("failed to stream file to clamd: %w", err)
+			}
+			if _, err := conn.Write(buf[:n]); err != nil {
+				return nil, fmt.Errorf("failed to stream file to clamd: %w", err)
+			}
+		}
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil {
+			return nil, fmt.Errorf("failed to read file: %w", readErr)
+		}
+	}
+
+	// A zero-length chunk terminates the stream
+	if _, err := conn.Write([]byte{0, 0, 0, 0}); err != nil {
+		return nil, fmt.Errorf("failed to finish stream: %w", err)
+	}
+
+	reply, err := io.ReadAll(conn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read clamd reply: %w", err)
+	}
+
+	return parseClamdReply(string(bytes.TrimRight(reply, "\x00\n")))
+}
+
+// parseClamdReply handles "stream: OK", "stream: <signature> FOUND" and "... ERROR"
+func parseClamdReply(reply string) (*ScanResult, error) {
+	reply = strings.TrimSpace(strings.TrimPrefix(reply, "stream:"))
+
+	switch {
+	case reply == "OK":
+		return &ScanResult{}, nil
+	case strings.HasSuffix(reply, " FOUND"):
+		return &ScanResult{
+			Infected:  true,
+			Signature: strings.TrimSuffix(reply, " FOUND"),
+		}, nil
+	}
+
+	return nil, fmt.Errorf("clamd error: %s", reply)
+}
+
+// noopScanner reports every file as clean, used when no scanner is configured
+type noopScanner struct{}
+
+func NewNoopScanner() Scanner {
+	return &noopScanner{}
+}
+
+func (s *noopScanner) Scan(ctx context.Context, r io.Reader) (*ScanResult, error) {
+	return &ScanResult{}, nil
+}
@@ -0,0 +1,305 @@
+package service
+
+import (
+	"app/src/model"
+	"app/src/utils"
+	"app/src/validation"
+	"context"
+	"errors"
+	"html"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// Sentinels used by ts_headline, replaced by <mark> after the snippet is escaped
+const (
+	highlightStart = "[[[mark]]]"
+	highlightStop  = "[[[/mark]]]"
+)
+
+// cjkSnippetRunes is roughly the length of a ts_headline snippet in CJK text
+const cjkSnippetRunes = 120
+
+var headlineOptions = "StartSel=" + highlightStart + ", StopSel=" + highlightStop +
+	", MaxWords=35, MinWords=15, MaxFragments=2, FragmentDelimiter=\" … \""
+
+const reindexBatchSize = 200
+
+var ErrInvalidSearchPDF = errors.New("pdf_id must be a valid UUID")
+
+type SearchResult struct {
+	Type         string     `gorm:"column:type" json:"type"` // pdf, page, summary or chunk
+	PDFID        uuid.UUID  `gorm:"column:pdf_id" json:"pdf_id"`
+	SummaryID    *uuid.UUID `gorm:"column:summary_id" json:"summary_id,omitempty"`
+	PageNumber   *int       `gorm:"column:page_number" json:"page_number,omitempty"`
+	OriginalName string     `gorm:"column:original_name" json:"original_name"`
+	Title        string     `gorm:"column:title" json:"title"`
+	Rank         float64    `gorm:"column:rank" json:"rank"`
+	Snippet      string     `gorm:"column:snippet" json:"snippet"`
+	ChunkIndex   *int       `gorm:"-" json:"chunk_index,omitempty"`
+}
+
+type SearchService interface {
+	Search(ctx context.Context, params validation.SearchQuery) ([]SearchResult, *model.PaginationMeta, error)
+	Reindex(ctx context.Context) (map[string]int64, error)
+}
+
+type searchService struct {
+	Log          *logrus.Logger
+	DB           *gorm.DB
+	ChunkService ChunkService
+}
+
+func NewSearchService(db *gorm.DB, chunkService ChunkService) SearchService {
+	return &searchService{
+		Log:          utils.Log,
+		DB:           db,
+		ChunkService: chunkService,
+	}
+}
+
+// hitsQuery returns the matching rows of every searched table with their rank.
+// Snippets are built afterwards for the requested page only.
+func (s *searchService) hitsQuery(params validation.SearchQuery) (string, []interface{}) {
+	var parts []string
+	var args []interface{}
+
+	pdfFilter := ""
+	if params.PDFID != "" {
+		pdfFilter = " AND p.id = ?"
+	}
+
+	if params.Type == "" || params.Type == "pdf" {
+		parts = append(parts, `
+			SELECT 'pdf' AS type, p.id AS pdf_id, NULL::uuid AS summary_id, NULL::int AS page_number,
+			       ts_rank(p.search_vector, q.query) AS rank
+			FROM pdf_documents p, q
+			WHERE p.deleted_at IS NULL AND p.search_vector @@ q.query`+pdfFilter)
+		if params.PDFID != "" {
+			args = append(args, params.PDFID)
+		}
+	}
+
+	if params.Type == "" || params.Type == "page" {
+		parts = append(parts, `
+			SELECT 'page', pg.pdf_id, NULL::uuid, pg.page_number, ts_rank(pg.search_vector, q.query)
+			FROM pdf_pages pg
+			JOIN pdf_documents p ON p.id = pg.pdf_id AND p.deleted_at IS NULL, q
+			WHERE pg.search_vector @@ q.query`+pdfFilter)
+		if params.PDFID != "" {
+			args = append(args, params.PDFID)
+		}
+	}
+
+	if params.Type == "" || params.Type == "summary" {
+		parts = append(parts, `
+			SELECT 'summary', s.pdf_id, s.id, NULL::int, ts_rank(s.search_vector, q.query)
+			FROM summaries s
+			JOIN pdf_documents p ON p.id = s.pdf_id AND p.deleted_at IS NULL, q
+			WHERE s.deleted_at IS NULL AND s.status = 'completed' AND s.search_vector @@ q.query`+pdfFilter)
+		if params.PDFID != "" {
+			args = append(args, params.PDFID)
+		}
+	}
+
+	return strings.Join(parts, "\nUNION ALL\n"), args
+}
+
+func (s *searchService) Search(ctx context.Context, params validation.SearchQuery) ([]SearchResult, *model.PaginationMeta, error) {
+	if params.Mode == "semantic" {
+		return s.semanticSearch(ctx, params)
+	}
+
+	hits, hitArgs := s.hitsQuery(params)
+
+	// CJK queries are segmented into bigrams like the indexed search_tokens
+	tsquery, terms, cjk := utils.CJKQuery(params.Query)
+	queryExpr, queryArg := "websearch_to_tsquery('simple', ?)", params.Query
+	if cjk {
+		queryExpr, queryArg = "to_tsquery('simple', ?)", tsquery
+	}
+	queryCTE := "WITH q AS (SELECT " + queryExpr + " AS query), hits AS (" + hits + ")"
+
+	var total int64
+	countArgs := append([]interface{}{queryArg}, hitArgs...)
+	if err := s.DB.WithContext(ctx).
+		Raw(queryCTE+" SELECT COUNT(*) FROM hits", countArgs...).
+		Scan(&total).Error; err != nil {
+		return nil, nil, err
+	}
+
+	// ts_headline cannot match bigram lexemes in unsegmented text, CJK snippets
+	// are cut and highlighted in Go instead
+	body := `CASE r.type
+			           WHEN 'pdf' THEN coalesce(nullif(p.title, ''), p.original_name)
+			           WHEN 'page' THEN (
+			               SELECT coalesce(nullif(pg.text, ''), pg.ocr_text, '') FROM pdf_pages pg
+			               WHERE pg.pdf_id = r.pdf_id AND pg.page_number = r.page_number)
+			           ELSE (SELECT coalesce(s.content, '') FROM summaries s WHERE s.id = r.summary_id)
+			       END`
+	snippetExpr := "ts_headline('simple', " + body + ", q.query, ?)"
+	if cjk {
+		snippetExpr = body
+	}
+
+	results := []SearchResult{}
+	if total > 0 {
+		sql := queryCTE + `,
+			ranked AS (
+				SELECT * FROM hits
+				ORDER BY rank DESC, pdf_id, page_number NULLS FIRST
+				LIMIT ? OFFSET ?
+			)
+			SELECT r.type, r.pdf_id, r.summary_id, r.page_number, r.rank, p.original_name, coalesce(p.title, '') AS title,
+			       ` + snippetExpr + ` AS snippet
+			FROM ranked r
+			JOIN pdf_documents p ON p.id = r.pdf_id
+			CROSS JOIN q
+			ORDER BY r.rank DESC, r.pdf_id, r.page_number NULLS FIRST`
+
+		args := append(countArgs, params.Limit, params.GetOffset())
+		if !cjk {
+			args = append(args, headlineOptions)
+		}
+		if err := s.DB.WithContext(ctx).Raw(sql, args...).Scan(&results).Error; err != nil {
+			return nil, nil, err
+		}
+	}
+
+	for i := range results {
+		if cjk {
+			results[i].Snippet = utils.HighlightTerms(results[i].Snippet, terms, cjkSnippetRunes)
+		} else {
+			results[i].Snippet = highlightSnippet(results[i].Snippet)
+		}
+	}
+
+	meta := model.NewPaginationMeta(params.Page, params.Limit, total)
+	return results, &meta, nil
+}
+
+// semanticSearch returns the chunks closest in meaning to the query. The type
+// filter does not apply, every result is a chunk of a page.
+func (s *searchService) semanticSearch(ctx context.Context, params validation.SearchQuery) ([]SearchResult, *model.PaginationMeta, error) {
+	var pdfID *uuid.UUID
+	if params.PDFID != "" {
+		id, err := uuid.Parse(params.PDFID)
+		if err != nil {
+			return nil, nil, ErrInvalidSearchPDF
+		}
+		pdfID = &id
+	}
+
+	matches, total, err := s.ChunkService.Search(ctx, params.Query, pdfID, params.Limit, params.GetOffset())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ids := make([]uuid.UUID, 0, len(matches))
+	for _, match := range matches {
+		ids = append(ids, match.PDFID)
+	}
+	var pdfs []model.PDF
+	if len(ids) > 0 {
+		if err := s.DB.WithContext(ctx).
+			Select("id", "original_name", "title").
+			Where("id IN ?", ids).
+			Find(&pdfs).Error; err != nil {
+			return nil, nil, err
+		}
+	}
+	byID := make(map[uuid.UUID]model.PDF, len(pdfs))
+	for _, pdf := range pdfs {
+		byID[pdf.ID] = pdf
+	}
+
+	results := make([]SearchResult, 0, len(matches))
+	for _, match := range matches {
+		page, chunk := match.PageNumber, match.ChunkIndex
+		results = append(results, SearchResult{
+			Type:         "chunk",
+			PDFID:        match.PDFID,
+			PageNumber:   &page,
+			ChunkIndex:   &chunk,
+			OriginalName: byID[match.PDFID].OriginalName,
+			Title:        byID[match.PDFID].Title,
+			Rank:         match.Score,
+			Snippet:      html.EscapeString(match.Content),
+		})
+	}
+
+	meta := model.NewPaginationMeta(params.Page, params.Limit, total)
+	return results, &meta, nil
+}
+
+// highlightSnippet escapes document text before adding the <mark> tags, so the
+// snippet can be rendered as HTML
+func highlightSnippet(snippet string) string {
+	escaped := html.EscapeString(strings.Join(strings.Fields(snippet), " "))
+	escaped = strings.ReplaceAll(escaped, highlightStart, "<mark>")
+	return strings.ReplaceAll(escaped, highlightStop, "</mark>")
+}
+
+// Reindex recomputes the CJK search tokens of every document, page and summary,
+// e.g. for rows stored before the tokens were introduced
+func (s *searchService) Reindex(ctx context.Context) (map[string]int64, error) {
+	counts := map[string]int64{}
+	db := s.DB.WithContext(ctx)
+
+	var pdfs []model.PDF
+	err := db.Select("id", "title", "original_name", "subject", "keywords", "author").
+		FindInBatches(&pdfs, reindexBatchSize, func(tx *gorm.DB, batch int) error {
+			for _, pdf := range pdfs {
+				tokens := utils.SegmentCJK(strings.Join([]string{
+					pdf.Title, pdf.OriginalName, pdf.Subject, pdf.Keywords, pdf.Author,
+				}, " "))
+				if err := db.Model(&model.PDF{}).Where("id = ?", pdf.ID).
+					UpdateColumn("search_tokens", tokens).Error; err != nil {
+					return err
+				}
+			}
+			counts["pdfs"] += int64(len(pdfs))
+			return nil
+		}).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var pages []model.PDFPage
+	err = db.Select("id", "text", "ocr_text").
+		FindInBatches(&pages, reindexBatchSize, func(tx *gorm.DB, batch int) error {
+			for _, page := range pages {
+				if err := db.Model(&model.PDFPage{}).Where("id = ?", page.ID).
+					UpdateColumn("search_tokens", utils.SegmentCJK(page.Text+" "+page.OCRText)).Error; err != nil {
+					return err
+				}
+			}
+			counts["pages"] += int64(len(pages))
+			return nil
+		}).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var summaries []model.Summary
+	err = db.Select("id", "content").
+		FindInBatches(&summaries, reindexBatchSize, func(tx *gorm.DB, batch int) error {
+			for _, summary := range summaries {
+				if err := db.Model(&model.Summary{}).Where("id = ?", summary.ID).
+					UpdateColumn("search_tokens", utils.SegmentCJK(summary.Content)).Error; err != nil {
+					return err
+				}
+			}
+			counts["summaries"] += int64(len(summaries))
+			return nil
+		}).Error
+	if err != nil {
+		return nil, err
+	}
+
+	s.Log.WithField("counts", counts).Info("Search tokens reindexed")
+	return counts, nil
+}
@@ -0,0 +1,227 @@
+package service
+
+import (
+	"app/src/model"
+	"app/src/utils"
+	"app/src/validation"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrShareNotFound         = errors.New("share not found")
+	ErrShareExpired          = errors.New("share has expired")
+	ErrSharePasswordRequired = errors.New("share is password protected")
+	ErrInvalidSharePassword  = errors.New("invalid share password")
+	ErrSummaryNotCompleted   = errors.New("summary is not completed yet")
+)
+
+type ShareService interface {
+	Create(ctx context.Context, summaryID uuid.UUID, payload validation.CreateShare, createdBy string) (*model.SummaryShare, error)
+	GetActiveByPDF(ctx context.Context, pdfID uuid.UUID) ([]model.SummaryShare, error)
+	Revoke(ctx context.Context, id uuid.UUID) error
+	View(ctx context.Context, token, password string) (*model.Summary, *model.PDF, error)
+}
+
+type shareService struct {
+	Log      *logrus.Logger
+	DB       *gorm.DB
+	Validate *validator.Validate
+}
+
+func NewShareService(db *gorm.DB, validate *validator.Validate) ShareService {
+	return &shareService{
+		Log:      utils.Log,
+		DB:       db,
+		Validate: validate,
+	}
+}
+
+// newShareToken returns a 256-bit URL safe token and the hash stored in the database
+func newShareToken() (string, string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(b)
+	return token, hashShareToken(token), nil
+}
+
+func hashShareToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func (s *shareService) Create(ctx context.Context, summaryID uuid.UUID, payload validation.CreateShare, createdBy string) (*model.SummaryShare, error) {
+	var summary model.Summary
+	if err := s.DB.WithContext(ctx).First(&summary, "id = ?", summaryID).Error; err != nil {
+		return nil, err
+	}
+	if summary.Status != "completed" {
+		return nil, ErrSummaryNotCompleted
+	}
+
+	token, tokenHash, err := newShareToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate share token: %w", err)
+	}
+
+	share := &model.SummaryShare{
+		SummaryID: summary.ID,
+		PDFID:     summary.PDFID,
+		TokenHash: tokenHash,
+		CreatedBy: createdBy,
+		CreatedAt: time.Now(),
+	}
+
+	if payload.ExpiresIn > 0 {
+		expiresAt := time.Now().Add(time.Duration(payload.ExpiresIn) * time.Second)
+		share.ExpiresAt = &expiresAt
+	}
+
+	if payload.Password != "" {
+		hash, err := bcrypt.GenerateFromPassword([]byte(payload.Password), bcrypt.DefaultCost)
+		if err != nil {
+			return nil, fmt.Errorf("failed to hash share password: %w", err)
+		}
+		share.PasswordHash = string(hash)
+	}
+
+	if err := s.DB.WithContext(ctx).Create(share).Error; err != nil {
+		return nil, fmt.Errorf("failed to create share: %w", err)
+	}
+
+	share.HasPassword = share.PasswordHash != ""
+	share.Token = token
+	share.URL = fmt.Sprintf("/v1/public/shares/%s", token)
+
+	s.createProcessingLog(ctx, "summary", summary.ID, "share", "success", "Summary share created", map[string]interface{}{
+		"share_id":     share.ID,
+		"created_by":   createdBy,
+		"expires_at":   share.ExpiresAt,
+		"has_password": share.HasPassword,
+	})
+
+	return share, nil
+}
+
+func (s *shareService) GetActiveByPDF(ctx context.Context, pdfID uuid.UUID) ([]model.SummaryShare, error) {
+	var shares []model.SummaryShare
+	if err := s.DB.WithContext(ctx).
+		Where("pdf_id = ? AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > ?)", pdfID, time.Now()).
+		Order("created_at DESC").
+		Find(&shares).Error; err != nil {
+		return nil, err
+	}
+
+	for i := range shares {
+		shares[i].HasPassword = shares[i].PasswordHash != ""
+	}
+	return shares, nil
+}
+
+func (s *shareService) Revoke(ctx context.Context, id uuid.UUID) error {
+	result := s.DB.WithContext(ctx).Model(&model.SummaryShare{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrShareNotFound
+	}
+
+	s.createProcessingLog(ctx, "share", id, "revoke", "success", "Summary share revoked", nil)
+	return nil
+}
+
+// View resolves a public token to its summary and counts the view. Revoked and
+// unknown tokens are indistinguishable to the caller.
+func (s *shareService) View(ctx context.Context, token, password string) (*model.Summary, *model.PDF, error) {
+	var share model.SummaryShare
+	if err := s.DB.WithContext(ctx).
+		Where("token_hash = ? AND revoked_at IS NULL", hashShareToken(token)).
+		First(&share).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, ErrShareNotFound
+		}
+		return nil, nil, err
+	}
+
+	if !share.Active() {
+		return nil, nil, ErrShareExpired
+	}
+
+	if share.PasswordHash != "" {
+		if password == "" {
+			return nil, nil, ErrSharePasswordRequired
+		}
+		if err := bcrypt.CompareHashAndPassword([]byte(share.PasswordHash), []byte(password)); err != nil {
+			return nil, nil, ErrInvalidSharePassword
+		}
+	}
+
+	var summary model.Summary
+	if err := s.DB.WithContext(ctx).First(&summary, "id = ?", share.SummaryID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, ErrShareNotFound
+		}
+		return nil, nil, err
+	}
+
+	var pdf model.PDF
+	if err := s.DB.WithContext(ctx).First(&pdf, "id = ?", share.PDFID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, ErrShareNotFound
+		}
+		return nil, nil, err
+	}
+
+	if err := s.DB.WithContext(ctx).Model(&model.SummaryShare{}).
+		Where("id = ?", share.ID).
+		Updates(map[string]interface{}{
+			"view_count":     gorm.Expr("view_count + 1"),
+			"last_viewed_at": time.Now(),
+		}).Error; err != nil {
+		s.Log.WithError(err).Warn("Failed to update share view count")
+	}
+
+	return &summary, &pdf, nil
+}
+
+func (s *shareService) createProcessingLog(ctx context.Context, entityType string, entityID uuid.UUID, action, status, message string, metadata map[string]interface{}) {
+	var metaJSON *json.RawMessage
+
+	if metadata != nil {
+		b, _ := json.Marshal(metadata)
+		raw := json.RawMessage(b)
+		metaJSON = &raw
+	}
+
+	log := &model.ProcessingLog{
+		EntityType: entityType,
+		EntityID:   entityID,
+		Action:     action,
+		Status:     status,
+		Message:    message,
+		Metadata:   metaJSON,
+		CreatedAt:  time.Now(),
+	}
+
+	if err := s.DB.WithContext(ctx).Create(log).Error; err != nil {
+		s.Log.WithError(err).Error("Failed to create processing log")
+	}
+}
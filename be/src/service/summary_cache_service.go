@@ -0,0 +1,213 @@
+package service
+
+import (
+	"app/src/config"
+	"app/src/model"
+	"app/src/utils"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// How long the model and prompt version reported by the ML service are reused
+const modelInfoTTL = 5 * time.Minute
+
+type ModelInfo struct {
+	Model         string `json:"model"`
+	PromptVersion string `json:"prompt_version"`
+}
+
+type SummaryCacheKey struct {
+	ContentHash   string
+	Language      string
+	Style         string
+	Model         string
+	PromptVersion string
+}
+
+type SummaryCacheFilter struct {
+	ContentHash   string
+	Language      string
+	Style         string
+	Model         string
+	PromptVersion string
+}
+
+type SummaryCacheService interface {
+	ModelInfo(ctx context.Context) (*ModelInfo, error)
+	Lookup(ctx context.Context, key SummaryCacheKey) (*model.SummaryCache, error)
+	Store(ctx context.Context, key SummaryCacheKey, content string, structured *json.RawMessage, summaryID uuid.UUID) error
+	Invalidate(ctx context.Context, filter SummaryCacheFilter) (int64, error)
+}
+
+type summaryCacheService struct {
+	Log *logrus.Logger
+	DB  *gorm.DB
+
+	mu        sync.Mutex
+	info      *ModelInfo
+	fetchedAt time.Time
+}
+
+func NewSummaryCacheService(db *gorm.DB) SummaryCacheService {
+	return &summaryCacheService{
+		Log: utils.Log,
+		DB:  db,
+	}
+}
+
+// ModelInfo asks the ML service which model and prompt version it uses, so a
+// model or prompt change never serves summaries produced by the old one
+func (s *summaryCacheService) ModelInfo(ctx context.Context) (*ModelInfo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.info != nil && time.Since(s.fetchedAt) < modelInfoTTL {
+		return s.info, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", config.MLServiceURL+"/info", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	client := &http.Client{Timeout: 5 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("model info request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("model info returned status %d", resp.StatusCode)
+	}
+
+	var info ModelInfo
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		return nil, fmt.Errorf("invalid model info response: %w", err)
+	}
+	if info.Model == "" || info.PromptVersion == "" {
+		return nil, errors.New("model info response is incomplete")
+	}
+
+	s.info = &info
+	s.fetchedAt = time.Now()
+	return s.info, nil
+}
+
+// fileSHA256 returns the hex encoded SHA-256 of a file
+func fileSHA256(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Lookup returns the cached entry and counts the hit, or nil on a miss
+func (s *summaryCacheService) Lookup(ctx context.Context, key SummaryCacheKey) (*model.SummaryCache, error) {
+	var entry model.SummaryCache
+	err := s.DB.WithContext(ctx).
+		Where("content_hash = ? AND language = ? AND style = ? AND model = ? AND prompt_version = ?",
+			key.ContentHash, key.Language, key.Style, key.Model, key.PromptVersion).
+		First(&entry).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if err := s.DB.WithContext(ctx).Model(&model.SummaryCache{}).
+		Where("id = ?", entry.ID).
+		Updates(map[string]interface{}{
+			"hit_count":   gorm.Expr("hit_count + 1"),
+			"last_hit_at": now,
+		}).Error; err != nil {
+		s.Log.WithError(err).Warn("Failed to update summary cache hit count")
+	}
+
+	return &entry, nil
+}
+
+// Store saves a freshly generated summary and its structured form, replacing
+// an older entry for the same key
+func (s *summaryCacheService) Store(ctx context.Context, key SummaryCacheKey, content string, structured *json.RawMessage, summaryID uuid.UUID) error {
+	var structuredValue interface{}
+	if structured != nil {
+		structuredValue = string(*structured)
+	}
+
+	entry := &model.SummaryCache{
+		ContentHash:     key.ContentHash,
+		Language:        key.Language,
+		Style:           key.Style,
+		Model:           key.Model,
+		PromptVersion:   key.PromptVersion,
+		Content:         content,
+		Structured:      structured,
+		SourceSummaryID: &summaryID,
+		CreatedAt:       time.Now(),
+	}
+
+	return s.DB.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns: []clause.Column{
+				{Name: "content_hash"}, {Name: "language"}, {Name: "style"}, {Name: "model"}, {Name: "prompt_version"},
+			},
+			DoUpdates: clause.Assignments(map[string]interface{}{
+				"content":           content,
+				"structured":        structuredValue,
+				"source_summary_id": summaryID,
+				"hit_count":         0,
+				"last_hit_at":       nil,
+				"created_at":        time.Now(),
+			}),
+		}).
+		Create(entry).Error
+}
+
+// Invalidate deletes the entries matching every non-empty filter field, an
+// empty filter clears the whole cache
+func (s *summaryCacheService) Invalidate(ctx context.Context, filter SummaryCacheFilter) (int64, error) {
+	query := s.DB.WithContext(ctx).Session(&gorm.Session{AllowGlobalUpdate: true})
+
+	if filter.ContentHash != "" {
+		query = query.Where("content_hash = ?", filter.ContentHash)
+	}
+	if filter.Language != "" {
+		query = query.Where("language = ?", filter.Language)
+	}
+	if filter.Style != "" {
+		query = query.Where("style = ?", filter.Style)
+	}
+	if filter.Model != "" {
+		query = query.Where("model = ?", filter.Model)
+	}
+	if filter.PromptVersion != "" {
+		query = query.Where("prompt_version = ?", filter.PromptVersion)
+	}
+
+	result := query.Delete(&model.SummaryCache{})
+	return result.RowsAffected, result.Error
+}
@@ -7,12 +7,15 @@ import (
     "app/src/validation"
     "bytes"
     "context"
+    "crypto/sha256"
+    "encoding/hex"
     "encoding/json"
+    "errors"
     "fmt"
     "io"
     "mime/multipart"
     "net/http"
-    "os"
+    "sort"
     "strings"
     "time"
 
@@ -20,39 +23,102 @@ import (
     "github.com/google/uuid"
     "github.com/sirupsen/logrus"
     "gorm.io/gorm"
+    "gorm.io/gorm/clause"
 )
 
 type SummaryService interface {
     GetByID(ctx context.Context, id uuid.UUID) (*model.Summary, error)
     GetAll(ctx context.Context, pdfID uuid.UUID, params validation.QueryParams) ([]model.Summary, *model.PaginationMeta, error)
-    Create(ctx context.Context, pdfID uuid.UUID, language, style string) (*model.Summary, error)
-    Update(ctx context.Context, id uuid.UUID, content string) error
-    Delete(ctx context.Context, id uuid.UUID) error
+    Create(ctx context.Context, pdfID uuid.UUID, payload validation.GenerateSummary) (*model.Summary, error)
+    CreateSynthesis(ctx context.Context, payload validation.GenerateSynthesis) (*model.Summary, error)
+    Update(ctx context.Context, id uuid.UUID, content, author string, version int) (*model.Summary, error)
+    Delete(ctx context.Context, id uuid.UUID, version int) error
     UpdateStatus(ctx context.Context, id uuid.UUID, status string, content string) error
+    GetRevisions(ctx context.Context, id uuid.UUID) ([]model.SummaryRevision, error)
+    GetRevision(ctx context.Context, id uuid.UUID, number int) (*model.SummaryRevision, error)
+    RestoreRevision(ctx context.Context, id uuid.UUID, number int, author string) (*model.Summary, error)
+    DiffRevisions(ctx context.Context, id uuid.UUID, from, to, contextLines int) (*SummaryDiff, error)
+    DiffSummaries(ctx context.Context, id, otherID uuid.UUID, contextLines int) (*SummaryDiff, error)
+    Attributions(ctx context.Context, id uuid.UUID) (*SummaryAttributions, error)
+}
+
+var (
+    ErrPDFLocked        = errors.New("PDF is password protected, unlock it before generating a summary")
+    ErrPDFUnavailable   = errors.New("PDF processing failed, the file is not available")
+    ErrDifferentPDFs    = errors.New("summaries belong to different PDFs")
+    ErrInvalidPageRange = errors.New("invalid page range")
+    ErrNoOutline        = errors.New("PDF has no outline with page targets")
+    ErrTooManyChapters  = errors.New("outline has too many chapters, summarize page ranges instead")
+)
+
+// DiffSide describes one side of a comparison, either a revision or a summary
+type DiffSide struct {
+    SummaryID uuid.UUID `json:"summary_id"`
+    Revision  int       `json:"revision,omitempty"`
+    Source    string    `json:"source,omitempty"`
+    Author    string    `json:"author,omitempty"`
+    Language  string    `json:"language,omitempty"`
+    Style     string    `json:"style,omitempty"`
+    CreatedAt time.Time `json:"created_at"`
+}
+
+type SummaryDiff struct {
+    From DiffSide `json:"from"`
+    To   DiffSide `json:"to"`
+    *utils.Diff
+}
+
+// SummaryAttributions lists the source pages of every paragraph and list item
+// of a summary. Stored is false when they were computed for the current
+// content because none were stored or the summary was edited since.
+type SummaryAttributions struct {
+    SummaryID uuid.UUID           `json:"summary_id"`
+    Version   int                 `json:"version"`
+    Content   string              `json:"content"`
+    Stored    bool                `json:"stored"`
+    Blocks    []utils.Attribution `json:"blocks"`
+}
+
+// storedAttributions is kept in summary metadata under "attributions"
+type storedAttributions struct {
+    ContentHash string              `json:"content_hash"`
+    Blocks      []utils.Attribution `json:"blocks"`
 }
 
 type summaryService struct {
-    Log      *logrus.Logger
-    DB       *gorm.DB
-    Validate *validator.Validate
+    Log               *logrus.Logger
+    DB                *gorm.DB
+    Validate          *validator.Validate
+    ExtractionService ExtractionService
+    Cache             SummaryCacheService
+    EntityService     EntityService
 }
 
-func NewSummaryService(db *gorm.DB, validate *validator.Validate) SummaryService {
+func NewSummaryService(db *gorm.DB, validate *validator.Validate, extractionService ExtractionService, cache SummaryCacheService, entityService EntityService) SummaryService {
     return &summaryService{
-        Log:      utils.Log,
-        DB:       db,
-        Validate: validate,
+        Log:               utils.Log,
+        DB:                db,
+        Validate:          validate,
+        ExtractionService: extractionService,
+        Cache:             cache,
+        EntityService:     entityService,
     }
 }
 
 func (s *summaryService) GetByID(ctx context.Context, id uuid.UUID) (*model.Summary, error) {
     var summary model.Summary
-    if err := s.DB.WithContext(ctx).First(&summary, "id = ?", id).Error; err != nil {
+    if err := s.DB.WithContext(ctx).
+        Preload("Sources", orderSources).
+        First(&summary, "id = ?", id).Error; err != nil {
         return nil, err
     }
     return &summary, nil
 }
 
+func orderSources(db *gorm.DB) *gorm.DB {
+    return db.Order("position ASC")
+}
+
 func (s *summaryService) GetAll(ctx context.Context, pdfID uuid.UUID, params validation.QueryParams) ([]model.Summary, *model.PaginationMeta, error) {
     var summaries []model.Summary
     var total int64
@@ -85,8 +151,9 @@ func (s *summaryService) GetAll(ctx context.Context, pdfID uuid.UUID, params val
         sortOrder = "ASC"
     }
 
+    // Synthesis summaries are listed on every source PDF
     query := s.DB.WithContext(ctx).Model(&model.Summary{}).
-        Where("pdf_id = ?", pdfID)
+        Where("(pdf_id = ? OR id IN (SELECT summary_id FROM summary_sources WHERE pdf_id = ?))", pdfID, pdfID)
 
     if params.Search != "" {
         query = query.Where("content ILIKE ?", "%"+params.Search+"%")
@@ -104,6 +171,19 @@ func (s *summaryService) GetAll(ctx context.Context, pdfID uuid.UUID, params val
         query = query.Where("style = ?", params.Style)
     }
 
+    if params.Keyword != "" {
+        query = query.Where("EXISTS (SELECT 1 FROM jsonb_array_elements_text(structured->'keywords') AS k WHERE lower(k) = lower(?))", params.Keyword)
+    }
+
+    switch params.Scope {
+    case "full":
+        query = query.Where("kind = 'single' AND page_from IS NULL")
+    case "partial":
+        query = query.Where("page_from IS NOT NULL")
+    case "synthesis":
+        query = query.Where("kind = 'synthesis'")
+    }
+
 	if params.DateFrom != "" {
 		query = query.Where("created_at >= ?", params.DateFrom+" 00:00:00")
 	}
@@ -120,7 +200,7 @@ func (s *summaryService) GetAll(ctx context.Context, pdfID uuid.UUID, params val
         Limit(params.Limit).
         Offset(params.GetOffset())
 
-    if err := query.Find(&summaries).Error; err != nil {
+    if err := query.Preload("Sources", orderSources).Find(&summaries).Error; err != nil {
         return nil, nil, err
     }
 
@@ -128,22 +208,106 @@ func (s *summaryService) GetAll(ctx context.Context, pdfID uuid.UUID, params val
     return summaries, &meta, nil
 }
 
-func (s *summaryService) Create(ctx context.Context, pdfID uuid.UUID, language, style string) (*model.Summary, error) {
+// Create starts a summary generation. Unless payload.Force is set, a cached
+// result for the same file, language, style, model and prompt version is
+// returned as a completed summary without calling the AI.
+func (s *summaryService) Create(ctx context.Context, pdfID uuid.UUID, payload validation.GenerateSummary) (*model.Summary, error) {
+    pdf := &model.PDF{}
+    if err := s.DB.WithContext(ctx).First(pdf, "id = ?", pdfID).Error; err != nil {
+        return nil, err
+    }
+    if pdf.Status == "locked" {
+        return nil, ErrPDFLocked
+    }
+    // Failed uploads may have been quarantined
+    if pdf.Status == "failed" {
+        return nil, ErrPDFUnavailable
+    }
+
+    pageFrom, pageTo, err := pageRange(pdf, payload.PageFrom, payload.PageTo)
+    if err != nil {
+        return nil, err
+    }
+
+    var outlineItemID *uuid.UUID
+    if payload.OutlineItemID != nil {
+        if payload.PageFrom != nil || payload.PageTo != nil {
+            return nil, fmt.Errorf("%w: use either a page range or an outline item", ErrInvalidPageRange)
+        }
+
+        var item model.PDFOutlineItem
+        if err := s.DB.WithContext(ctx).
+            Where("id = ? AND pdf_id = ?", *payload.OutlineItemID, pdfID).
+            First(&item).Error; err != nil {
+            if errors.Is(err, gorm.ErrRecordNotFound) {
+                return nil, fmt.Errorf("%w: outline item not found", ErrInvalidPageRange)
+            }
+            return nil, err
+        }
+        if item.PageFrom == nil {
+            return nil, fmt.Errorf("%w: outline item has no page target", ErrInvalidPageRange)
+        }
+
+        if pageFrom, pageTo, err = pageRange(pdf, item.PageFrom, item.PageTo); err != nil {
+            return nil, err
+        }
+        outlineItemID = &item.ID
+    }
+
+    var chapters []model.PDFOutlineItem
+    if payload.Mode == "chapters" {
+        if pageFrom != nil || outlineItemID != nil {
+            return nil, fmt.Errorf("%w: chapter summaries cover the whole document", ErrInvalidPageRange)
+        }
+        if chapters, err = s.chapters(ctx, pdfID); err != nil {
+            return nil, err
+        }
+    }
+
     summaryID := uuid.New()
 
     s.createProcessingLog(ctx, "summary", summaryID, "generate", "started", "Starting summary generation", map[string]interface{}{
-        "pdf_id":   pdfID.String(),
-        "language": language,
-        "style":    style,
+        "pdf_id":    pdfID.String(),
+        "language":  payload.Language,
+        "style":     payload.Style,
+        "force":     payload.Force,
+        "page_from": pageFrom,
+        "page_to":   pageTo,
+        "mode":      payload.Mode,
+        "cite":      payload.Cite,
     })
 
+    // The cache is keyed on the whole file, partial and chapter summaries bypass it
+    var cacheKey *SummaryCacheKey
+    if pageFrom == nil && chapters == nil {
+        cacheKey = s.cacheKey(ctx, pdf, payload.Language, payload.Style)
+    }
+    if cacheKey != nil && !payload.Force {
+        entry, err := s.Cache.Lookup(ctx, *cacheKey)
+        if err != nil {
+            s.Log.WithError(err).Warn("Summary cache lookup failed")
+        }
+        if entry != nil {
+            return s.createFromCache(ctx, summaryID, pdfID, payload, entry)
+        }
+    }
+
     summary := &model.Summary{
         ID:       summaryID,
         PDFID:    pdfID,
-        Language: language,
-        Style:    style,
+        Language: payload.Language,
+        Style:    payload.Style,
+        PageFrom: pageFrom,
+        PageTo:   pageTo,
         Status:   "processing",
         IsEdited: false,
+        Version:  1,
+
+        OutlineItemID: outlineItemID,
+    }
+    if chapters != nil {
+        mode := payload.Mode
+        summary.Mode = &mode
     }
 
     if err := s.DB.WithContext(ctx).Create(summary).Error; err != nil {
@@ -151,40 +315,113 @@ func (s *summaryService) Create(ctx context.Context, pdfID uuid.UUID, language,
         return nil, err
     }
 
-    go s.callAIService(context.Background(), summary)
+    if chapters != nil {
+        go s.callChapters(context.Background(), summary, chapters, payload.Cite)
+    } else {
+        go s.callAIService(context.Background(), summary, cacheKey, payload.Cite)
+    }
 
     return summary, nil
 }
 
-func (s *summaryService) Update(ctx context.Context, id uuid.UUID, content string) error {
-    return s.DB.WithContext(ctx).Model(&model.Summary{}).
-        Where("id = ?", id).
-        Updates(map[string]interface{}{
-            "content":    content,
-            "is_edited":  true,
-            "updated_at": time.Now(),
-        }).Error
-}
+// CreateSynthesis starts one summary across several PDFs. Timeline syntheses
+// order the documents by date, the others keep the requested order. The first
+// document is stored as pdf_id and every document is linked as a source.
+func (s *summaryService) CreateSynthesis(ctx context.Context, payload validation.GenerateSynthesis) (*model.Summary, error) {
+    var found []model.PDF
+    if err := s.DB.WithContext(ctx).Where("id IN ?", payload.PDFIDs).Find(&found).Error; err != nil {
+        return nil, err
+    }
+    if len(found) != len(payload.PDFIDs) {
+        return nil, gorm.ErrRecordNotFound
+    }
 
-func (s *summaryService) Delete(ctx context.Context, id uuid.UUID) error {
-    return s.DB.WithContext(ctx).Delete(&model.Summary{}, id).Error
-}
+    byID := make(map[uuid.UUID]model.PDF, len(found))
+    for _, pdf := range found {
+        if pdf.Status == "locked" {
+            return nil, ErrPDFLocked
+        }
+        if pdf.Status == "failed" {
+            return nil, ErrPDFUnavailable
+        }
+        byID[pdf.ID] = pdf
+    }
 
-func (s *summaryService) UpdateStatus(ctx context.Context, id uuid.UUID, status string, content string) error {
-    updates := map[string]interface{}{
-        "status":     status,
-        "updated_at": time.Now(),
+    pdfs := make([]model.PDF, len(payload.PDFIDs))
+    for i, id := range payload.PDFIDs {
+        pdfs[i] = byID[id]
     }
-    if content != "" {
-        updates["content"] = content
+    if payload.Mode == "timeline" {
+        sort.SliceStable(pdfs, func(i, j int) bool {
+            return documentDate(pdfs[i]).Before(documentDate(pdfs[j]))
+        })
     }
 
-    return s.DB.WithContext(ctx).Model(&model.Summary{}).
-        Where("id = ?", id).
-        Updates(updates).Error
+    summaryID := uuid.New()
+    mode := payload.Mode
+    summary := &model.Summary{
+        ID:       summaryID,
+        PDFID:    pdfs[0].ID,
+        Language: payload.Language,
+        Style:    payload.Style,
+        Kind:     "synthesis",
+        Mode:     &mode,
+        Status:   "processing",
+        IsEdited: false,
+        Version:  1,
+    }
+    for i, pdf := range pdfs {
+        summary.Sources = append(summary.Sources, model.SummarySource{
+            SummaryID: summaryID,
+            PDFID:     pdf.ID,
+            Position:  i,
+        })
+    }
+
+    err := s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+        if err := tx.Omit("Sources").Create(summary).Error; err != nil {
+            return err
+        }
+        return tx.Create(&summary.Sources).Error
+    })
+    if err != nil {
+        s.failSummary(ctx, summaryID, "Failed to create synthesis record", err)
+        return nil, err
+    }
+
+    s.createProcessingLog(ctx, "summary", summaryID, "synthesize", "started", "Starting synthesis generation", map[string]interface{}{
+        "pdf_ids":  payload.PDFIDs,
+        "mode":     payload.Mode,
+        "language": payload.Language,
+        "style":    payload.Style,
+    })
+
+    go s.callSynthesis(context.Background(), summary, pdfs)
+
+    return summary, nil
 }
 
-func (s *summaryService) callAIService(ctx context.Context, summary *model.Summary) {
+// documentDate is the creation date from the PDF metadata, falling back to
+// the upload time
+func documentDate(pdf model.PDF) time.Time {
+    if pdf.PDFCreatedAt != nil {
+        return *pdf.PDFCreatedAt
+    }
+    return pdf.UploadedAt
+}
+
+// chapterSection links a section of a chapter summary to its outline item
+type chapterSection struct {
+    OutlineItemID uuid.UUID `json:"outline_item_id"`
+    Title         string    `json:"title"`
+    PageFrom      int       `json:"page_from"`
+    PageTo        int       `json:"page_to"`
+    Skipped       bool      `json:"skipped,omitempty"` // no text on its pages
+}
+
+// callChapters summarizes every chapter separately and assembles the results
+// into one document with a section per chapter
+func (s *summaryService) callChapters(ctx context.Context, summary *model.Summary, chapters []model.PDFOutlineItem, cite bool) {
     start := time.Now()
 
     pdf := &model.PDF{}
@@ -193,33 +430,122 @@ func (s *summaryService) callAIService(ctx context.Context, summary *model.Summa
         return
     }
 
-    body := &bytes.Buffer{}
-    writer := multipart.NewWriter(body)
-    _ = writer.WriteField("language", summary.Language)
-    _ = writer.WriteField("style", summary.Style)
-
-    file, err := os.Open(pdf.FilePath)
+    pages, err := s.loadPages(ctx, pdf)
     if err != nil {
-        s.failSummary(ctx, summary.ID, "Failed to open PDF", err)
+        s.failSummary(ctx, summary.ID, "Failed to extract text from PDF", err)
         return
     }
-    defer file.Close()
 
-    fw, err := writer.CreateFormFile("file", pdf.OriginalName)
-    if err != nil {
-        s.failSummary(ctx, summary.ID, "Failed to create form file", err)
+    var parts []string
+    var parsed map[string]interface{}
+    sections := make([]chapterSection, 0, len(chapters))
+    for _, chapter := range chapters {
+        section := chapterSection{
+            OutlineItemID: chapter.ID,
+            Title:         chapter.Title,
+            PageFrom:      *chapter.PageFrom,
+            PageTo:        *chapter.PageTo,
+        }
+
+        var scoped []model.PDFPage
+        for _, page := range pages {
+            if page.PageNumber >= section.PageFrom && page.PageNumber <= section.PageTo {
+                scoped = append(scoped, page)
+            }
+        }
+        text := joinPageText(scoped)
+        if text == "" {
+            section.Skipped = true
+            sections = append(sections, section)
+            continue
+        }
+
+        parsed, err = s.summarizeText(ctx, summary.Language, summary.Style, text)
+        if err != nil {
+            s.failSummary(ctx, summary.ID, "AI service error on chapter "+chapter.Title, err)
+            return
+        }
+        content := summaryContent(parsed)
+        if content == "" {
+            s.failSummary(ctx, summary.ID, "AI response missing or empty content for chapter "+chapter.Title, nil)
+            return
+        }
+
+        pageLabel := fmt.Sprintf("p. %d", section.PageFrom)
+        if section.PageTo > section.PageFrom {
+            pageLabel = fmt.Sprintf("pp. %d–%d", section.PageFrom, section.PageTo)
+        }
+        parts = append(parts, fmt.Sprintf("## %s (%s)\n\n%s", chapter.Title, pageLabel, demoteHeadings(content)))
+        sections = append(sections, section)
+    }
+
+    if len(parts) == 0 {
+        s.failSummary(ctx, summary.ID, "No text found in any chapter, including OCR", nil)
         return
     }
-    if _, err := io.Copy(fw, file); err != nil {
-        s.failSummary(ctx, summary.ID, "Failed to copy PDF", err)
+
+    content := strings.Join(parts, "\n\n")
+    processingTime := time.Since(start).Milliseconds()
+
+    metadata := map[string]interface{}{
+        "processing_time_ms": processingTime,
+        "ai_model":           parsed["model"],
+        "prompt_version":     parsed["prompt_version"],
+        "ocr_pages":          countOCRPages(pages),
+        "sections":           sections,
+        "cache_hit":          false,
+    }
+    if cite {
+        metadata["attributions"] = attribute(content, sourcePages(pages))
+    }
+
+    if err := s.completeSummary(ctx, summary, content, metadata); err != nil {
+        s.createProcessingLog(ctx, "summary", summary.ID, "generate", "failed", "Failed to update summary", map[string]interface{}{
+            "error": err.Error(),
+        })
         return
     }
+
+    s.createProcessingLog(ctx, "summary", summary.ID, "generate", "completed", "Chapter summary generated successfully", map[string]interface{}{
+        "content_length":     len(content),
+        "chapters":           len(parts),
+        "processing_time_ms": processingTime,
+    })
+}
+
+// demoteHeadings moves Markdown headings one level down so they nest under
+// the chapter heading
+func demoteHeadings(content string) string {
+    lines := strings.Split(content, "\n")
+    for i, line := range lines {
+        if strings.HasPrefix(line, "#") && !strings.HasPrefix(line, "######") {
+            lines[i] = "#" + line
+        }
+    }
+    return strings.Join(lines, "\n")
+}
+
+// loadPages reuses previously extracted text and extracts it otherwise
+func (s *summaryService) loadPages(ctx context.Context, pdf *model.PDF) ([]model.PDFPage, error) {
+    pages, err := s.ExtractionService.GetPages(ctx, pdf.ID)
+    if err != nil || len(pages) > 0 {
+        return pages, err
+    }
+    return s.ExtractionService.Extract(ctx, pdf, "")
+}
+
+// summarizeText sends text to the ML service and returns its parsed response
+func (s *summaryService) summarizeText(ctx context.Context, language, style, text string) (map[string]interface{}, error) {
+    body := &bytes.Buffer{}
+    writer := multipart.NewWriter(body)
+    _ = writer.WriteField("language", language)
+    _ = writer.WriteField("style", style)
+    _ = writer.WriteField("text", text)
     writer.Close()
 
-    req, err := http.NewRequest("POST", config.MLServiceURL+"/summarize", body)
+    req, err := http.NewRequestWithContext(ctx, "POST", config.MLServiceURL+"/summarize", body)
     if err != nil {
-        s.failSummary(ctx, summary.ID, "Failed to create request", err)
-        return
+        return nil, fmt.Errorf("failed to create request: %w", err)
     }
     req.Header.Set("Content-Type", writer.FormDataContentType())
 
@@ -227,7 +553,98 @@ func (s *summaryService) callAIService(ctx context.Context, summary *model.Summa
         Timeout: 2 * time.Minute,
     }
     resp, err := client.Do(req)
+    if err != nil {
+        return nil, fmt.Errorf("AI request failed: %w", err)
+    }
+    defer resp.Body.Close()
+
+    respBody, err := io.ReadAll(resp.Body)
+    if err != nil {
+        return nil, fmt.Errorf("failed to read AI response: %w", err)
+    }
+
+    if resp.StatusCode != 200 {
+        errMsg := strings.TrimSpace(string(respBody))
+        if errMsg == "" {
+            errMsg = fmt.Sprintf("AI returned status %d", resp.StatusCode)
+        }
+        return nil, errors.New(errMsg)
+    }
+
+    var parsed map[string]interface{}
+    if err := json.Unmarshal(respBody, &parsed); err != nil {
+        return nil, fmt.Errorf("invalid AI JSON response: %w", err)
+    }
+    return parsed, nil
+}
 
+type synthesisDocument struct {
+    Title string `json:"title"`
+    Date  string `json:"date"`
+    Text  string `json:"text"`
+}
+
+type synthesisRequest struct {
+    Documents []synthesisDocument `json:"documents"`
+    Mode      string              `json:"mode"`
+    Language  string              `json:"language"`
+    Style     string              `json:"style"`
+}
+
+func (s *summaryService) callSynthesis(ctx context.Context, summary *model.Summary, pdfs []model.PDF) {
+    start := time.Now()
+
+    request := synthesisRequest{
+        Mode:     *summary.Mode,
+        Language: summary.Language,
+        Style:    summary.Style,
+    }
+
+    ocrPages := 0
+    for i := range pdfs {
+        pdf := &pdfs[i]
+
+        pages, err := s.loadPages(ctx, pdf)
+        if err != nil {
+            s.failSummary(ctx, summary.ID, "Failed to extract text from PDF "+pdf.OriginalName, err)
+            return
+        }
+
+        text := joinPageText(pages)
+        if text == "" {
+            s.failSummary(ctx, summary.ID, "No text found in PDF "+pdf.OriginalName, nil)
+            return
+        }
+        ocrPages += countOCRPages(pages)
+
+        title := pdf.Title
+        if title == "" {
+            title = pdf.OriginalName
+        }
+        request.Documents = append(request.Documents, synthesisDocument{
+            Title: title,
+            Date:  documentDate(*pdf).Format("2006-01-02"),
+            Text:  text,
+        })
+    }
+
+    body, err := json.Marshal(request)
+    if err != nil {
+        s.failSummary(ctx, summary.ID, "Failed to encode synthesis request", err)
+        return
+    }
+
+    req, err := http.NewRequest("POST", config.MLServiceURL+"/synthesize", bytes.NewReader(body))
+    if err != nil {
+        s.failSummary(ctx, summary.ID, "Failed to create request", err)
+        return
+    }
+    req.Header.Set("Content-Type", "application/json")
+
+    client := &http.Client{
+        Timeout: 5 * time.Minute,
+    }
+    resp, err := client.Do(req)
     if err != nil {
         s.failSummary(ctx, summary.ID, "AI request failed", err)
         return
@@ -245,7 +662,7 @@ func (s *summaryService) callAIService(ctx context.Context, summary *model.Summa
         if errMsg == "" {
             errMsg = fmt.Sprintf("AI returned status %d", resp.StatusCode)
         }
-        s.failSummary(ctx, summary.ID, "AI service error", fmt.Errorf(errMsg))
+        s.failSummary(ctx, summary.ID, "AI service error", errors.New(errMsg))
         return
     }
 
@@ -255,23 +672,7 @@ func (s *summaryService) callAIService(ctx context.Context, summary *model.Summa
         return
     }
 
-    var content string
-
-    if v, ok := parsed["content"]; ok {
-        content = fmt.Sprintf("%v", v)
-    } else if v, ok := parsed["summary"]; ok {
-        content = fmt.Sprintf("%v", v)
-    } else if v, ok := parsed["result"]; ok {
-        content = fmt.Sprintf("%v", v)
-    } else if data, ok := parsed["data"].(map[string]interface{}); ok {
-        if v, ok := data["content"]; ok {
-            content = fmt.Sprintf("%v", v)
-        } else if v, ok := data["summary"]; ok {
-            content = fmt.Sprintf("%v", v)
-        }
-    }
-
-    content = strings.TrimSpace(content)
+    content := summaryContent(parsed)
     if content == "" {
         s.failSummary(ctx, summary.ID, "AI response missing or empty content", nil)
         return
@@ -282,46 +683,925 @@ func (s *summaryService) callAIService(ctx context.Context, summary *model.Summa
     metadata := map[string]interface{}{
         "processing_time_ms": processingTime,
         "ai_model":           parsed["model"],
+        "prompt_version":     parsed["prompt_version"],
+        "ocr_pages":          ocrPages,
+        "source_count":       len(pdfs),
+        "cache_hit":          false,
     }
 
-    metadataJSON, _ := json.Marshal(metadata)
-
-    if err := s.DB.WithContext(ctx).Model(&model.Summary{}).
-        Where("id = ?", summary.ID).
-        Updates(map[string]interface{}{
-            "content":  content,
-            "metadata": string(metadataJSON),
-            "status":   "completed",
-        }).Error; err != nil {
-
-        s.createProcessingLog(ctx, "summary", summary.ID, "generate", "failed", "Failed to update summary", map[string]interface{}{
+    if err := s.completeSummary(ctx, summary, content, metadata); err != nil {
+        s.createProcessingLog(ctx, "summary", summary.ID, "synthesize", "failed", "Failed to update summary", map[string]interface{}{
             "error": err.Error(),
         })
         return
     }
 
-    s.createProcessingLog(ctx, "summary", summary.ID, "generate", "completed", "Summary generated successfully", map[string]interface{}{
+    s.createProcessingLog(ctx, "summary", summary.ID, "synthesize", "completed", "Synthesis generated successfully", map[string]interface{}{
         "content_length":     len(content),
         "processing_time_ms": processingTime,
     })
 }
 
-func (s *summaryService) failSummary(ctx context.Context, id uuid.UUID, msg string, err error) {
-    meta := map[string]interface{}{}
+const maxChapterSections = 50
+
+// chapters returns the top-level outline items with a page target. An outline
+// with a single root, usually the document title, uses its children instead.
+func (s *summaryService) chapters(ctx context.Context, pdfID uuid.UUID) ([]model.PDFOutlineItem, error) {
+    var items []model.PDFOutlineItem
+    if err := s.DB.WithContext(ctx).
+        Where("pdf_id = ?", pdfID).
+        Order("position ASC").
+        Find(&items).Error; err != nil {
+        return nil, err
+    }
+
+    level := buildOutlineTree(items)
+    for len(level) == 1 && len(level[0].Children) > 0 {
+        level = level[0].Children
+    }
+
+    var chapters []model.PDFOutlineItem
+    for _, item := range level {
+        if item.PageFrom != nil {
+            item.Children = nil
+            chapters = append(chapters, item)
+        }
+    }
+    if len(chapters) == 0 {
+        return nil, ErrNoOutline
+    }
+    if len(chapters) > maxChapterSections {
+        return nil, ErrTooManyChapters
+    }
+    return chapters, nil
+}
+
+// pageRange completes a requested page range. A missing bound defaults to the
+// first or last page and a range covering every page is returned as nil, so
+// such a summary counts as a whole-document summary.
+func pageRange(pdf *model.PDF, from, to *int) (*int, *int, error) {
+    if from == nil && to == nil {
+        return nil, nil, nil
+    }
+
+    first, last := 1, pdf.PageCount
+    if from != nil {
+        first = *from
+    }
+    if to != nil {
+        last = *to
+    }
+
+    if last < 1 || first > last {
+        return nil, nil, fmt.Errorf("%w: page_from must not be after page_to", ErrInvalidPageRange)
+    }
+    if pdf.PageCount > 0 && last > pdf.PageCount {
+        return nil, nil, fmt.Errorf("%w: the document has %d pages", ErrInvalidPageRange, pdf.PageCount)
+    }
+    if first == 1 && last == pdf.PageCount {
+        return nil, nil, nil
+    }
+    return &first, &last, nil
+}
+
+// cacheKey returns nil when the key cannot be built, e.g. the ML service is
+// unreachable, in which case the cache is skipped
+func (s *summaryService) cacheKey(ctx context.Context, pdf *model.PDF, language, style string) *SummaryCacheKey {
+    if pdf.ContentHash == "" {
+        hash, err := fileSHA256(pdf.FilePath)
+        if err != nil {
+            s.Log.WithError(err).Warn("Failed to hash PDF for summary cache")
+            return nil
+        }
+        pdf.ContentHash = hash
+        if err := s.DB.WithContext(ctx).Model(&model.PDF{}).
+            Where("id = ?", pdf.ID).
+            Update("content_hash", hash).Error; err != nil {
+            s.Log.WithError(err).Warn("Failed to store PDF content hash")
+        }
+    }
+
+    info, err := s.Cache.ModelInfo(ctx)
     if err != nil {
-        meta["error"] = err.Error()
+        s.Log.WithError(err).Warn("Summary cache disabled, model info unavailable")
+        return nil
     }
 
-    metaJSON, _ := json.Marshal(meta)
+    return &SummaryCacheKey{
+        ContentHash:   pdf.ContentHash,
+        Language:      language,
+        Style:         style,
+        Model:         info.Model,
+        PromptVersion: info.PromptVersion,
+    }
+}
 
-    _ = s.DB.WithContext(ctx).Model(&model.Summary{}).
-        Where("id = ?", id).
-        Updates(map[string]interface{}{
-            "status":   "failed",
-            "metadata": string(metaJSON),
-        }).Error
+func (s *summaryService) createFromCache(ctx context.Context, summaryID, pdfID uuid.UUID, payload validation.GenerateSummary, entry *model.SummaryCache) (*model.Summary, error) {
+    meta := map[string]interface{}{
+        "cache_hit":          true,
+        "cache_id":           entry.ID,
+        "cached_from":        entry.SourceSummaryID,
+        "cached_at":          entry.CreatedAt,
+        "ai_model":           entry.Model,
+        "prompt_version":     entry.PromptVersion,
+        "processing_time_ms": 0,
+    }
+    if payload.Cite {
+        // Cached entries cover the whole file, so every page is a candidate
+        pages, err := s.ExtractionService.GetPages(ctx, pdfID)
+        if err != nil {
+            s.Log.WithError(err).Warn("Failed to load pages for attributions")
+        } else {
+            meta["attributions"] = attribute(entry.Content, sourcePages(pages))
+        }
+    }
+    metadataJSON, _ := json.Marshal(meta)
+    metadata := json.RawMessage(metadataJSON)
 
-    s.createProcessingLog(ctx, "summary", id, "generate", "failed", msg, meta)
+    summary := &model.Summary{
+        ID:           summaryID,
+        PDFID:        pdfID,
+        Content:      entry.Content,
+        Structured:   entry.Structured,
+        SearchTokens: utils.SegmentCJK(entry.Content),
+        Language:     payload.Language,
+        Style:        payload.Style,
+        Status:       "completed",
+        IsEdited:     false,
+        Metadata:     &metadata,
+        Version:      1,
+        CreatedAt:    time.Now(),
+        UpdatedAt:    time.Now(),
+    }
+
+    err := s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+        if err := tx.Create(summary).Error; err != nil {
+            return err
+        }
+        _, err := s.addRevision(tx, summaryID, entry.Content, "ai", "cache", nil)
+        return err
+    })
+    if err != nil {
+        s.failSummary(ctx, summaryID, "Failed to create summary from cache", err)
+        return nil, err
+    }
+
+    // Entries stored before the structured form was cached, or whose content
+    // could not be structured, are structured now
+    if entry.Structured == nil {
+        go s.restructure(summaryID, summary.Version)
+    } else {
+        go s.indexEntities(pdfID)
+    }
+
+    s.createProcessingLog(ctx, "summary", summaryID, "generate", "completed", "Summary served from cache", map[string]interface{}{
+        "cache_id":       entry.ID,
+        "content_length": len(entry.Content),
+    })
+
+    return summary, nil
+}
+
+// Update stores the edited content and records it as a manual revision. A
+// non-zero version must match the current one, otherwise ErrVersionConflict
+// is returned and nothing is written. The structured form is rebuilt in the
+// background.
+func (s *summaryService) Update(ctx context.Context, id uuid.UUID, content, author string, version int) (*model.Summary, error) {
+    var updated int
+    err := s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+        current, err := s.lockSummary(tx, id)
+        if err != nil {
+            return err
+        }
+        if version > 0 && current.Version != version {
+            return ErrVersionConflict
+        }
+
+        if err := tx.Model(&model.Summary{}).
+            Where("id = ?", id).
+            Updates(map[string]interface{}{
+                "content":       content,
+                "search_tokens": utils.SegmentCJK(content),
+                "structured":    nil,
+                "metadata":      gorm.Expr("COALESCE(metadata, '{}'::jsonb) - 'structured_error'"),
+                "is_edited":     true,
+                "version":       gorm.Expr("version + 1"),
+                "updated_at":    time.Now(),
+            }).Error; err != nil {
+            return err
+        }
+        updated = current.Version + 1
+
+        _, err = s.addRevision(tx, id, content, "manual", author, nil)
+        return err
+    })
+    if err != nil {
+        return nil, err
+    }
+
+    go s.restructure(id, updated)
+
+    return s.GetByID(ctx, id)
+}
+
+func (s *summaryService) Delete(ctx context.Context, id uuid.UUID, version int) error {
+    if version == 0 {
+        return s.DB.WithContext(ctx).Delete(&model.Summary{}, id).Error
+    }
+
+    result := s.DB.WithContext(ctx).Where("version = ?", version).Delete(&model.Summary{}, id)
+    if result.Error != nil {
+        return result.Error
+    }
+    if result.RowsAffected == 0 {
+        if _, err := s.GetByID(ctx, id); err != nil {
+            return err
+        }
+        return ErrVersionConflict
+    }
+    return nil
+}
+
+func (s *summaryService) UpdateStatus(ctx context.Context, id uuid.UUID, status string, content string) error {
+    updates := map[string]interface{}{
+        "status":     status,
+        "version":    gorm.Expr("version + 1"),
+        "updated_at": time.Now(),
+    }
+    if content != "" {
+        updates["content"] = content
+        updates["search_tokens"] = utils.SegmentCJK(content)
+    }
+
+    return s.DB.WithContext(ctx).Model(&model.Summary{}).
+        Where("id = ?", id).
+        Updates(updates).Error
+}
+
+func (s *summaryService) callAIService(ctx context.Context, summary *model.Summary, cacheKey *SummaryCacheKey, cite bool) {
+    start := time.Now()
+
+    pdf := &model.PDF{}
+    if err := s.DB.WithContext(ctx).First(pdf, "id = ?", summary.PDFID).Error; err != nil {
+        s.failSummary(ctx, summary.ID, "PDF not found", err)
+        return
+    }
+
+    body := &bytes.Buffer{}
+    writer := multipart.NewWriter(body)
+    _ = writer.WriteField("language", summary.Language)
+    _ = writer.WriteField("style", summary.Style)
+
+    // Reuse previously extracted text, e.g. from unlocked password-protected PDFs
+    pages, err := s.ExtractionService.GetPages(ctx, pdf.ID)
+    if err != nil {
+        s.failSummary(ctx, summary.ID, "Failed to load extracted text", err)
+        return
+    }
+
+    if len(pages) == 0 {
+        pages, err = s.ExtractionService.Extract(ctx, pdf, "")
+        if err != nil {
+            s.failSummary(ctx, summary.ID, "Failed to extract text from PDF", err)
+            return
+        }
+    }
+
+    if summary.IsPartial() {
+        scoped := make([]model.PDFPage, 0, len(pages))
+        for _, page := range pages {
+            if summary.InRange(page.PageNumber) {
+                scoped = append(scoped, page)
+            }
+        }
+        pages = scoped
+    }
+
+    text := joinPageText(pages)
+    if text == "" {
+        s.failSummary(ctx, summary.ID, "No text found in PDF, including OCR", nil)
+        return
+    }
+
+    _ = writer.WriteField("text", text)
+    writer.Close()
+
+    req, err := http.NewRequest("POST", config.MLServiceURL+"/summarize", body)
+    if err != nil {
+        s.failSummary(ctx, summary.ID, "Failed to create request", err)
+        return
+    }
+    req.Header.Set("Content-Type", writer.FormDataContentType())
+
+    client := &http.Client{
+        Timeout: 2 * time.Minute,
+    }
+    resp, err := client.Do(req)
+
+    if err != nil {
+        s.failSummary(ctx, summary.ID, "AI request failed", err)
+        return
+    }
+    defer resp.Body.Close()
+
+    respBody, err := io.ReadAll(resp.Body)
+    if err != nil {
+        s.failSummary(ctx, summary.ID, "Failed to read AI response", err)
+        return
+    }
+
+    if resp.StatusCode != 200 {
+        errMsg := strings.TrimSpace(string(respBody))
+        if errMsg == "" {
+            errMsg = fmt.Sprintf("AI returned status %d", resp.StatusCode)
+        }
+        s.failSummary(ctx, summary.ID, "AI service error", fmt.Errorf(errMsg))
+        return
+    }
+
+    var parsed map[string]interface{}
+    if err := json.Unmarshal(respBody, &parsed); err != nil {
+        s.failSummary(ctx, summary.ID, "Invalid AI JSON response", err)
+        return
+    }
+
+    content := summaryContent(parsed)
+    if content == "" {
+        s.failSummary(ctx, summary.ID, "AI response missing or empty content", nil)
+        return
+    }
+
+    processingTime := time.Since(start).Milliseconds()
+
+    metadata := map[string]interface{}{
+        "processing_time_ms": processingTime,
+        "ai_model":           parsed["model"],
+        "prompt_version":     parsed["prompt_version"],
+        "ocr_pages":          countOCRPages(pages),
+        "cache_hit":          false,
+    }
+    if cite {
+        metadata["attributions"] = attribute(content, sourcePages(pages))
+    }
+
+    if err := s.completeSummary(ctx, summary, content, metadata); err != nil {
+        s.createProcessingLog(ctx, "summary", summary.ID, "generate", "failed", "Failed to update summary", map[string]interface{}{
+            "error": err.Error(),
+        })
+        return
+    }
+
+    s.createProcessingLog(ctx, "summary", summary.ID, "generate", "completed", "Summary generated successfully", map[string]interface{}{
+        "content_length":     len(content),
+        "processing_time_ms": processingTime,
+    })
+
+    if cacheKey != nil {
+        // The ML service reports what actually produced this summary
+        if v, ok := parsed["model"].(string); ok && v != "" {
+            cacheKey.Model = v
+        }
+        if v, ok := parsed["prompt_version"].(string); ok && v != "" {
+            cacheKey.PromptVersion = v
+        }
+        if err := s.Cache.Store(ctx, *cacheKey, content, summary.Structured, summary.ID); err != nil {
+            s.Log.WithError(err).Warn("Failed to store summary in cache")
+        }
+    }
+}
+
+// summaryContent reads the generated text from the ML service response
+func summaryContent(parsed map[string]interface{}) string {
+    var content string
+
+    if v, ok := parsed["content"]; ok {
+        content = fmt.Sprintf("%v", v)
+    } else if v, ok := parsed["summary"]; ok {
+        content = fmt.Sprintf("%v", v)
+    } else if v, ok := parsed["result"]; ok {
+        content = fmt.Sprintf("%v", v)
+    } else if data, ok := parsed["data"].(map[string]interface{}); ok {
+        if v, ok := data["content"]; ok {
+            content = fmt.Sprintf("%v", v)
+        } else if v, ok := data["summary"]; ok {
+            content = fmt.Sprintf("%v", v)
+        }
+    }
+
+    return strings.TrimSpace(content)
+}
+
+// completeSummary stores generated content together with its structured form
+// and records it as an AI revision. Failing to structure the content does not
+// fail the summary, the error is kept in the metadata instead. The stored
+// content and structured form are set on summary.
+func (s *summaryService) completeSummary(ctx context.Context, summary *model.Summary, content string, metadata map[string]interface{}) error {
+    var structured interface{}
+    var structuredJSON *json.RawMessage
+    parts, err := s.structureSummary(ctx, summary.Language, content)
+    if err != nil {
+        s.Log.WithError(err).WithField("summary_id", summary.ID).Warn("Failed to structure summary")
+        metadata["structured_error"] = err.Error()
+    } else {
+        partsJSON, _ := json.Marshal(parts)
+        structured = string(partsJSON)
+        raw := json.RawMessage(partsJSON)
+        structuredJSON = &raw
+    }
+
+    metadataJSON, _ := json.Marshal(metadata)
+
+    err = s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+        if err := tx.Model(&model.Summary{}).
+            Where("id = ?", summary.ID).
+            Updates(map[string]interface{}{
+                "content":       content,
+                "search_tokens": utils.SegmentCJK(content),
+                "metadata":      string(metadataJSON),
+                "structured":    structured,
+                "status":        "completed",
+                "version":       gorm.Expr("version + 1"),
+            }).Error; err != nil {
+            return err
+        }
+
+        _, err := s.addRevision(tx, summary.ID, content, "ai", "ai-service", nil)
+        return err
+    })
+    if err != nil {
+        return err
+    }
+    summary.Content = content
+    summary.Structured = structuredJSON
+
+    if summary.Kind != "synthesis" && !summary.IsPartial() {
+        go s.indexEntities(summary.PDFID)
+    }
+    return nil
+}
+
+// restructure structures edited, restored or cached content in the
+// background, so the stored parts never describe an older text. The result is
+// only stored while the summary is still at the given version, a newer edit
+// restructures its own content. Failures are kept in the metadata as
+// structured_error, as completeSummary does.
+func (s *summaryService) restructure(id uuid.UUID, version int) {
+    ctx := context.Background()
+    log := s.Log.WithField("summary_id", id)
+
+    var summary model.Summary
+    if err := s.DB.WithContext(ctx).First(&summary, "id = ? AND version = ?", id, version).Error; err != nil {
+        if !errors.Is(err, gorm.ErrRecordNotFound) {
+            log.WithError(err).Warn("Failed to load summary to structure")
+        }
+        return
+    }
+
+    var structured interface{}
+    metadata := gorm.Expr("COALESCE(metadata, '{}'::jsonb) - 'structured_error'")
+    parts, err := s.structureSummary(ctx, summary.Language, summary.Content)
+    if err != nil {
+        log.WithError(err).Warn("Failed to structure summary")
+        metadata = gorm.Expr("COALESCE(metadata, '{}'::jsonb) || jsonb_build_object('structured_error', ?::text)", err.Error())
+    } else {
+        partsJSON, _ := json.Marshal(parts)
+        structured = string(partsJSON)
+    }
+
+    // The structured form is part of the summary, so the version is bumped
+    result := s.DB.WithContext(ctx).Model(&model.Summary{}).
+        Where("id = ? AND version = ?", id, version).
+        Updates(map[string]interface{}{
+            "structured": structured,
+            "metadata":   metadata,
+            "version":    gorm.Expr("version + 1"),
+        })
+    if result.Error != nil {
+        log.WithError(result.Error).Warn("Failed to store structured summary")
+        return
+    }
+
+    if result.RowsAffected > 0 && summary.Kind != "synthesis" && !summary.IsPartial() {
+        s.indexEntities(summary.PDFID)
+    }
+}
+
+// indexEntities refreshes the entity index of a PDF from its new summary
+func (s *summaryService) indexEntities(pdfID uuid.UUID) {
+    if _, err := s.EntityService.IndexPDF(context.Background(), pdfID); err != nil {
+        s.Log.WithError(err).WithField("pdf_id", pdfID).Warn("Failed to index entities")
+    }
+}
+
+const maxStructuredItems = 20
+
+// structureSummary asks the ML service for the parts of a Markdown summary
+func (s *summaryService) structureSummary(ctx context.Context, language, content string) (*model.StructuredSummary, error) {
+    body, err := json.Marshal(map[string]string{
+        "summary":  content,
+        "language": language,
+    })
+    if err != nil {
+        return nil, err
+    }
+
+    req, err := http.NewRequestWithContext(ctx, "POST", config.MLServiceURL+"/structure", bytes.NewReader(body))
+    if err != nil {
+        return nil, err
+    }
+    req.Header.Set("Content-Type", "application/json")
+
+    client := &http.Client{
+        Timeout: 2 * time.Minute,
+    }
+    resp, err := client.Do(req)
+    if err != nil {
+        return nil, err
+    }
+    defer resp.Body.Close()
+
+    respBody, err := io.ReadAll(resp.Body)
+    if err != nil {
+        return nil, err
+    }
+    if resp.StatusCode != 200 {
+        return nil, fmt.Errorf("AI returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+    }
+
+    var parsed struct {
+        Structured json.RawMessage `json:"structured"`
+    }
+    if err := json.Unmarshal(respBody, &parsed); err != nil {
+        return nil, err
+    }
+    return parseStructured(parsed.Structured)
+}
+
+// parseStructured validates the structured form returned by the ML service.
+// Values are trimmed, empty and duplicate items dropped and lists capped.
+func parseStructured(data json.RawMessage) (*model.StructuredSummary, error) {
+    if len(data) == 0 || string(data) == "null" {
+        return nil, errors.New("structured summary missing from AI response")
+    }
+
+    var parts model.StructuredSummary
+    decoder := json.NewDecoder(bytes.NewReader(data))
+    decoder.DisallowUnknownFields()
+    if err := decoder.Decode(&parts); err != nil {
+        return nil, fmt.Errorf("invalid structured summary: %w", err)
+    }
+
+    parts.Purpose = strings.TrimSpace(parts.Purpose)
+    parts.KeyPoints = cleanItems(parts.KeyPoints)
+    parts.Findings = cleanItems(parts.Findings)
+    parts.Conclusions = cleanItems(parts.Conclusions)
+    parts.Keywords = cleanItems(parts.Keywords)
+    parts.Takeaways = cleanItems(parts.Takeaways)
+
+    if parts.Purpose == "" {
+        return nil, errors.New("invalid structured summary: purpose is empty")
+    }
+    if len(parts.KeyPoints) == 0 {
+        return nil, errors.New("invalid structured summary: no key points")
+    }
+    if len(parts.Keywords) == 0 {
+        return nil, errors.New("invalid structured summary: no keywords")
+    }
+
+    return &parts, nil
+}
+
+func cleanItems(items []string) []string {
+    cleaned := make([]string, 0, len(items))
+    seen := map[string]bool{}
+    for _, item := range items {
+        // Models sometimes keep the Markdown emphasis of the summary
+        item = strings.TrimSpace(strings.Trim(strings.TrimSpace(item), "*_`"))
+        key := strings.ToLower(item)
+        if item == "" || seen[key] {
+            continue
+        }
+        seen[key] = true
+        cleaned = append(cleaned, item)
+        if len(cleaned) == maxStructuredItems {
+            break
+        }
+    }
+    return cleaned
+}
+
+func (s *summaryService) failSummary(ctx context.Context, id uuid.UUID, msg string, err error) {
+    meta := map[string]interface{}{}
+    if err != nil {
+        meta["error"] = err.Error()
+    }
+
+    metaJSON, _ := json.Marshal(meta)
+
+    _ = s.DB.WithContext(ctx).Model(&model.Summary{}).
+        Where("id = ?", id).
+        Updates(map[string]interface{}{
+            "status":   "failed",
+            "metadata": string(metaJSON),
+            "version":  gorm.Expr("version + 1"),
+        }).Error
+
+    s.createProcessingLog(ctx, "summary", id, "generate", "failed", msg, meta)
+}
+
+func (s *summaryService) GetRevisions(ctx context.Context, id uuid.UUID) ([]model.SummaryRevision, error) {
+    if _, err := s.GetByID(ctx, id); err != nil {
+        return nil, err
+    }
+
+    var revisions []model.SummaryRevision
+    if err := s.DB.WithContext(ctx).
+        Where("summary_id = ?", id).
+        Order("revision_number DESC").
+        Find(&revisions).Error; err != nil {
+        return nil, err
+    }
+    return revisions, nil
+}
+
+func (s *summaryService) GetRevision(ctx context.Context, id uuid.UUID, number int) (*model.SummaryRevision, error) {
+    var revision model.SummaryRevision
+    if err := s.DB.WithContext(ctx).
+        Where("summary_id = ? AND revision_number = ?", id, number).
+        First(&revision).Error; err != nil {
+        return nil, err
+    }
+    return &revision, nil
+}
+
+// RestoreRevision copies the content of an earlier revision back into the
+// summary. History is append-only, so the restore becomes a new revision.
+// The structured form is rebuilt in the background.
+func (s *summaryService) RestoreRevision(ctx context.Context, id uuid.UUID, number int, author string) (*model.Summary, error) {
+    var restored int
+    err := s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+        current, err := s.lockSummary(tx, id)
+        if err != nil {
+            return err
+        }
+
+        var revision model.SummaryRevision
+        if err := tx.Where("summary_id = ? AND revision_number = ?", id, number).
+            First(&revision).Error; err != nil {
+            return err
+        }
+
+        if err := tx.Model(&model.Summary{}).
+            Where("id = ?", id).
+            Updates(map[string]interface{}{
+                "content":       revision.Content,
+                "search_tokens": utils.SegmentCJK(revision.Content),
+                "structured":    nil,
+                "metadata":      gorm.Expr("COALESCE(metadata, '{}'::jsonb) - 'structured_error'"),
+                "is_edited":     true,
+                "version":       gorm.Expr("version + 1"),
+                "updated_at":    time.Now(),
+            }).Error; err != nil {
+            return err
+        }
+        restored = current.Version + 1
+
+        _, err = s.addRevision(tx, id, revision.Content, "restore", author, &revision.RevisionNumber)
+        return err
+    })
+    if err != nil {
+        return nil, err
+    }
+
+    go s.restructure(id, restored)
+
+    s.createProcessingLog(ctx, "summary", id, "restore", "success", "Summary restored from revision", map[string]interface{}{
+        "revision": number,
+        "author":   author,
+    })
+
+    return s.GetByID(ctx, id)
+}
+
+// DiffRevisions compares two revisions of a summary. A zero from selects the
+// first revision (usually the AI draft) and a zero to selects the latest one.
+func (s *summaryService) DiffRevisions(ctx context.Context, id uuid.UUID, from, to, contextLines int) (*SummaryDiff, error) {
+    revisions, err := s.GetRevisions(ctx, id)
+    if err != nil {
+        return nil, err
+    }
+    if len(revisions) == 0 {
+        return nil, gorm.ErrRecordNotFound
+    }
+
+    // Revisions are ordered newest first
+    if from == 0 {
+        from = revisions[len(revisions)-1].RevisionNumber
+    }
+    if to == 0 {
+        to = revisions[0].RevisionNumber
+    }
+
+    var oldRev, newRev *model.SummaryRevision
+    for i := range revisions {
+        if revisions[i].RevisionNumber == from {
+            oldRev = &revisions[i]
+        }
+        if revisions[i].RevisionNumber == to {
+            newRev = &revisions[i]
+        }
+    }
+    if oldRev == nil || newRev == nil {
+        return nil, gorm.ErrRecordNotFound
+    }
+
+    return &SummaryDiff{
+        From: revisionSide(oldRev),
+        To:   revisionSide(newRev),
+        Diff: utils.DiffText(oldRev.Content, newRev.Content,
+            fmt.Sprintf("revision %d", oldRev.RevisionNumber),
+            fmt.Sprintf("revision %d", newRev.RevisionNumber),
+            contextLines),
+    }, nil
+}
+
+// DiffSummaries compares the current content of two summaries of the same PDF,
+// e.g. a professional and a simple summary
+func (s *summaryService) DiffSummaries(ctx context.Context, id, otherID uuid.UUID, contextLines int) (*SummaryDiff, error) {
+    summary, err := s.GetByID(ctx, id)
+    if err != nil {
+        return nil, err
+    }
+    other, err := s.GetByID(ctx, otherID)
+    if err != nil {
+        return nil, err
+    }
+    if summary.PDFID != other.PDFID {
+        return nil, ErrDifferentPDFs
+    }
+
+    return &SummaryDiff{
+        From: summarySide(summary),
+        To:   summarySide(other),
+        Diff: utils.DiffText(summary.Content, other.Content,
+            fmt.Sprintf("%s (%s, %s)", summary.ID, summary.Style, summary.Language),
+            fmt.Sprintf("%s (%s, %s)", other.ID, other.Style, other.Language),
+            contextLines),
+    }, nil
+}
+
+func revisionSide(revision *model.SummaryRevision) DiffSide {
+    return DiffSide{
+        SummaryID: revision.SummaryID,
+        Revision:  revision.RevisionNumber,
+        Source:    revision.Source,
+        Author:    revision.Author,
+        CreatedAt: revision.CreatedAt,
+    }
+}
+
+func summarySide(summary *model.Summary) DiffSide {
+    return DiffSide{
+        SummaryID: summary.ID,
+        Language:  summary.Language,
+        Style:     summary.Style,
+        CreatedAt: summary.CreatedAt,
+    }
+}
+
+// Attributions returns the source pages of every block of a summary. Stored
+// attributions are used while the content they were computed for is current,
+// otherwise they are computed from the extracted text of the source pages.
+func (s *summaryService) Attributions(ctx context.Context, id uuid.UUID) (*SummaryAttributions, error) {
+    summary, err := s.GetByID(ctx, id)
+    if err != nil {
+        return nil, err
+    }
+    if summary.Status != "completed" {
+        return nil, ErrSummaryNotCompleted
+    }
+
+    result := &SummaryAttributions{
+        SummaryID: summary.ID,
+        Version:   summary.Version,
+        Content:   summary.Content,
+    }
+
+    if summary.Metadata != nil {
+        var metadata struct {
+            Attributions *storedAttributions `json:"attributions"`
+        }
+        if err := json.Unmarshal(*summary.Metadata, &metadata); err == nil &&
+            metadata.Attributions != nil && metadata.Attributions.ContentHash == contentHash(summary.Content) {
+            result.Stored = true
+            result.Blocks = metadata.Attributions.Blocks
+            return result, nil
+        }
+    }
+
+    pdfIDs := []uuid.UUID{summary.PDFID}
+    if len(summary.Sources) > 0 {
+        pdfIDs = pdfIDs[:0]
+        for _, source := range summary.Sources {
+            pdfIDs = append(pdfIDs, source.PDFID)
+        }
+    }
+
+    var pages []model.PDFPage
+    for _, pdfID := range pdfIDs {
+        found, err := s.ExtractionService.GetPages(ctx, pdfID)
+        if err != nil {
+            return nil, err
+        }
+        for _, page := range found {
+            if summary.InRange(page.PageNumber) {
+                pages = append(pages, page)
+            }
+        }
+    }
+
+    result.Blocks = attribute(summary.Content, sourcePages(pages)).Blocks
+    return result, nil
+}
+
+func attribute(content string, pages []utils.SourcePage) storedAttributions {
+    return storedAttributions{
+        ContentHash: contentHash(content),
+        Blocks:      utils.AttributeBlocks(content, pages),
+    }
+}
+
+func contentHash(content string) string {
+    sum := sha256.Sum256([]byte(content))
+    return hex.EncodeToString(sum[:])
+}
+
+func sourcePages(pages []model.PDFPage) []utils.SourcePage {
+    sources := make([]utils.SourcePage, 0, len(pages))
+    for _, page := range pages {
+        sources = append(sources, utils.SourcePage{
+            PDFID: page.PDFID.String(),
+            Page:  page.PageNumber,
+            Text:  page.Content(),
+        })
+    }
+    return sources
+}
+
+// lockSummary takes a row lock so concurrent edits get sequential revision
+// numbers and see the version they are about to replace
+func (s *summaryService) lockSummary(tx *gorm.DB, id uuid.UUID) (*model.Summary, error) {
+    var summary model.Summary
+    if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+        Select("id", "version").
+        First(&summary, "id = ?", id).Error; err != nil {
+        return nil, err
+    }
+    return &summary, nil
+}
+
+func (s *summaryService) addRevision(tx *gorm.DB, summaryID uuid.UUID, content, source, author string, restoredFrom *int) (*model.SummaryRevision, error) {
+    var latest int
+    if err := tx.Model(&model.SummaryRevision{}).
+        Where("summary_id = ?", summaryID).
+        Select("COALESCE(MAX(revision_number), 0)").
+        Scan(&latest).Error; err != nil {
+        return nil, err
+    }
+
+    revision := &model.SummaryRevision{
+        SummaryID:      summaryID,
+        RevisionNumber: latest + 1,
+        Content:        content,
+        Source:         source,
+        Author:         author,
+        RestoredFrom:   restoredFrom,
+        CreatedAt:      time.Now(),
+    }
+    if err := tx.Create(revision).Error; err != nil {
+        return nil, err
+    }
+    return revision, nil
+}
+
+func joinPageText(pages []model.PDFPage) string {
+    texts := make([]string, 0, len(pages))
+    for _, page := range pages {
+        if text := page.Content(); text != "" {
+            texts = append(texts, text)
+        }
+    }
+    return strings.Join(texts, "\n")
+}
+
+func countOCRPages(pages []model.PDFPage) int {
+    count := 0
+    for _, page := range pages {
+        if strings.TrimSpace(page.Text) == "" && page.OCRText != "" {
+            count++
+        }
+    }
+    return count
 }
 
 func encodeJSONNoEscape(v interface{}) (string, error) {
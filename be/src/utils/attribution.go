@@ -0,0 +1,215 @@
+package utils
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+const (
+	// Share of a block's token weight a page must contain to be cited
+	minAttributionScore = 0.3
+	// Other pages are cited when they score close to the best one
+	attributionPageRatio = 0.8
+	maxAttributionPages  = 3
+)
+
+var (
+	listItemPattern = regexp.MustCompile(`^\s*(?:[-*+]|\d+[.)])\s+`)
+	headingPattern  = regexp.MustCompile(`^\s{0,3}#{1,6}\s`)
+)
+
+// SourcePage is the extracted text of one page a summary may be based on
+type SourcePage struct {
+	PDFID string
+	Page  int
+	Text  string
+}
+
+type PageRef struct {
+	PDFID string `json:"pdf_id"`
+	Page  int    `json:"page"`
+}
+
+// Attribution links a paragraph or list item of a summary to the pages it was
+// most likely derived from. Pages is empty when no page matches well enough.
+type Attribution struct {
+	Block     int       `json:"block"`
+	Kind      string    `json:"kind"` // paragraph or item
+	StartLine int       `json:"start_line"`
+	EndLine   int       `json:"end_line"`
+	Text      string    `json:"text"`
+	Pages     []PageRef `json:"pages"`
+	Score     float64   `json:"score"`
+}
+
+type summaryBlock struct {
+	kind      string
+	startLine int
+	endLine   int
+	lines     []string
+}
+
+// splitBlocks returns the paragraphs and list items of Markdown content.
+// Headings, tables, rules and code blocks carry no claims and are skipped.
+func splitBlocks(content string) []summaryBlock {
+	var blocks []summaryBlock
+	var current *summaryBlock
+	inCode := false
+
+	flush := func() {
+		if current != nil {
+			blocks = append(blocks, *current)
+			current = nil
+		}
+	}
+
+	for i, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") {
+			flush()
+			inCode = !inCode
+			continue
+		}
+		if inCode || trimmed == "" || headingPattern.MatchString(line) ||
+			strings.HasPrefix(trimmed, "|") || strings.Trim(trimmed, "-*_ ") == "" {
+			flush()
+			continue
+		}
+
+		if listItemPattern.MatchString(line) {
+			flush()
+			current = &summaryBlock{kind: "item", startLine: i + 1}
+		} else if current == nil {
+			current = &summaryBlock{kind: "paragraph", startLine: i + 1}
+		}
+		current.lines = append(current.lines, trimmed)
+		current.endLine = i + 1
+	}
+	flush()
+
+	return blocks
+}
+
+// attributionTokens returns the distinct words of text, ignoring very short
+// ones, with CJK runs split into bigrams
+func attributionTokens(text string) map[string]bool {
+	tokens := map[string]bool{}
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+	for _, word := range words {
+		if ContainsCJK(word) {
+			for _, gram := range strings.Fields(SegmentCJK(word)) {
+				tokens[gram] = true
+			}
+			continue
+		}
+		if len([]rune(word)) >= 3 {
+			tokens[word] = true
+		}
+	}
+	return tokens
+}
+
+// AttributeBlocks matches every block of a summary against the source pages.
+// Tokens are weighted by inverse page frequency, so distinctive terms decide
+// the match. Summaries in another language than the document match nothing.
+func AttributeBlocks(content string, pages []SourcePage) []Attribution {
+	pageTokens := make([]map[string]bool, len(pages))
+	df := map[string]int{}
+	for i, page := range pages {
+		pageTokens[i] = attributionTokens(page.Text)
+		for token := range pageTokens[i] {
+			df[token]++
+		}
+	}
+
+	idf := func(token string) float64 {
+		return math.Log(1 + float64(len(pages))/float64(df[token]+1))
+	}
+
+	blocks := splitBlocks(content)
+	attributions := make([]Attribution, 0, len(blocks))
+	for i, block := range blocks {
+		text := strings.Join(block.lines, " ")
+		attribution := Attribution{
+			Block:     i,
+			Kind:      block.kind,
+			StartLine: block.startLine,
+			EndLine:   block.endLine,
+			Text:      text,
+			Pages:     []PageRef{},
+		}
+
+		tokens := attributionTokens(listItemPattern.ReplaceAllString(text, ""))
+		var total float64
+		for token := range tokens {
+			total += idf(token)
+		}
+
+		if total > 0 {
+			type pageScore struct {
+				index int
+				score float64
+			}
+			scores := make([]pageScore, 0, len(pages))
+			for p := range pages {
+				var matched float64
+				for token := range tokens {
+					if pageTokens[p][token] {
+						matched += idf(token)
+					}
+				}
+				if matched > 0 {
+					scores = append(scores, pageScore{p, matched / total})
+				}
+			}
+			sort.SliceStable(scores, func(a, b int) bool {
+				return scores[a].score > scores[b].score
+			})
+
+			if len(scores) > 0 && scores[0].score >= minAttributionScore {
+				attribution.Score = math.Round(scores[0].score*1000) / 1000
+				for _, s := range scores {
+					if len(attribution.Pages) == maxAttributionPages || s.score < scores[0].score*attributionPageRatio {
+						break
+					}
+					attribution.Pages = append(attribution.Pages, PageRef{PDFID: pages[s.index].PDFID, Page: pages[s.index].Page})
+				}
+				sort.Slice(attribution.Pages, func(a, b int) bool {
+					if attribution.Pages[a].PDFID != attribution.Pages[b].PDFID {
+						return attribution.Pages[a].PDFID < attribution.Pages[b].PDFID
+					}
+					return attribution.Pages[a].Page < attribution.Pages[b].Page
+				})
+			}
+		}
+
+		attributions = append(attributions, attribution)
+	}
+
+	return attributions
+}
+
+// AnnotateMarkdown appends page anchors like [p. 3](link) to the last line of
+// every attributed block
+func AnnotateMarkdown(content string, attributions []Attribution, link func(PageRef) string) string {
+	lines := strings.Split(content, "\n")
+	for _, attribution := range attributions {
+		if len(attribution.Pages) == 0 || attribution.EndLine < 1 || attribution.EndLine > len(lines) {
+			continue
+		}
+
+		anchors := make([]string, len(attribution.Pages))
+		for i, page := range attribution.Pages {
+			anchors[i] = fmt.Sprintf("[p. %d](%s)", page.Page, link(page))
+		}
+		line := attribution.EndLine - 1
+		lines[line] = strings.TrimRight(lines[line], " ") + " " + strings.Join(anchors, " ")
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,206 @@
+package utils
+
+import (
+	"html"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Postgres' parsers do not segment Chinese, Japanese and Korean text, a whole
+// run of characters becomes one lexeme. These helpers index overlapping
+// bigrams of every CJK run instead and build matching phrase queries.
+
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul)
+}
+
+// ContainsCJK reports whether text contains any CJK character
+func ContainsCJK(text string) bool {
+	for _, r := range text {
+		if isCJK(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// cjkRuns returns the maximal runs of CJK characters in text
+func cjkRuns(text string) [][]rune {
+	var runs [][]rune
+	var current []rune
+	for _, r := range text {
+		if isCJK(r) {
+			current = append(current, r)
+			continue
+		}
+		if len(current) > 0 {
+			runs = append(runs, current)
+			current = nil
+		}
+	}
+	if len(current) > 0 {
+		runs = append(runs, current)
+	}
+	return runs
+}
+
+func bigrams(run []rune) []string {
+	if len(run) == 1 {
+		return []string{string(run)}
+	}
+	grams := make([]string, 0, len(run)-1)
+	for i := 0; i+1 < len(run); i++ {
+		grams = append(grams, string(run[i:i+2]))
+	}
+	return grams
+}
+
+// SegmentCJK returns the space separated bigrams of all CJK runs in text, e.g.
+// "機械学習" becomes "機械 械学 学習". Text without CJK returns an empty string.
+func SegmentCJK(text string) string {
+	var tokens []string
+	for _, run := range cjkRuns(text) {
+		tokens = append(tokens, bigrams(run)...)
+	}
+	return strings.Join(tokens, " ")
+}
+
+// CJKQuery converts a search query containing CJK text into a to_tsquery
+// expression. CJK runs become bigram phrases ("機械 <-> 械学 <-> 学習"), a single
+// character becomes a prefix match and other words must match as they are.
+// The returned terms are used for highlighting. ok is false without CJK text.
+func CJKQuery(query string) (tsquery string, terms []string, ok bool) {
+	if !ContainsCJK(query) {
+		return "", nil, false
+	}
+
+	var parts []string
+	for _, field := range strings.Fields(query) {
+		field = strings.Trim(field, `"'`)
+
+		var word []rune
+		flushWord := func() {
+			w := strings.ToLower(strings.TrimFunc(string(word), func(r rune) bool {
+				return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+			}))
+			if w != "" {
+				parts = append(parts, quoteLexeme(w))
+				terms = append(terms, w)
+			}
+			word = nil
+		}
+
+		var run []rune
+		flushRun := func() {
+			if len(run) == 0 {
+				return
+			}
+			grams := bigrams(run)
+			if len(grams) == 1 && len(run) == 1 {
+				parts = append(parts, quoteLexeme(grams[0])+":*")
+			} else {
+				quoted := make([]string, len(grams))
+				for i, gram := range grams {
+					quoted[i] = quoteLexeme(gram)
+				}
+				parts = append(parts, "("+strings.Join(quoted, " <-> ")+")")
+			}
+			terms = append(terms, string(run))
+			run = nil
+		}
+
+		for _, r := range field {
+			if isCJK(r) {
+				flushWord()
+				run = append(run, r)
+			} else {
+				flushRun()
+				word = append(word, r)
+			}
+		}
+		flushWord()
+		flushRun()
+	}
+
+	if len(parts) == 0 {
+		return "", nil, false
+	}
+	return strings.Join(parts, " & "), terms, true
+}
+
+func quoteLexeme(lexeme string) string {
+	lexeme = strings.ReplaceAll(lexeme, `\`, `\\`)
+	return "'" + strings.ReplaceAll(lexeme, "'", "''") + "'"
+}
+
+// HighlightTerms returns an HTML escaped excerpt of text around the first
+// matching term, with every term occurrence wrapped in <mark>
+func HighlightTerms(text string, terms []string, radius int) string {
+	text = strings.Join(strings.Fields(text), " ")
+	lower := strings.ToLower(text)
+	// Lowercasing may change byte lengths for a few scripts, match exactly then
+	if len(lower) != len(text) {
+		lower = text
+	}
+
+	first := -1
+	for _, term := range terms {
+		if i := strings.Index(lower, term); i >= 0 && (first < 0 || i < first) {
+			first = i
+		}
+	}
+
+	start, end := 0, len(text)
+	if first >= 0 {
+		start = moveRunes(text, first, -radius/3)
+		end = moveRunes(text, first, radius)
+	} else {
+		end = moveRunes(text, 0, radius)
+	}
+
+	var out strings.Builder
+	if start > 0 {
+		out.WriteString("… ")
+	}
+
+	for i := start; i < end; {
+		matched := ""
+		for _, term := range terms {
+			if term != "" && strings.HasPrefix(lower[i:], term) && len(term) > len(matched) {
+				matched = term
+			}
+		}
+		if matched != "" {
+			stop := i + len(matched)
+			if stop > end {
+				stop = end
+			}
+			out.WriteString("<mark>" + html.EscapeString(text[i:stop]) + "</mark>")
+			i = stop
+			continue
+		}
+
+		_, size := utf8.DecodeRuneInString(text[i:])
+		out.WriteString(html.EscapeString(text[i : i+size]))
+		i += size
+	}
+
+	if end < len(text) {
+		out.WriteString(" …")
+	}
+	return out.String()
+}
+
+// moveRunes returns the byte offset n runes away from offset, clamped to text
+func moveRunes(text string, offset, n int) int {
+	for ; n < 0 && offset > 0; n++ {
+		_, size := utf8.DecodeLastRuneInString(text[:offset])
+		offset -= size
+	}
+	for ; n > 0 && offset < len(text); n-- {
+		_, size := utf8.DecodeRuneInString(text[offset:])
+		offset += size
+	}
+	return offset
+}
@@ -0,0 +1,48 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ContentDisposition builds a Content-Disposition header value for the given
+// disposition type ("inline" or "attachment") and file name. An ASCII fallback
+// is sent in filename for old clients, the exact name in filename* (RFC 6266/5987).
+func ContentDisposition(disposition, filename string) string {
+	var fallback strings.Builder
+	for _, r := range filename {
+		switch {
+		case r == '"' || r == '\\' || r < 0x20 || r > 0x7e:
+			fallback.WriteRune('_')
+		default:
+			fallback.WriteRune(r)
+		}
+	}
+
+	return fmt.Sprintf(`%s; filename="%s"; filename*=UTF-8''%s`, disposition, fallback.String(), encodeRFC5987(filename))
+}
+
+// encodeRFC5987 percent-encodes everything except the attr-char set
+func encodeRFC5987(value string) string {
+	const hex = "0123456789ABCDEF"
+
+	var sb strings.Builder
+	for _, b := range []byte(value) {
+		if isAttrChar(b) {
+			sb.WriteByte(b)
+			continue
+		}
+		sb.WriteByte('%')
+		sb.WriteByte(hex[b>>4])
+		sb.WriteByte(hex[b&0x0f])
+	}
+	return sb.String()
+}
+
+func isAttrChar(b byte) bool {
+	switch {
+	case b >= 'a' && b <= 'z', b >= 'A' && b <= 'Z', b >= '0' && b <= '9':
+		return true
+	}
+	return strings.IndexByte("!#$&+-.^_`|~", b) >= 0
+}
@@ -0,0 +1,335 @@
+package utils
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// wordToken splits prose into words, whitespace and punctuation. Han, kana and
+// Thai characters are not separated by spaces, so each one is its own token.
+var wordToken = regexp.MustCompile(`\s+|[\p{Han}\p{Hiragana}\p{Katakana}\p{Thai}]|[\p{L}\p{M}\p{N}_'’]+|.`)
+
+type DiffOp struct {
+	Type string `json:"type"` // equal, insert or delete
+	Text string `json:"text"`
+}
+
+type DiffLine struct {
+	Type    string `json:"type"`
+	Text    string `json:"text"`
+	OldLine int    `json:"old_line,omitempty"`
+	NewLine int    `json:"new_line,omitempty"`
+}
+
+type DiffHunk struct {
+	OldStart int        `json:"old_start"`
+	OldLines int        `json:"old_lines"`
+	NewStart int        `json:"new_start"`
+	NewLines int        `json:"new_lines"`
+	Lines    []DiffLine `json:"lines"`
+	Words    []DiffOp   `json:"words"`
+}
+
+type DiffStats struct {
+	Insertions       int `json:"insertions"`
+	Deletions        int `json:"deletions"`
+	WordsAdded       int `json:"words_added"`
+	WordsRemoved     int `json:"words_removed"`
+	UnchangedPercent int `json:"unchanged_percent"`
+}
+
+type Diff struct {
+	Unified string     `json:"unified"`
+	Hunks   []DiffHunk `json:"hunks"`
+	Stats   DiffStats  `json:"stats"`
+}
+
+// DiffText compares two texts line by line and, inside every hunk, word by word.
+// contextLines is the number of unchanged lines kept around each change.
+func DiffText(oldText, newText, oldLabel, newLabel string, contextLines int) *Diff {
+	oldLines := splitLines(oldText)
+	newLines := splitLines(newText)
+	ops := myers(oldLines, newLines)
+
+	diff := &Diff{Hunks: []DiffHunk{}}
+
+	// Number every line of the edit script
+	lines := make([]DiffLine, 0, len(ops))
+	oldNo, newNo := 0, 0
+	for _, op := range ops {
+		line := DiffLine{Type: op.Type, Text: op.Text}
+		switch op.Type {
+		case "equal":
+			oldNo++
+			newNo++
+			line.OldLine, line.NewLine = oldNo, newNo
+		case "delete":
+			oldNo++
+			line.OldLine = oldNo
+			diff.Stats.Deletions++
+		case "insert":
+			newNo++
+			line.NewLine = newNo
+			diff.Stats.Insertions++
+		}
+		lines = append(lines, line)
+	}
+
+	// Group changes that are closer than twice the context into one hunk
+	for i := 0; i < len(lines); {
+		if lines[i].Type == "equal" {
+			i++
+			continue
+		}
+
+		start := i - contextLines
+		if start < 0 {
+			start = 0
+		}
+		end := i
+		for j := i; j < len(lines); j++ {
+			if lines[j].Type != "equal" {
+				end = j
+				continue
+			}
+			if j-end > 2*contextLines {
+				break
+			}
+		}
+		stop := end + contextLines + 1
+		if stop > len(lines) {
+			stop = len(lines)
+		}
+
+		oldBefore, newBefore := 0, 0
+		for _, line := range lines[:start] {
+			if line.OldLine > 0 {
+				oldBefore = line.OldLine
+			}
+			if line.NewLine > 0 {
+				newBefore = line.NewLine
+			}
+		}
+
+		diff.Hunks = append(diff.Hunks, buildHunk(lines[start:stop], oldBefore, newBefore))
+		i = stop
+	}
+
+	var unified strings.Builder
+	if len(diff.Hunks) > 0 {
+		fmt.Fprintf(&unified, "--- %s\n+++ %s\n", oldLabel, newLabel)
+	}
+	for _, hunk := range diff.Hunks {
+		fmt.Fprintf(&unified, "@@ -%s +%s @@\n", hunkRange(hunk.OldStart, hunk.OldLines), hunkRange(hunk.NewStart, hunk.NewLines))
+		for _, line := range hunk.Lines {
+			prefix := " "
+			if line.Type == "delete" {
+				prefix = "-"
+			} else if line.Type == "insert" {
+				prefix = "+"
+			}
+			unified.WriteString(prefix + line.Text + "\n")
+		}
+
+		for _, word := range hunk.Words {
+			if strings.TrimSpace(word.Text) == "" {
+				continue
+			}
+			if word.Type == "insert" {
+				diff.Stats.WordsAdded++
+			} else if word.Type == "delete" {
+				diff.Stats.WordsRemoved++
+			}
+		}
+	}
+	diff.Unified = unified.String()
+
+	total := len(oldLines)
+	if len(newLines) > total {
+		total = len(newLines)
+	}
+	diff.Stats.UnchangedPercent = 100
+	if total > 0 {
+		diff.Stats.UnchangedPercent = (total - max(diff.Stats.Insertions, diff.Stats.Deletions)) * 100 / total
+	}
+
+	return diff
+}
+
+// buildHunk numbers a hunk from the count of old and new lines before it
+func buildHunk(lines []DiffLine, oldBefore, newBefore int) DiffHunk {
+	hunk := DiffHunk{Lines: lines}
+
+	var oldText, newText []string
+	for _, line := range lines {
+		if line.Type != "insert" {
+			hunk.OldLines++
+			oldText = append(oldText, line.Text)
+		}
+		if line.Type != "delete" {
+			hunk.NewLines++
+			newText = append(newText, line.Text)
+		}
+	}
+
+	// Empty sides point at the line before the change, as in unified diffs
+	hunk.OldStart, hunk.NewStart = oldBefore, newBefore
+	if hunk.OldLines > 0 {
+		hunk.OldStart++
+	}
+	if hunk.NewLines > 0 {
+		hunk.NewStart++
+	}
+
+	hunk.Words = mergeOps(myers(
+		wordToken.FindAllString(strings.Join(oldText, "\n"), -1),
+		wordToken.FindAllString(strings.Join(newText, "\n"), -1),
+	))
+
+	return hunk
+}
+
+// hunkRange formats one side of a hunk header, a single line has no count
+func hunkRange(start, count int) string {
+	if count == 1 {
+		return fmt.Sprintf("%d", start)
+	}
+	return fmt.Sprintf("%d,%d", start, count)
+}
+
+func splitLines(text string) []string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.TrimSuffix(text, "\n")
+	if text == "" {
+		return nil
+	}
+	return strings.Split(text, "\n")
+}
+
+// mergeOps joins consecutive tokens of the same type
+func mergeOps(ops []DiffOp) []DiffOp {
+	merged := []DiffOp{}
+	for _, op := range ops {
+		if n := len(merged); n > 0 && merged[n-1].Type == op.Type {
+			merged[n-1].Text += op.Text
+			continue
+		}
+		merged = append(merged, op)
+	}
+	return merged
+}
+
+// Edit distance after which the texts are treated as unrelated and replaced
+// wholesale, this bounds memory for the trace on very different inputs
+const maxEditDistance = 2000
+
+// myers computes the shortest edit script between a and b (Myers, 1986)
+func myers(a, b []string) []DiffOp {
+	// Common prefix and suffix never need the search
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	ops := make([]DiffOp, 0, len(a)+len(b))
+	for _, text := range a[:prefix] {
+		ops = append(ops, DiffOp{Type: "equal", Text: text})
+	}
+	ops = append(ops, myersMiddle(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for _, text := range a[len(a)-suffix:] {
+		ops = append(ops, DiffOp{Type: "equal", Text: text})
+	}
+	return ops
+}
+
+func myersMiddle(a, b []string) []DiffOp {
+	n, m := len(a), len(b)
+	maxD := n + m
+	if maxD > maxEditDistance {
+		maxD = maxEditDistance
+	}
+	offset := n + m + 1
+	v := make([]int, 2*(n+m)+3)
+	var trace [][]int
+
+	for d := 0; d <= maxD; d++ {
+		// Only diagonals -d-1..d+1 are read when backtracking round d
+		trace = append(trace, append([]int(nil), v[offset-d-1:offset+d+2]...))
+
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+
+			if x >= n && y >= m {
+				return backtrack(trace, a, b, d)
+			}
+		}
+	}
+
+	ops := make([]DiffOp, 0, n+m)
+	for _, text := range a {
+		ops = append(ops, DiffOp{Type: "delete", Text: text})
+	}
+	for _, text := range b {
+		ops = append(ops, DiffOp{Type: "insert", Text: text})
+	}
+	return ops
+}
+
+func backtrack(trace [][]int, a, b []string, d int) []DiffOp {
+	x, y := len(a), len(b)
+	var ops []DiffOp
+
+	for ; d > 0; d-- {
+		v := trace[d]
+		at := func(k int) int { return v[k+d+1] }
+		k := x - y
+
+		var prevK int
+		if k == -d || (k != d && at(k-1) < at(k+1)) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := at(prevK)
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			x--
+			y--
+			ops = append(ops, DiffOp{Type: "equal", Text: a[x]})
+		}
+		if x == prevX {
+			y--
+			ops = append(ops, DiffOp{Type: "insert", Text: b[y]})
+		} else {
+			x--
+			ops = append(ops, DiffOp{Type: "delete", Text: a[x]})
+		}
+	}
+	for x > 0 && y > 0 {
+		x--
+		y--
+		ops = append(ops, DiffOp{Type: "equal", Text: a[x]})
+	}
+
+	for i, j := 0, len(ops)-1; i < j; i, j = i+1, j-1 {
+		ops[i], ops[j] = ops[j], ops[i]
+	}
+	return ops
+}
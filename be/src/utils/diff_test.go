@@ -0,0 +1,133 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func diffInput(fields string) string {
+	if fields == "" {
+		return ""
+	}
+	return strings.Join(strings.Fields(fields), "\n") + "\n"
+}
+
+// Expected output is taken from GNU diffutils: diff -U <context> --label old --label new
+func TestDiffTextMatchesUnifiedDiff(t *testing.T) {
+	tests := []struct {
+		name    string
+		old     string
+		new     string
+		context int
+		want    string
+	}{
+		{
+			name:    "insert after a moved prefix",
+			old:     "a b c d e",
+			new:     "x y a b c d NEW e",
+			context: 0,
+			want:    "--- old\n+++ new\n@@ -0,0 +1,2 @@\n+x\n+y\n@@ -4,0 +7 @@\n+NEW\n",
+		},
+		{
+			name:    "insert after a moved prefix with context",
+			old:     "a b c d e",
+			new:     "x y a b c d NEW e",
+			context: 3,
+			want:    "--- old\n+++ new\n@@ -1,5 +1,8 @@\n+x\n+y\n a\n b\n c\n d\n+NEW\n e\n",
+		},
+		{
+			name:    "insert at the start",
+			old:     "a b",
+			new:     "z a b",
+			context: 0,
+			want:    "--- old\n+++ new\n@@ -0,0 +1 @@\n+z\n",
+		},
+		{
+			name:    "append at the end",
+			old:     "a b",
+			new:     "a b c",
+			context: 0,
+			want:    "--- old\n+++ new\n@@ -2,0 +3 @@\n+c\n",
+		},
+		{
+			name:    "delete at the start",
+			old:     "a b c",
+			new:     "b c",
+			context: 0,
+			want:    "--- old\n+++ new\n@@ -1 +0,0 @@\n-a\n",
+		},
+		{
+			name:    "delete at the end",
+			old:     "a b c",
+			new:     "a b",
+			context: 1,
+			want:    "--- old\n+++ new\n@@ -2,2 +2 @@\n b\n-c\n",
+		},
+		{
+			name:    "replace one line",
+			old:     "a b c d e",
+			new:     "a b X d e",
+			context: 1,
+			want:    "--- old\n+++ new\n@@ -2,3 +2,3 @@\n b\n-c\n+X\n d\n",
+		},
+		{
+			name:    "separate hunks",
+			old:     "1 2 3 4 5 6 7 8 9 10 11 12",
+			new:     "1 2 X 4 5 6 7 8 9 10 Y 12",
+			context: 2,
+			want:    "--- old\n+++ new\n@@ -1,5 +1,5 @@\n 1\n 2\n-3\n+X\n 4\n 5\n@@ -9,4 +9,4 @@\n 9\n 10\n-11\n+Y\n 12\n",
+		},
+		{
+			name:    "nearby changes share a hunk",
+			old:     "1 2 3 4 5 6 7 8",
+			new:     "1 X 3 4 5 Y 7 8",
+			context: 2,
+			want:    "--- old\n+++ new\n@@ -1,8 +1,8 @@\n 1\n-2\n+X\n 3\n 4\n 5\n-6\n+Y\n 7\n 8\n",
+		},
+		{
+			name:    "from empty",
+			old:     "",
+			new:     "a b",
+			context: 3,
+			want:    "--- old\n+++ new\n@@ -0,0 +1,2 @@\n+a\n+b\n",
+		},
+		{
+			name:    "to empty",
+			old:     "a b",
+			new:     "",
+			context: 3,
+			want:    "--- old\n+++ new\n@@ -1,2 +0,0 @@\n-a\n-b\n",
+		},
+		{
+			name:    "identical",
+			old:     "a b",
+			new:     "a b",
+			context: 3,
+			want:    "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := DiffText(diffInput(tt.old), diffInput(tt.new), "old", "new", tt.context).Unified
+			if got != tt.want {
+				t.Errorf("DiffText() unified =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiffTextHunkStarts(t *testing.T) {
+	diff := DiffText(diffInput("a b c d e"), diffInput("x y a b c d NEW e"), "old", "new", 0)
+	if len(diff.Hunks) != 2 {
+		t.Fatalf("got %d hunks, want 2", len(diff.Hunks))
+	}
+
+	hunk := diff.Hunks[1]
+	if hunk.OldStart != 4 || hunk.OldLines != 0 || hunk.NewStart != 7 || hunk.NewLines != 1 {
+		t.Errorf("hunk = -%d,%d +%d,%d, want -4,0 +7,1", hunk.OldStart, hunk.OldLines, hunk.NewStart, hunk.NewLines)
+	}
+	if diff.Stats.Insertions != 3 || diff.Stats.Deletions != 0 {
+		t.Errorf("stats = %+v, want 3 insertions and no deletions", diff.Stats)
+	}
+}
@@ -0,0 +1,151 @@
+package utils
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+const (
+	EntityKeyword      = "keyword"
+	EntityPerson       = "person"
+	EntityOrganization = "organization"
+	EntityLocation     = "location"
+	EntityDate         = "date"
+	EntityAmount       = "amount"
+)
+
+// EntityTypes lists the supported entity types in display order
+var EntityTypes = []string{EntityKeyword, EntityPerson, EntityOrganization, EntityLocation, EntityDate, EntityAmount}
+
+const maxEntityRunes = 200
+
+// Patterns of the rule-based extractor, used when the ML service is not
+// available. Locations are not recognized without a gazetteer.
+var (
+	boldPattern       = regexp.MustCompile(`\*\*([^*\n]{2,80})\*\*|__([^_\n]{2,80})__`)
+	entityDatePattern = regexp.MustCompile(`(?i)\b(?:\d{4}-\d{2}-\d{2}|\d{1,2}\s+(?:jan|feb|mar|apr|may|jun|jul|aug|sep|sept|oct|nov|dec)[a-z]*\.?\s+\d{4}|(?:jan|feb|mar|apr|may|jun|jul|aug|sep|sept|oct|nov|dec)[a-z]*\.?\s+\d{1,2},\s*\d{4})\b|\d{4}年\d{1,2}月\d{1,2}日`)
+	amountPattern     = regexp.MustCompile(`(?i)(?:[$€£¥]|\b(?:usd|eur|gbp|jpy|cny|idr|krw|rp)\.?\s?)\d[\d.,]*(?:\s?(?:k|m|bn|million|billion|thousand|juta|miliar))?\b|\b\d[\d.,]*\s?(?:usd|eur|gbp|jpy|cny|idr|krw|dollars|euros|rupiah)\b`)
+	orgPattern        = regexp.MustCompile(`\b(?:PT\.?\s+)?(?:[A-Z][\w&'.-]*\s+){0,4}(?:Inc|Ltd|LLC|LLP|Corp|Corporation|GmbH|AG|S\.A|PLC|Tbk|Co|Company|Group|Bank|University|Ministry|Agency)\b\.?`)
+	personPattern     = regexp.MustCompile(`\b(?:Mr|Mrs|Ms|Dr|Prof)\.?\s+(?:[A-Z][a-z'-]+\s?){1,3}`)
+)
+
+// Entity is one mention found in a text
+type Entity struct {
+	Type string `json:"type"`
+	Name string `json:"name"`
+}
+
+// NormalizeEntity returns the key entities are deduplicated on: lower case
+// with collapsed whitespace and without surrounding punctuation or emphasis
+func NormalizeEntity(name string) string {
+	name = strings.Join(strings.Fields(name), " ")
+	name = strings.TrimFunc(name, func(r rune) bool {
+		return unicode.IsPunct(r) && r != '$' && r != '%' || unicode.IsSpace(r) || r == '*' || r == '_'
+	})
+	if runes := []rune(name); len(runes) > maxEntityRunes {
+		name = string(runes[:maxEntityRunes])
+	}
+	return strings.ToLower(name)
+}
+
+// CleanEntityName trims an entity name for display
+func CleanEntityName(name string) string {
+	name = strings.Join(strings.Fields(name), " ")
+	name = strings.Trim(name, "*_`\"'“”,;:")
+	if runes := []rune(name); len(runes) > maxEntityRunes {
+		name = string(runes[:maxEntityRunes])
+	}
+	return strings.TrimSpace(name)
+}
+
+// IsEntityType reports whether t is a supported entity type
+func IsEntityType(t string) bool {
+	for _, entityType := range EntityTypes {
+		if t == entityType {
+			return true
+		}
+	}
+	return false
+}
+
+// MarkdownKeywords returns the bold terms of the last section of a summary,
+// where the summarizer prompts put the keywords
+func MarkdownKeywords(content string) []string {
+	lines := strings.Split(content, "\n")
+	start := 0
+	for i, line := range lines {
+		if headingPattern.MatchString(line) {
+			start = i
+		}
+	}
+
+	var keywords []string
+	for _, match := range boldPattern.FindAllStringSubmatch(strings.Join(lines[start:], "\n"), -1) {
+		keyword := match[1]
+		if keyword == "" {
+			keyword = match[2]
+		}
+		if keyword = CleanEntityName(keyword); keyword != "" {
+			keywords = append(keywords, keyword)
+		}
+	}
+	return keywords
+}
+
+// ExtractEntities finds dates, amounts, organizations and people with
+// patterns. It misses a lot compared to a language model, but keeps the index
+// usable when the ML service is down.
+func ExtractEntities(text string) []Entity {
+	var entities []Entity
+	add := func(entityType string, matches []string) {
+		for _, match := range matches {
+			if name := CleanEntityName(match); name != "" {
+				entities = append(entities, Entity{Type: entityType, Name: name})
+			}
+		}
+	}
+
+	add(EntityDate, entityDatePattern.FindAllString(text, -1))
+	add(EntityAmount, amountPattern.FindAllString(text, -1))
+	add(EntityOrganization, orgPattern.FindAllString(text, -1))
+	add(EntityPerson, personPattern.FindAllString(text, -1))
+
+	return DedupeEntities(entities)
+}
+
+// DedupeEntities drops invalid and repeated entities, keeping the first
+// spelling of each, and sorts them by type and name
+func DedupeEntities(entities []Entity) []Entity {
+	seen := map[string]bool{}
+	unique := make([]Entity, 0, len(entities))
+	for _, entity := range entities {
+		entity.Type = strings.ToLower(strings.TrimSpace(entity.Type))
+		entity.Name = CleanEntityName(entity.Name)
+		key := entity.Type + "\x00" + NormalizeEntity(entity.Name)
+		if !IsEntityType(entity.Type) || NormalizeEntity(entity.Name) == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		unique = append(unique, entity)
+	}
+
+	sort.SliceStable(unique, func(i, j int) bool {
+		if unique[i].Type != unique[j].Type {
+			return unique[i].Type < unique[j].Type
+		}
+		return NormalizeEntity(unique[i].Name) < NormalizeEntity(unique[j].Name)
+	})
+	return unique
+}
+
+// CountMentions counts the case-insensitive occurrences of name in text, at
+// least one since the entity was found in it or in its summary
+func CountMentions(text, name string) int {
+	count := strings.Count(strings.ToLower(text), strings.ToLower(name))
+	if count < 1 {
+		return 1
+	}
+	return count
+}
@@ -0,0 +1,46 @@
+package utils
+
+import (
+	"strconv"
+	"strings"
+)
+
+// ETag formats a resource version as a strong entity tag
+func ETag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// MatchETag reports whether an If-None-Match style header contains the
+// version. Weak comparison is used, so W/"3" matches version 3.
+func MatchETag(header string, version int) bool {
+	if strings.TrimSpace(header) == "*" {
+		return true
+	}
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tag == ETag(version) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseIfMatch returns the version required by an If-Match header. A missing
+// header or "*" returns 0, meaning any version. Weak and malformed tags never
+// match (RFC 9110 requires strong comparison for If-Match).
+func ParseIfMatch(header string) (int, bool) {
+	header = strings.TrimSpace(header)
+	if header == "" || header == "*" {
+		return 0, true
+	}
+
+	tag := strings.TrimSpace(strings.Split(header, ",")[0])
+	if !strings.HasPrefix(tag, `"`) || !strings.HasSuffix(tag, `"`) || len(tag) < 3 {
+		return 0, false
+	}
+	version, err := strconv.Atoi(tag[1 : len(tag)-1])
+	if err != nil || version < 1 {
+		return 0, false
+	}
+	return version, true
+}
@@ -0,0 +1,427 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// The supported subset of JSON Schema. References, combinators and conditional
+// keywords are rejected instead of being silently ignored.
+var (
+	schemaTypes = map[string]bool{
+		"object": true, "array": true, "string": true, "number": true,
+		"integer": true, "boolean": true, "null": true,
+	}
+	schemaFormats = map[string]bool{
+		"date": true, "date-time": true, "email": true, "uri": true,
+	}
+	schemaKeywords = map[string]bool{
+		"$schema": true, "$id": true, "title": true, "description": true, "examples": true, "default": true,
+		"type": true, "enum": true, "const": true,
+		"properties": true, "required": true, "additionalProperties": true,
+		"items": true, "minItems": true, "maxItems": true, "uniqueItems": true,
+		"minLength": true, "maxLength": true, "pattern": true, "format": true,
+		"minimum": true, "maximum": true, "exclusiveMinimum": true, "exclusiveMaximum": true,
+	}
+)
+
+// SchemaError is a violation of a schema at a JSON path like $.parties[0].name
+type SchemaError struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+func (e SchemaError) Error() string {
+	return e.Path + ": " + e.Message
+}
+
+// ParseSchema decodes a JSON Schema and checks that it only uses the supported
+// keywords. The root must describe an object with at least one property.
+func ParseSchema(data []byte) (map[string]interface{}, error) {
+	var schema map[string]interface{}
+	if err := json.Unmarshal(data, &schema); err != nil {
+		return nil, fmt.Errorf("schema is not a JSON object: %w", err)
+	}
+
+	if err := checkSchema(schema, "$"); err != nil {
+		return nil, err
+	}
+	if !schemaAllows(schema, "object") {
+		return nil, SchemaError{Path: "$", Message: `root schema must have type "object"`}
+	}
+	if properties, _ := schema["properties"].(map[string]interface{}); len(properties) == 0 {
+		return nil, SchemaError{Path: "$", Message: "root schema must define properties"}
+	}
+
+	return schema, nil
+}
+
+// SchemaProperties returns the top-level property names of a schema in the
+// order they are declared in data
+func SchemaProperties(data []byte) []string {
+	var root struct {
+		Properties json.RawMessage `json:"properties"`
+	}
+	if err := json.Unmarshal(data, &root); err != nil || len(root.Properties) == 0 {
+		return nil
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(string(root.Properties)))
+	if token, err := decoder.Token(); err != nil || token != json.Delim('{') {
+		return nil
+	}
+
+	var names []string
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return names
+		}
+		name, _ := token.(string)
+		var skip json.RawMessage
+		if err := decoder.Decode(&skip); err != nil {
+			return names
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+func checkSchema(schema map[string]interface{}, path string) error {
+	keys := make([]string, 0, len(schema))
+	for key := range schema {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if !schemaKeywords[key] {
+			return SchemaError{Path: path, Message: fmt.Sprintf("unsupported keyword %q", key)}
+		}
+	}
+
+	if t, ok := schema["type"]; ok {
+		types, ok := schemaTypeList(t)
+		if !ok || len(types) == 0 {
+			return SchemaError{Path: path, Message: "type must be a type name or a list of type names"}
+		}
+		for _, name := range types {
+			if !schemaTypes[name] {
+				return SchemaError{Path: path, Message: fmt.Sprintf("unknown type %q", name)}
+			}
+		}
+	}
+
+	if enum, ok := schema["enum"]; ok {
+		if values, ok := enum.([]interface{}); !ok || len(values) == 0 {
+			return SchemaError{Path: path, Message: "enum must be a non-empty array"}
+		}
+	}
+
+	if properties, ok := schema["properties"]; ok {
+		props, ok := properties.(map[string]interface{})
+		if !ok {
+			return SchemaError{Path: path, Message: "properties must be an object"}
+		}
+		for name, property := range props {
+			child, ok := property.(map[string]interface{})
+			if !ok {
+				return SchemaError{Path: path + "." + name, Message: "property schema must be an object"}
+			}
+			if err := checkSchema(child, path+"."+name); err != nil {
+				return err
+			}
+		}
+	}
+
+	if required, ok := schema["required"]; ok {
+		names, ok := required.([]interface{})
+		if !ok {
+			return SchemaError{Path: path, Message: "required must be an array of property names"}
+		}
+		props, _ := schema["properties"].(map[string]interface{})
+		for _, name := range names {
+			s, ok := name.(string)
+			if !ok {
+				return SchemaError{Path: path, Message: "required must be an array of property names"}
+			}
+			if _, ok := props[s]; !ok {
+				return SchemaError{Path: path, Message: fmt.Sprintf("required property %q is not defined", s)}
+			}
+		}
+	}
+
+	if additional, ok := schema["additionalProperties"]; ok {
+		if _, ok := additional.(bool); !ok {
+			return SchemaError{Path: path, Message: "additionalProperties must be true or false"}
+		}
+	}
+
+	if items, ok := schema["items"]; ok {
+		child, ok := items.(map[string]interface{})
+		if !ok {
+			return SchemaError{Path: path + "[]", Message: "items must be a schema object"}
+		}
+		if err := checkSchema(child, path+"[]"); err != nil {
+			return err
+		}
+	}
+
+	for _, key := range []string{"minItems", "maxItems", "minLength", "maxLength"} {
+		if v, ok := schema[key]; ok {
+			if n, ok := v.(float64); !ok || n < 0 || n != math.Trunc(n) {
+				return SchemaError{Path: path, Message: key + " must be a non-negative integer"}
+			}
+		}
+	}
+	for _, key := range []string{"minimum", "maximum", "exclusiveMinimum", "exclusiveMaximum"} {
+		if v, ok := schema[key]; ok {
+			if _, ok := v.(float64); !ok {
+				return SchemaError{Path: path, Message: key + " must be a number"}
+			}
+		}
+	}
+	if v, ok := schema["uniqueItems"]; ok {
+		if _, ok := v.(bool); !ok {
+			return SchemaError{Path: path, Message: "uniqueItems must be true or false"}
+		}
+	}
+
+	if pattern, ok := schema["pattern"]; ok {
+		s, ok := pattern.(string)
+		if !ok {
+			return SchemaError{Path: path, Message: "pattern must be a string"}
+		}
+		if _, err := regexp.Compile(s); err != nil {
+			return SchemaError{Path: path, Message: "invalid pattern: " + err.Error()}
+		}
+	}
+
+	if format, ok := schema["format"]; ok {
+		if s, ok := format.(string); !ok || !schemaFormats[s] {
+			return SchemaError{Path: path, Message: "format must be one of date, date-time, email or uri"}
+		}
+	}
+
+	return nil
+}
+
+// ValidateSchema checks a decoded JSON value against a schema accepted by
+// ParseSchema and returns every violation, not just the first one
+func ValidateSchema(schema map[string]interface{}, value interface{}) []SchemaError {
+	var errs []SchemaError
+	validateValue(schema, value, "$", &errs)
+	return errs
+}
+
+func validateValue(schema map[string]interface{}, value interface{}, path string, errs *[]SchemaError) {
+	fail := func(format string, args ...interface{}) {
+		*errs = append(*errs, SchemaError{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if t, ok := schema["type"]; ok {
+		types, _ := schemaTypeList(t)
+		matched := false
+		for _, name := range types {
+			if jsonTypeMatches(name, value) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			fail("expected %s, got %s", strings.Join(types, " or "), jsonTypeName(value))
+			return
+		}
+	}
+
+	if enum, ok := schema["enum"].([]interface{}); ok {
+		found := false
+		for _, option := range enum {
+			if jsonEqual(option, value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			fail("must be one of %s", compactJSON(enum))
+		}
+	}
+	if constant, ok := schema["const"]; ok && !jsonEqual(constant, value) {
+		fail("must be %s", compactJSON(constant))
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		props, _ := schema["properties"].(map[string]interface{})
+		if required, ok := schema["required"].([]interface{}); ok {
+			for _, name := range required {
+				s, _ := name.(string)
+				if _, ok := v[s]; !ok {
+					fail("missing required property %q", s)
+				}
+			}
+		}
+
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			child, ok := props[name].(map[string]interface{})
+			if !ok {
+				if additional, ok := schema["additionalProperties"].(bool); ok && !additional {
+					fail("unexpected property %q", name)
+				}
+				continue
+			}
+			validateValue(child, v[name], path+"."+name, errs)
+		}
+
+	case []interface{}:
+		if n, ok := schema["minItems"].(float64); ok && float64(len(v)) < n {
+			fail("must have at least %d items", int(n))
+		}
+		if n, ok := schema["maxItems"].(float64); ok && float64(len(v)) > n {
+			fail("must have at most %d items", int(n))
+		}
+		if unique, _ := schema["uniqueItems"].(bool); unique {
+			for i := range v {
+				for j := 0; j < i; j++ {
+					if jsonEqual(v[i], v[j]) {
+						fail("items %d and %d are equal", j, i)
+					}
+				}
+			}
+		}
+		if items, ok := schema["items"].(map[string]interface{}); ok {
+			for i, item := range v {
+				validateValue(items, item, fmt.Sprintf("%s[%d]", path, i), errs)
+			}
+		}
+
+	case string:
+		length := utf8.RuneCountInString(v)
+		if n, ok := schema["minLength"].(float64); ok && float64(length) < n {
+			fail("must be at least %d characters", int(n))
+		}
+		if n, ok := schema["maxLength"].(float64); ok && float64(length) > n {
+			fail("must be at most %d characters", int(n))
+		}
+		if pattern, ok := schema["pattern"].(string); ok {
+			if re, err := regexp.Compile(pattern); err == nil && !re.MatchString(v) {
+				fail("does not match pattern %q", pattern)
+			}
+		}
+		if format, ok := schema["format"].(string); ok && !formatMatches(format, v) {
+			fail("is not a valid %s", format)
+		}
+
+	case float64:
+		if n, ok := schema["minimum"].(float64); ok && v < n {
+			fail("must be at least %v", n)
+		}
+		if n, ok := schema["maximum"].(float64); ok && v > n {
+			fail("must be at most %v", n)
+		}
+		if n, ok := schema["exclusiveMinimum"].(float64); ok && v <= n {
+			fail("must be greater than %v", n)
+		}
+		if n, ok := schema["exclusiveMaximum"].(float64); ok && v >= n {
+			fail("must be less than %v", n)
+		}
+	}
+}
+
+func schemaTypeList(t interface{}) ([]string, bool) {
+	switch v := t.(type) {
+	case string:
+		return []string{v}, true
+	case []interface{}:
+		types := make([]string, 0, len(v))
+		for _, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return nil, false
+			}
+			types = append(types, s)
+		}
+		return types, true
+	}
+	return nil, false
+}
+
+func schemaAllows(schema map[string]interface{}, name string) bool {
+	types, _ := schemaTypeList(schema["type"])
+	for _, t := range types {
+		if t == name {
+			return true
+		}
+	}
+	return false
+}
+
+func jsonTypeMatches(name string, value interface{}) bool {
+	switch name {
+	case "integer":
+		n, ok := value.(float64)
+		return ok && n == math.Trunc(n)
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	}
+	return jsonTypeName(value) == name
+}
+
+func jsonTypeName(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+func jsonEqual(a, b interface{}) bool {
+	return compactJSON(a) == compactJSON(b)
+}
+
+// compactJSON encodes a decoded value, map keys are sorted by encoding/json
+func compactJSON(value interface{}) string {
+	data, _ := json.Marshal(value)
+	return string(data)
+}
+
+func formatMatches(format, value string) bool {
+	switch format {
+	case "date":
+		_, err := time.Parse("2006-01-02", value)
+		return err == nil
+	case "date-time":
+		_, err := time.Parse(time.RFC3339, value)
+		return err == nil
+	case "email":
+		address, err := mail.ParseAddress(value)
+		return err == nil && address.Address == value
+	case "uri":
+		u, err := url.Parse(value)
+		return err == nil && u.Scheme != ""
+	}
+	return true
+}
@@ -0,0 +1,169 @@
+package utils
+
+import (
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	mdHeading     = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+	mdUnordered   = regexp.MustCompile(`^\s*[-*+]\s+(.*)$`)
+	mdOrdered     = regexp.MustCompile(`^\s*\d+[.)]\s+(.*)$`)
+	mdQuote       = regexp.MustCompile(`^\s*>\s?(.*)$`)
+	mdRule        = regexp.MustCompile(`^\s*(?:(?:-\s*){3,}|(?:\*\s*){3,}|(?:_\s*){3,})$`)
+	mdBold        = regexp.MustCompile(`(\*\*|__)(\S(?:.*?\S)?)(\*\*|__)`)
+	mdItalic      = regexp.MustCompile(`(^|[^\w*])[*_](\S(?:[^*_]*?\S)?)[*_]`)
+	mdLink        = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)\)`)
+	mdSafeSchemes = []string{"http://", "https://", "mailto:"}
+)
+
+// RenderMarkdown converts the subset of Markdown produced by the summarizer
+// (headings, lists, quotes, code, emphasis and links) to HTML. All input is
+// escaped first, raw HTML is never passed through and only http, https and
+// mailto links are kept, so the output is safe to serve to anonymous viewers.
+func RenderMarkdown(source string) string {
+	var out strings.Builder
+	var paragraph []string
+	list := ""
+	inCode := false
+
+	flushParagraph := func() {
+		if len(paragraph) > 0 {
+			out.WriteString("<p>" + strings.Join(paragraph, "<br>\n") + "</p>\n")
+			paragraph = nil
+		}
+	}
+	closeList := func() {
+		if list != "" {
+			out.WriteString("</" + list + ">\n")
+			list = ""
+		}
+	}
+	openList := func(tag string) {
+		if list != tag {
+			closeList()
+			out.WriteString("<" + tag + ">\n")
+			list = tag
+		}
+	}
+
+	for _, line := range strings.Split(strings.ReplaceAll(source, "\r\n", "\n"), "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			flushParagraph()
+			closeList()
+			if inCode {
+				out.WriteString("</code></pre>\n")
+			} else {
+				out.WriteString("<pre><code>")
+			}
+			inCode = !inCode
+			continue
+		}
+		if inCode {
+			out.WriteString(html.EscapeString(line) + "\n")
+			continue
+		}
+
+		if strings.TrimSpace(line) == "" {
+			flushParagraph()
+			closeList()
+			continue
+		}
+
+		if m := mdHeading.FindStringSubmatch(line); m != nil {
+			flushParagraph()
+			closeList()
+			level := string('0' + byte(len(m[1])))
+			out.WriteString("<h" + level + ">" + renderInline(m[2]) + "</h" + level + ">\n")
+			continue
+		}
+		if mdRule.MatchString(line) {
+			flushParagraph()
+			closeList()
+			out.WriteString("<hr>\n")
+			continue
+		}
+		if m := mdUnordered.FindStringSubmatch(line); m != nil {
+			flushParagraph()
+			openList("ul")
+			out.WriteString("<li>" + renderInline(m[1]) + "</li>\n")
+			continue
+		}
+		if m := mdOrdered.FindStringSubmatch(line); m != nil {
+			flushParagraph()
+			openList("ol")
+			out.WriteString("<li>" + renderInline(m[1]) + "</li>\n")
+			continue
+		}
+		if m := mdQuote.FindStringSubmatch(line); m != nil {
+			flushParagraph()
+			closeList()
+			out.WriteString("<blockquote>" + renderInline(m[1]) + "</blockquote>\n")
+			continue
+		}
+
+		closeList()
+		paragraph = append(paragraph, renderInline(strings.TrimSpace(line)))
+	}
+
+	flushParagraph()
+	closeList()
+	if inCode {
+		out.WriteString("</code></pre>\n")
+	}
+
+	return out.String()
+}
+
+// renderInline escapes a line and applies code spans, emphasis and links
+func renderInline(text string) string {
+	parts := strings.Split(text, "`")
+
+	var out strings.Builder
+	for i, part := range parts {
+		// Odd parts are inside a code span, unless the backtick is unmatched
+		if i%2 == 1 && i < len(parts)-1 {
+			out.WriteString("<code>" + html.EscapeString(part) + "</code>")
+			continue
+		}
+		if i%2 == 1 {
+			out.WriteString("`")
+		}
+
+		// Links are swapped for placeholders so emphasis never rewrites a URL
+		var links []string
+		escaped := mdLink.ReplaceAllStringFunc(html.EscapeString(part), func(match string) string {
+			m := mdLink.FindStringSubmatch(match)
+			label := renderEmphasis(m[1])
+			if isSafeLink(html.UnescapeString(m[2])) {
+				label = `<a href="` + m[2] + `" rel="nofollow noopener noreferrer">` + label + `</a>`
+			}
+			links = append(links, label)
+			return "\x00" + strconv.Itoa(len(links)-1) + "\x00"
+		})
+		escaped = renderEmphasis(escaped)
+		for i, link := range links {
+			escaped = strings.Replace(escaped, "\x00"+strconv.Itoa(i)+"\x00", link, 1)
+		}
+		out.WriteString(escaped)
+	}
+
+	return out.String()
+}
+
+func renderEmphasis(text string) string {
+	text = mdBold.ReplaceAllString(text, "<strong>$2</strong>")
+	return mdItalic.ReplaceAllString(text, "$1<em>$2</em>")
+}
+
+func isSafeLink(href string) bool {
+	lower := strings.ToLower(strings.TrimSpace(href))
+	for _, scheme := range mdSafeSchemes {
+		if strings.HasPrefix(lower, scheme) {
+			return true
+		}
+	}
+	return false
+}
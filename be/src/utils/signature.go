@@ -0,0 +1,26 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+// SignPath returns the HMAC-SHA256 signature of a request path valid until expires
+func SignPath(secret, path string, expires int64) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%s\n%d", path, expires)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyPath checks the signature and that the link has not expired
+func VerifyPath(secret, path string, expires int64, signature string) bool {
+	if time.Now().Unix() > expires {
+		return false
+	}
+
+	expected := SignPath(secret, path, expires)
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
@@ -0,0 +1,13 @@
+package validation
+
+import "github.com/google/uuid"
+
+type AskQuestion struct {
+	Question       string     `json:"question" validate:"required,min=1,max=2000"`
+	ConversationID *uuid.UUID `json:"conversation_id" validate:"omitempty"`
+	Language       string     `json:"language" validate:"omitempty,oneof=EN ID CN JP KR"`
+}
+
+type ConversationIDParam struct {
+	ID uuid.UUID `params:"id" validate:"required,uuid"`
+}
@@ -0,0 +1,23 @@
+package validation
+
+type ClassificationRuleParam struct {
+	Category string `params:"category" validate:"required,max=50"`
+}
+
+// UpsertClassificationRule creates or replaces the rule of a category
+type UpsertClassificationRule struct {
+	Label       string   `json:"label" validate:"required,max=100"`
+	Description string   `json:"description" validate:"omitempty,max=1000"`
+	Keywords    []string `json:"keywords" validate:"required,min=1,max=200,dive,required,max=100"`
+	IsActive    *bool    `json:"is_active"`
+}
+
+// SetCategory overrides the category of a PDF, nil hands it back to
+// automatic classification
+type SetCategory struct {
+	Category *string `json:"category" validate:"omitempty,max=50"`
+}
+
+type RetrainQuery struct {
+	Reclassify bool `query:"reclassify"`
+}
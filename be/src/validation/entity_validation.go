@@ -0,0 +1,25 @@
+package validation
+
+import "github.com/google/uuid"
+
+// EntityQuery lists the entities mentioned in the most PDFs. Name matches the
+// normalized name exactly, Search matches part of it.
+type EntityQuery struct {
+	Type   string `query:"type" validate:"omitempty,oneof=keyword person organization location date amount"`
+	Name   string `query:"name" validate:"omitempty,max=255"`
+	Search string `query:"search" validate:"omitempty,max=255"`
+	Limit  int    `query:"limit" validate:"omitempty,min=1,max=100"`
+}
+
+type EntityIDParam struct {
+	ID uuid.UUID `params:"id" validate:"required,uuid"`
+}
+
+type EntityPDFsQuery struct {
+	Page  int `query:"page" validate:"omitempty,min=1"`
+	Limit int `query:"limit" validate:"omitempty,min=1,max=100"`
+}
+
+func (q *EntityPDFsQuery) GetOffset() int {
+	return (q.Page - 1) * q.Limit
+}
@@ -0,0 +1,49 @@
+package validation
+
+import (
+	"encoding/json"
+
+	"github.com/google/uuid"
+)
+
+type CreateExtractionTemplate struct {
+	Name        string          `json:"name" validate:"required,min=1,max=100"`
+	Description string          `json:"description" validate:"omitempty,max=2000"`
+	Schema      json.RawMessage `json:"schema" validate:"required"`
+}
+
+// UpdateExtractionTemplate changes the given fields, a new schema bumps the
+// template version
+type UpdateExtractionTemplate struct {
+	Name        *string         `json:"name" validate:"omitempty,min=1,max=100"`
+	Description *string         `json:"description" validate:"omitempty,max=2000"`
+	Schema      json.RawMessage `json:"schema" validate:"omitempty"`
+}
+
+type TemplateIDParam struct {
+	ID uuid.UUID `params:"id" validate:"required,uuid"`
+}
+
+type ExtractionIDParam struct {
+	ID uuid.UUID `params:"id" validate:"required,uuid"`
+}
+
+type RunExtraction struct {
+	Template string `query:"template" validate:"required,max=100"` // template ID or name
+}
+
+// ExtractionResultsQuery filters the latest result of a template per PDF.
+// Field is a dotted path into the extracted data, e.g. governing_law or
+// parties.0.name, and is compared as text with Value.
+type ExtractionResultsQuery struct {
+	Page   int    `query:"page" validate:"omitempty,min=1"`
+	Limit  int    `query:"limit" validate:"omitempty,min=1,max=100"`
+	Status string `query:"status" validate:"omitempty,oneof=processing completed invalid failed"`
+	Field  string `query:"field" validate:"omitempty,max=255"`
+	Value  string `query:"value" validate:"required_with=Field,max=1000"`
+	Export string `query:"export" validate:"omitempty,oneof=csv json"`
+}
+
+func (q *ExtractionResultsQuery) GetOffset() int {
+	return (q.Page - 1) * q.Limit
+}
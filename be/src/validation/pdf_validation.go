@@ -5,12 +5,34 @@ import "github.com/google/uuid"
 type GenerateSummary struct {
 	Language string `json:"language" validate:"required,oneof=EN ID CN JP KR"`
 	Style    string `json:"style" validate:"required,oneof=professional simple"`
+	Force    bool   `json:"force"` // bypass the summary cache
+	PageFrom *int   `json:"page_from" validate:"omitempty,min=1"`
+	PageTo   *int   `json:"page_to" validate:"omitempty,min=1"`
+	Mode     string `json:"mode" validate:"omitempty,oneof=chapters"` // one section per top-level chapter
+	Cite     bool   `json:"cite"` // store the source pages of every paragraph
+
+	OutlineItemID *uuid.UUID `json:"outline_item_id"` // alternative to page_from/page_to
+}
+
+type GenerateSynthesis struct {
+	PDFIDs   []uuid.UUID `json:"pdf_ids" validate:"required,min=2,max=10,unique"`
+	Mode     string      `json:"mode" validate:"required,oneof=compare merge timeline"`
+	Language string      `json:"language" validate:"required,oneof=EN ID CN JP KR"`
+	Style    string      `json:"style" validate:"required,oneof=professional simple"`
 }
 
 type UpdateSummary struct {
 	Content string `json:"content" validate:"required"`
 }
 
+type UnlockPDF struct {
+	Password string `json:"password" validate:"required,max=128"`
+}
+
+type SignFileURL struct {
+	TTL int `json:"ttl" validate:"omitempty,min=1,max=86400"` // seconds
+}
+
 type PDFIDParam struct {
 	ID uuid.UUID `params:"id" validate:"required,uuid"`
 }
@@ -19,18 +41,51 @@ type SummaryIDParam struct {
 	ID uuid.UUID `params:"id" validate:"required,uuid"`
 }
 
+type RevisionParam struct {
+	ID       uuid.UUID `params:"id" validate:"required,uuid"`
+	Revision int       `params:"rev" validate:"required,min=1"`
+}
+
+type SummaryPairParam struct {
+	ID      uuid.UUID `params:"id" validate:"required,uuid"`
+	OtherID uuid.UUID `params:"other" validate:"required,uuid"`
+}
+
+type DiffQuery struct {
+	From    int  `query:"from" validate:"omitempty,min=1"`
+	To      int  `query:"to" validate:"omitempty,min=1"`
+	Context *int `query:"context" validate:"omitempty,min=0,max=50"`
+}
+
+// ContextLines returns the requested number of context lines, 3 by default
+func (q DiffQuery) ContextLines() int {
+	if q.Context == nil {
+		return 3
+	}
+	return *q.Context
+}
+
 type QueryParams struct {
-	Page      int    `query:"page" validate:"omitempty,min=1"`
-	Limit     int    `query:"limit" validate:"omitempty,min=1,max=100"`
-	Search    string `query:"search" validate:"omitempty,max=255"`
-	Status    string `query:"status" validate:"omitempty,oneof=processing completed failed timeout pending"`
-	Language  string `query:"language" validate:"omitempty,oneof=EN ID CN JP KR"`
-	Style     string `query:"style" validate:"omitempty,oneof=professional simple"`
-	DateFrom  string `query:"date_from" validate:"omitempty"`
-	DateTo    string `query:"date_to" validate:"omitempty"`
-	SortBy    string `query:"sort_by" validate:"omitempty,oneof=created_at updated_at uploaded_at original_name"`
-	SortOrder string `query:"sort_order" validate:"omitempty,oneof=asc desc"`
-	Export    string `query:"export" validate:"omitempty,oneof=csv json"`
+	Page         int    `query:"page" validate:"omitempty,min=1"`
+	Limit        int    `query:"limit" validate:"omitempty,min=1,max=100"`
+	Search       string `query:"search" validate:"omitempty,max=255"`
+	Status       string `query:"status" validate:"omitempty,oneof=processing completed failed timeout pending locked"`
+	Language     string `query:"language" validate:"omitempty,oneof=EN ID CN JP KR"`
+	Style        string `query:"style" validate:"omitempty,oneof=professional simple"`
+	Scope        string `query:"scope" validate:"omitempty,oneof=full partial synthesis"`
+	Author       string `query:"author" validate:"omitempty,max=255"`
+	Keyword      string `query:"keyword" validate:"omitempty,max=255"`
+	Category     string `query:"category" validate:"omitempty,max=50"` // "unclassified" for none
+	Producer     string `query:"producer" validate:"omitempty,max=255"`
+	PDFVersion   string `query:"pdf_version" validate:"omitempty,max=10"`
+	HasTextLayer string `query:"has_text_layer" validate:"omitempty,oneof=true false"`
+	MinPages     int    `query:"min_pages" validate:"omitempty,min=1"`
+	MaxPages     int    `query:"max_pages" validate:"omitempty,min=1"`
+	DateFrom     string `query:"date_from" validate:"omitempty"`
+	DateTo       string `query:"date_to" validate:"omitempty"`
+	SortBy       string `query:"sort_by" validate:"omitempty,oneof=created_at updated_at uploaded_at original_name page_count title author pdf_created_at pdf_modified_at category"`
+	SortOrder    string `query:"sort_order" validate:"omitempty,oneof=asc desc"`
+	Export       string `query:"export" validate:"omitempty,oneof=csv json"`
 }
 
 type SummaryQueryParams struct {
@@ -45,4 +100,13 @@ type SummaryQueryParams struct {
 	DateFrom  string `query:"date_from" validate:"omitempty"`
 	DateTo    string `query:"date_to" validate:"omitempty"`
 	Export    string `query:"export" validate:"omitempty,oneof=csv json"`
+}
+
+type InvalidateCacheQuery struct {
+	PDFID         string `query:"pdf_id" validate:"omitempty,uuid"`
+	ContentHash   string `query:"content_hash" validate:"omitempty,len=64,hexadecimal"`
+	Language      string `query:"language" validate:"omitempty,oneof=EN ID CN JP KR"`
+	Style         string `query:"style" validate:"omitempty,oneof=professional simple"`
+	Model         string `query:"model" validate:"omitempty,max=100"`
+	PromptVersion string `query:"prompt_version" validate:"omitempty,max=20"`
 }
\ No newline at end of file
@@ -0,0 +1,23 @@
+package validation
+
+type SearchQuery struct {
+	Query string `query:"q" validate:"required,min=1,max=255"`
+	Mode  string `query:"mode" validate:"omitempty,oneof=keyword semantic"`
+	Type  string `query:"type" validate:"omitempty,oneof=pdf page summary"`
+	PDFID string `query:"pdf_id" validate:"omitempty,uuid"`
+	Page  int    `query:"page" validate:"omitempty,min=1"`
+	Limit int    `query:"limit" validate:"omitempty,min=1,max=100"`
+}
+
+func (q *SearchQuery) SetDefaults() {
+	if q.Page < 1 {
+		q.Page = 1
+	}
+	if q.Limit < 1 {
+		q.Limit = 10
+	}
+}
+
+func (q *SearchQuery) GetOffset() int {
+	return (q.Page - 1) * q.Limit
+}
@@ -0,0 +1,24 @@
+package validation
+
+import "github.com/google/uuid"
+
+type CreateShare struct {
+	ExpiresIn int    `json:"expires_in" validate:"omitempty,min=60,max=31536000"` // seconds
+	Password  string `json:"password" validate:"omitempty,min=8,max=128"`
+}
+
+type ViewShare struct {
+	Format string `query:"format" validate:"omitempty,oneof=md html"`
+}
+
+type UnlockShare struct {
+	Password string `json:"password" form:"password" validate:"omitempty,max=128"`
+}
+
+type ShareIDParam struct {
+	ID uuid.UUID `params:"id" validate:"required,uuid"`
+}
+
+type ShareTokenParam struct {
+	Token string `params:"token" validate:"required,min=32,max=64"`
+}
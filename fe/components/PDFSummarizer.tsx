@@ -184,16 +184,18 @@ export const PDFSummarizer: React.FC = () => {
   };
 
   {
-    /* Utility: Get File URL for Preview */
+    /* Preview: Open the PDF with a Freshly Signed File Link */
   }
-  const getFileURL = (pdf: PDF) => {
-    if (pdf.URL && pdf.URL.startsWith("http")) {
-      return pdf.URL;
+  const openPreview = async (pdf: PDF) => {
+    try {
+      setPreviewUrl(await pdfService.signFileURL(pdf.id));
+      setShowPreview(true);
+    } catch (error) {
+      console.error("Failed to open PDF preview", error);
+      if (typeof window !== "undefined" && (window as any).showToast) {
+        (window as any).showToast("Failed to open PDF preview", "error");
+      }
     }
-
-    const BACKEND_URL =
-      process.env.NEXT_PUBLIC_BACKEND_URL || "http://localhost:5000";
-    return `${BACKEND_URL}/uploads/${pdf.filename}`;
   };
 
   {
@@ -824,10 +826,7 @@ export const PDFSummarizer: React.FC = () => {
                 </div>
 
                 <div
-                  onClick={() => {
-                    setPreviewUrl(getFileURL(activePDF));
-                    setShowPreview(true);
-                  }}
+                  onClick={() => openPreview(activePDF)}
                   className="bg-white/40 rounded-lg p-4 border border-white/50 mb-4 cursor-pointer hover:bg-white/50 transition"
                 >
                   <div className="flex items-center justify-between">
@@ -950,10 +949,7 @@ export const PDFSummarizer: React.FC = () => {
                   </div>
 
                   <div
-                    onClick={() => {
-                      setPreviewUrl(getFileURL(activePDF));
-                      setShowPreview(true);
-                    }}
+                    onClick={() => openPreview(activePDF)}
                     className="bg-white/40 rounded-lg p-4 border border-white/50 mb-4 cursor-pointer hover:bg-white/50 transition"
                   >
                     <div className="flex items-center justify-between">
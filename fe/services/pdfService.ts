@@ -150,6 +150,19 @@ export const pdfService = {
     return response.json();
   },
 
+  async signFileURL(id: string): Promise<string> {
+    const response = await fetch(`${BACKEND_URL}/v1/pdfs/${id}/file/sign`, {
+      method: 'POST',
+    });
+
+    if (!response.ok) {
+      throw new Error('Failed to sign PDF file link');
+    }
+
+    const json = await response.json();
+    return `${BACKEND_URL}${json.data.url}`;
+  },
+
   async deletePDF(id: string) {
     const response = await fetch(`${BACKEND_URL}/v1/pdfs/${id}`, {
       method: 'DELETE',